@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	chartWidth  = 800
+	chartHeight = 500
+	chartLeft   = 80
+	chartRight  = 160
+	chartTop    = 50
+	chartBottom = 60
+)
+
+var seriesColors = []string{"#d62728", "#1f77b4", "#2ca02c", "#ff7f0e", "#9467bd"}
+
+func escapeXML(s string) string {
+	b := strings.Builder{}
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// axis maps values onto a span of pixels, on a linear or log scale.
+type axis struct {
+	label    string
+	log      bool
+	min, max float64
+}
+
+// newAxis fits an axis around the values. Log axes ignore values that aren't
+// positive.
+func newAxis(label string, log bool, values []float64) axis {
+	a := axis{label: label, log: log, min: math.Inf(1), max: math.Inf(-1)}
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) || (log && v <= 0) {
+			continue
+		}
+		a.min = math.Min(a.min, v)
+		a.max = math.Max(a.max, v)
+	}
+
+	if math.IsInf(a.min, 1) {
+		a.min, a.max = 1, 10
+	}
+	if log {
+		a.min = math.Pow(10, math.Floor(math.Log10(a.min)))
+		a.max = math.Pow(10, math.Ceil(math.Log10(a.max)))
+		if a.min == a.max {
+			a.max *= 10
+		}
+		return a
+	}
+
+	if a.min > 0 {
+		a.min = 0
+	}
+	if a.min == a.max {
+		a.max = a.min + 1
+	}
+	step := niceStep((a.max - a.min) / 5)
+	a.min = math.Floor(a.min/step) * step
+	a.max = math.Ceil(a.max/step) * step
+	return a
+}
+
+// niceStep rounds a tick spacing up to 1, 2 or 5 times a power of 10.
+func niceStep(raw float64) float64 {
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			return m * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// position returns how far along the axis a value is, from 0 to 1.
+func (a axis) position(v float64) float64 {
+	if a.log {
+		return (math.Log10(v) - math.Log10(a.min)) / (math.Log10(a.max) - math.Log10(a.min))
+	}
+	return (v - a.min) / (a.max - a.min)
+}
+
+func (a axis) ticks() []float64 {
+	ticks := make([]float64, 0)
+	if a.log {
+		for t := a.min; t <= a.max*1.0001; t *= 10 {
+			ticks = append(ticks, t)
+		}
+		return ticks
+	}
+
+	step := niceStep((a.max - a.min) / 5)
+	for t := a.min; t <= a.max+step/1000; t += step {
+		ticks = append(ticks, t)
+	}
+	return ticks
+}
+
+func formatTick(v float64) string {
+	return fmt.Sprintf("%.6g", v)
+}
+
+type chartPoint struct {
+	series int
+	label  string
+	x, y   float64
+}
+
+// scatterChart plots labeled points, colored by the series they belong to.
+type scatterChart struct {
+	title  string
+	series []string
+	x, y   axis
+	points []chartPoint
+}
+
+func (c scatterChart) plotX(v float64) float64 {
+	return chartLeft + c.x.position(v)*(chartWidth-chartLeft-chartRight)
+}
+
+func (c scatterChart) plotY(v float64) float64 {
+	return chartHeight - chartBottom - c.y.position(v)*(chartHeight-chartTop-chartBottom)
+}
+
+func writeSVGHeader(b *strings.Builder, title string) {
+	fmt.Fprintf(b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"12\">\n", chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(b, "<rect width=\"%d\" height=\"%d\" fill=\"white\"/>\n", chartWidth, chartHeight)
+	fmt.Fprintf(b, "<text x=\"%d\" y=\"30\" text-anchor=\"middle\" font-size=\"16\">%s</text>\n", chartWidth/2, escapeXML(title))
+}
+
+func (c scatterChart) WriteSVG(w io.Writer) error {
+	b := strings.Builder{}
+	writeSVGHeader(&b, c.title)
+
+	// Grid lines and tick labels
+	for _, t := range c.x.ticks() {
+		x := c.plotX(t)
+		fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%d\" stroke=\"#ddd\"/>\n", x, chartTop, x, chartHeight-chartBottom)
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", x, chartHeight-chartBottom+18, formatTick(t))
+	}
+	for _, t := range c.y.ticks() {
+		y := c.plotY(t)
+		fmt.Fprintf(&b, "<line x1=\"%d\" y1=\"%.1f\" x2=\"%d\" y2=\"%.1f\" stroke=\"#ddd\"/>\n", chartLeft, y, chartWidth-chartRight, y)
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%.1f\" text-anchor=\"end\">%s</text>\n", chartLeft-6, y+4, formatTick(t))
+	}
+	fmt.Fprintf(&b, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"none\" stroke=\"black\"/>\n", chartLeft, chartTop, chartWidth-chartLeft-chartRight, chartHeight-chartTop-chartBottom)
+	fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", (chartLeft+chartWidth-chartRight)/2, chartHeight-15, escapeXML(c.x.label))
+	fmt.Fprintf(&b, "<text transform=\"translate(20 %d) rotate(-90)\" text-anchor=\"middle\">%s</text>\n", (chartTop+chartHeight-chartBottom)/2, escapeXML(c.y.label))
+
+	for _, p := range c.points {
+		if (c.x.log && p.x <= 0) || (c.y.log && p.y <= 0) || math.IsNaN(p.x) || math.IsNaN(p.y) {
+			continue
+		}
+		x, y := c.plotX(p.x), c.plotY(p.y)
+		color := seriesColors[p.series%len(seriesColors)]
+		fmt.Fprintf(&b, "<circle cx=\"%.1f\" cy=\"%.1f\" r=\"4\" fill=\"%s\"/>\n", x, y, color)
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" font-size=\"10\" fill=\"%s\">%s</text>\n", x+6, y-4, color, escapeXML(p.label))
+	}
+
+	for i, name := range c.series {
+		y := chartTop + 10 + i*18
+		color := seriesColors[i%len(seriesColors)]
+		fmt.Fprintf(&b, "<circle cx=\"%d\" cy=\"%d\" r=\"4\" fill=\"%s\"/>\n", chartWidth-chartRight+20, y, color)
+		fmt.Fprintf(&b, "<text x=\"%d\" y=\"%d\">%s</text>\n", chartWidth-chartRight+30, y+4, escapeXML(name))
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type chartBar struct {
+	label string
+	value float64
+}
+
+// barChart plots one horizontal bar per label.
+type barChart struct {
+	title string
+	unit  string
+	bars  []chartBar
+}
+
+func (c barChart) WriteSVG(w io.Writer) error {
+	values := make([]float64, len(c.bars))
+	for i, bar := range c.bars {
+		values[i] = bar.value
+	}
+	scale := newAxis(c.unit, false, values)
+
+	b := strings.Builder{}
+	writeSVGHeader(&b, c.title)
+
+	left, right := 120.0, float64(chartWidth-40)
+	rowHeight := float64(chartHeight-chartTop-chartBottom) / math.Max(float64(len(c.bars)), 1)
+	barX := func(v float64) float64 {
+		return left + scale.position(v)*(right-left)
+	}
+
+	for _, t := range scale.ticks() {
+		x := barX(t)
+		fmt.Fprintf(&b, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%d\" stroke=\"#ddd\"/>\n", x, chartTop, x, chartHeight-chartBottom)
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", x, chartHeight-chartBottom+18, formatTick(t))
+	}
+	fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%d\" text-anchor=\"middle\">%s</text>\n", (left+right)/2, chartHeight-15, escapeXML(c.unit))
+
+	for i, bar := range c.bars {
+		y := float64(chartTop) + float64(i)*rowHeight
+		fmt.Fprintf(&b, "<rect x=\"%.1f\" y=\"%.1f\" width=\"%.1f\" height=\"%.1f\" fill=\"%s\"/>\n", left, y+rowHeight*0.15, barX(bar.value)-left, rowHeight*0.7, seriesColors[1])
+		fmt.Fprintf(&b, "<text x=\"%.1f\" y=\"%.1f\" text-anchor=\"end\">%s</text>\n", left-6, y+rowHeight/2+4, escapeXML(bar.label))
+	}
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// methodTotals sums one method's results across every dataset it ran on.
+type methodTotals struct {
+	method       string
+	vectors      int
+	uncompressed int
+	compressed   int
+	baseline     int
+	errorSum     float64
+	maxError     float64
+	duration     time.Duration
+
+	// timed is false for methods whose duration covers more than packing and
+	// unpacking the vectors, which are left off the runtime chart.
+	timed bool
+}
+
+// totalMethods sums each method that decoded vectors, in the order they
+// were first run.
+func totalMethods(datasets []dataset) []*methodTotals {
+	totals := make([]*methodTotals, 0)
+	byMethod := make(map[string]*methodTotals)
+	for _, ds := range datasets {
+		baseline := 0
+		for _, e := range ds.entries {
+			if e.method == "Baseline" {
+				baseline = e.uncomressed
+			}
+		}
+
+		for _, e := range ds.entries {
+			if e.angular == nil || e.angular.Count == 0 {
+				continue
+			}
+			t, ok := byMethod[e.method]
+			if !ok {
+				// glTF entries time writing and reading back a whole file
+				t = &methodTotals{method: e.method, timed: !strings.HasPrefix(e.method, "glTF ")}
+				byMethod[e.method] = t
+				totals = append(totals, t)
+			}
+			t.vectors += e.angular.Count
+			t.uncompressed += e.uncomressed
+			t.compressed += e.compressed
+			t.baseline += baseline
+			t.errorSum += e.angular.Mean * float64(e.angular.Count)
+			t.maxError = math.Max(t.maxError, e.angular.Max)
+			if e.duration != nil {
+				t.duration += *e.duration
+			}
+		}
+	}
+	return totals
+}
+
+func writeSVGFile(path string, chart interface{ WriteSVG(io.Writer) error }) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := chart.WriteSVG(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeCharts plots every method's error against its size, its compression
+// against its error, and its runtime, summed across the datasets.
+func writeCharts(dir string, datasets []dataset) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	totals := totalMethods(datasets)
+	errorPoints := make([]chartPoint, 0, len(totals)*2)
+	compressionPoints := make([]chartPoint, 0, len(totals))
+	bars := make([]chartBar, 0, len(totals))
+	for _, t := range totals {
+		bits := float64(t.uncompressed*8) / float64(t.vectors)
+		mean := t.errorSum / float64(t.vectors)
+		errorPoints = append(
+			errorPoints,
+			chartPoint{series: 0, label: t.method, x: bits, y: mean},
+			chartPoint{series: 1, label: t.method, x: bits, y: t.maxError},
+		)
+		compressionPoints = append(compressionPoints, chartPoint{
+			label: t.method,
+			x:     mean,
+			y:     float64(t.baseline) / float64(t.compressed),
+		})
+		if t.timed {
+			bars = append(bars, chartBar{
+				label: t.method,
+				value: float64(t.duration.Nanoseconds()) / float64(t.vectors),
+			})
+		}
+	}
+
+	pointValues := func(points []chartPoint, x bool) []float64 {
+		values := make([]float64, len(points))
+		for i, p := range points {
+			values[i] = p.y
+			if x {
+				values[i] = p.x
+			}
+		}
+		return values
+	}
+
+	charts := map[string]interface{ WriteSVG(io.Writer) error }{
+		"avgerr.svg": scatterChart{
+			title:  "Angular Error by Size",
+			series: []string{"Mean", "Max"},
+			x:      newAxis("Bits per Vector", false, pointValues(errorPoints, true)),
+			y:      newAxis("Angular Error (degrees)", true, pointValues(errorPoints, false)),
+			points: errorPoints,
+		},
+		"compressionvsavgerr.svg": scatterChart{
+			title:  "Compression Ratio by Mean Angular Error",
+			series: []string{"Method"},
+			x:      newAxis("Mean Angular Error (degrees)", true, pointValues(compressionPoints, true)),
+			y:      newAxis("Baseline Size / Compressed Size", false, pointValues(compressionPoints, false)),
+			points: compressionPoints,
+		},
+		"runtime.svg": barChart{
+			title: "Pack and Unpack Runtime",
+			unit:  "Nanoseconds per Vector",
+			bars:  bars,
+		},
+	}
+	for name, chart := range charts {
+		if err := writeSVGFile(filepath.Join(dir, name), chart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// compressor is a general purpose compression algorithm packed data can be
+// stored with.
+type compressor struct {
+	name   string
+	writer func(w io.Writer) (io.WriteCloser, error)
+	reader func(r io.Reader) (io.ReadCloser, error)
+}
+
+func flateCompressor(level int) compressor {
+	return compressor{
+		name: fmt.Sprintf("flate-%d", level),
+		writer: func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, level)
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(r), nil
+		},
+	}
+}
+
+var compressors = []compressor{
+	flateCompressor(1),
+	flateCompressor(6),
+	flateCompressor(9),
+	{
+		name: "gzip",
+		writer: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+	{
+		name: "zlib",
+		writer: func(w io.Writer) (io.WriteCloser, error) {
+			return zlib.NewWriter(w), nil
+		},
+		reader: zlib.NewReader,
+	},
+	{
+		name: "lzw",
+		writer: func(w io.Writer) (io.WriteCloser, error) {
+			return lzw.NewWriter(w, lzw.LSB, 8), nil
+		},
+		reader: func(r io.Reader) (io.ReadCloser, error) {
+			return lzw.NewReader(r, lzw.LSB, 8), nil
+		},
+	},
+}
+
+func compressorByName(name string) (compressor, error) {
+	names := make([]string, len(compressors))
+	for i, c := range compressors {
+		if c.name == name {
+			return c, nil
+		}
+		names[i] = c.name
+	}
+	return compressor{}, fmt.Errorf("unknown compressor %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+func (c compressor) compress(data []byte) ([]byte, error) {
+	out := bytes.Buffer{}
+	w, err := c.writer(&out)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func (c compressor) decompress(data []byte) ([]byte, error) {
+	r, err := c.reader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	return decompressed, r.Close()
+}
+
+// Layouts packed data is compressed in. Shuffling groups the bytes or bits
+// that sit at the same position within each value together.
+const (
+	layoutPlain        = "plain"
+	layoutByteShuffled = "byte shuffled"
+	layoutBitShuffled  = "bit shuffled"
+)
+
+// compressionResult is how well and how fast one compressor handled one
+// layout of a method's packed data.
+type compressionResult struct {
+	compressor string
+	layout     string
+	size       int
+	compress   time.Duration
+	decompress time.Duration
+}
+
+// measureCompression compresses the data laid out plainly, byte shuffled
+// and bit shuffled by values width bytes wide, with each compressor. Each
+// compressed copy is decompressed and checked against what went in.
+func measureCompression(compressors []compressor, data []byte, width int) ([]compressionResult, error) {
+	layouts := []struct {
+		name string
+		data []byte
+	}{
+		{layoutPlain, data},
+		{layoutByteShuffled, unitpacking.ByteShuffle(data, width)},
+		{layoutBitShuffled, unitpacking.BitShuffle(data, width)},
+	}
+
+	results := make([]compressionResult, 0, len(compressors)*len(layouts))
+	for _, c := range compressors {
+		for _, layout := range layouts {
+			start := time.Now()
+			compressed, err := c.compress(layout.data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.name, err)
+			}
+			compressDuration := time.Since(start)
+
+			start = time.Now()
+			decompressed, err := c.decompress(compressed)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.name, err)
+			}
+			decompressDuration := time.Since(start)
+
+			if !bytes.Equal(decompressed, layout.data) {
+				return nil, fmt.Errorf("%s didn't decompress %s data back to the original", c.name, layout.name)
+			}
+
+			results = append(results, compressionResult{
+				compressor: c.name,
+				layout:     layout.name,
+				size:       len(compressed),
+				compress:   compressDuration,
+				decompress: decompressDuration,
+			})
+		}
+	}
+	return results, nil
+}
+
+// compressionTotals sums a method, compressor and layout's results across
+// datasets.
+type compressionTotals struct {
+	method       string
+	uncompressed int
+	compressionResult
+}
+
+// totalCompression sums the compression results of every method across the
+// datasets, in the order they were first run.
+func totalCompression(datasets []dataset) []*compressionTotals {
+	totals := make([]*compressionTotals, 0)
+	byKey := make(map[string]*compressionTotals)
+	for _, ds := range datasets {
+		for _, e := range ds.entries {
+			for _, r := range e.compression {
+				key := e.method + "\x00" + r.compressor + "\x00" + r.layout
+				t, ok := byKey[key]
+				if !ok {
+					t = &compressionTotals{
+						method:            e.method,
+						compressionResult: compressionResult{compressor: r.compressor, layout: r.layout},
+					}
+					byKey[key] = t
+					totals = append(totals, t)
+				}
+				t.uncompressed += e.uncomressed
+				t.size += r.size
+				t.compress += r.compress
+				t.decompress += r.decompress
+			}
+		}
+	}
+	return totals
+}
+
+// megabytesPerSecond is how fast uncompressed bytes went through in the
+// given time.
+func megabytesPerSecond(size int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(size) / (1024 * 1024) / d.Seconds()
+}
+
+// writeCompression lists how every compressor did on every method's data,
+// summed across the datasets.
+func writeCompression(out io.Writer, datasets []dataset, csv bool) error {
+	if csv {
+		if _, err := fmt.Fprintln(out, "\"method\", \"compressor\", \"layout\", \"uncompressed\", \"compressed\", \"compression ratio\", \"compress ms\", \"decompress ms\", \"compress MB/s\", \"decompress MB/s\""); err != nil {
+			return err
+		}
+	} else {
+		_, err := fmt.Fprint(
+			out,
+			"\n### Compression\n\nEvery method's packed data compressed with each compressor, summed across the datasets. Throughput is in megabytes of uncompressed data per second.\n\n| Method | Compressor | Layout | Uncompressed | Compressed | Ratio | Compress | Decompress | Compress MB/s | Decompress MB/s |\n|-|-|-|-|-|-|-|-|-|-|\n",
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, t := range totalCompression(datasets) {
+		ratio := float64(t.uncompressed) / float64(t.size)
+		compressSpeed := megabytesPerSecond(t.uncompressed, t.compress)
+		decompressSpeed := megabytesPerSecond(t.uncompressed, t.decompress)
+
+		var err error
+		if csv {
+			_, err = fmt.Fprintf(
+				out,
+				"%q, %q, %q, %d, %d, %.4f, %.3f, %.3f, %.2f, %.2f\n",
+				t.method, t.compressor, t.layout, t.uncompressed, t.size, ratio,
+				t.compress.Seconds()*1000, t.decompress.Seconds()*1000, compressSpeed, decompressSpeed,
+			)
+		} else {
+			_, err = fmt.Fprintf(
+				out,
+				"| %s | %s | %s | %s | %s | %s | %s | %s | %.2f | %.2f |\n",
+				t.method, t.compressor, t.layout, formatSize(t.uncompressed), formatSize(t.size), formatRatio(ratio),
+				t.compress, t.decompress, compressSpeed, decompressSpeed,
+			)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// config is everything that decides what a benchmark run measures. Two runs
+// with the same config measure the same vectors, so only the runtimes differ
+// between machines.
+type config struct {
+	// Data is the directory models are loaded from.
+	Data string `json:"data"`
+
+	// Vectors is how many random unit vectors make up the random dataset.
+	Vectors int `json:"vectors"`
+
+	// Seed seeds the random dataset.
+	Seed int64 `json:"seed"`
+
+	// Codecs names the codecs to run, or all of them when empty.
+	Codecs []string `json:"codecs"`
+
+	// Compressors names the compressors packed data is measured with. The
+	// first fills the compression columns of the results table.
+	Compressors []string `json:"compressors"`
+
+	// Thresholds are the angular errors, in degrees, to report the fraction
+	// of vectors above.
+	Thresholds []float64 `json:"thresholds"`
+
+	// MaxError is how far any component of a decoded vector can be from the
+	// original before the vector counts as an outlier.
+	MaxError float64 `json:"maxError"`
+
+	// Outliers is how many of the worst outliers to report for each method.
+	Outliers int `json:"outliers"`
+
+	// Strict fails the run when there are any outliers.
+	Strict bool `json:"strict"`
+
+	// Format is markdown or csv.
+	Format string `json:"format"`
+
+	// ObjDir is where the models are written back out with their generated
+	// normals, or nowhere when empty.
+	ObjDir string `json:"objDir"`
+
+	// Charts is where SVG charts of the results are written, or nowhere when
+	// empty.
+	Charts string `json:"charts"`
+}
+
+func defaultConfig() config {
+	return config{
+		Data:        "../../../common-3d-test-models/data",
+		Vectors:     10000000,
+		Seed:        1,
+		Compressors: []string{"flate-9", "flate-6", "flate-1", "gzip", "zlib", "lzw"},
+		Thresholds:  []float64{0.1, 0.5, 1},
+		MaxError:    0.1,
+		Outliers:    5,
+		Format:      "markdown",
+		ObjDir:      ".",
+	}
+}
+
+func readConfig(path string) (config, error) {
+	cfg := defaultConfig()
+	f, err := os.Open(path)
+	if err != nil {
+		return cfg, err
+	}
+	defer f.Close()
+
+	decoder := json.NewDecoder(f)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseConfig reads the config file if one is given, then overrides it with
+// any flags that were set explicitly.
+func parseConfig(set *flag.FlagSet, args []string) (config, bool, error) {
+	defaults := defaultConfig()
+	configPath := set.String("config", "", "JSON file to read the config from, overridden by any other flags")
+	data := set.String("data", defaults.Data, "directory of models to benchmark")
+	vectors := set.Int("vectors", defaults.Vectors, "number of random unit vectors to benchmark")
+	seed := set.Int64("seed", defaults.Seed, "seed for the random unit vectors")
+	codecs := set.String("codecs", "", "comma separated codecs to run, defaults to all of them")
+	compressorsFlag := set.String("compressors", strings.Join(defaults.Compressors, ","), "comma separated compressors to measure packed data with, the first fills the results table")
+	thresholds := set.String("thresholds", formatThresholds(defaults.Thresholds), "comma separated angular errors, in degrees, to report the fraction of vectors above")
+	maxError := set.Float64("max-error", defaults.MaxError, "largest error allowed in any component of a decoded vector before it counts as an outlier")
+	outliers := set.Int("outliers", defaults.Outliers, "number of the worst outliers to report for each method")
+	strict := set.Bool("strict", defaults.Strict, "exit with an error when any vector is an outlier")
+	format := set.String("format", defaults.Format, "output format, markdown or csv")
+	objDir := set.String("obj-dir", defaults.ObjDir, "directory to write models with generated normals to, skipped when empty")
+	charts := set.String("charts", defaults.Charts, "directory to write SVG charts of the results to, skipped when empty")
+	dump := set.Bool("dump-config", false, "print the config as JSON and exit")
+	if err := set.Parse(args); err != nil {
+		return config{}, false, err
+	}
+	if set.NArg() > 0 {
+		return config{}, false, fmt.Errorf("unexpected arguments %v", set.Args())
+	}
+
+	cfg := defaults
+	if *configPath != "" {
+		var err error
+		if cfg, err = readConfig(*configPath); err != nil {
+			return config{}, false, err
+		}
+	}
+
+	var err error
+	set.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "thresholds":
+			cfg.Thresholds, err = parseThresholds(*thresholds)
+		case "data":
+			cfg.Data = *data
+		case "vectors":
+			cfg.Vectors = *vectors
+		case "seed":
+			cfg.Seed = *seed
+		case "codecs":
+			cfg.Codecs = strings.Split(*codecs, ",")
+		case "compressors":
+			cfg.Compressors = strings.Split(*compressorsFlag, ",")
+		case "max-error":
+			cfg.MaxError = *maxError
+		case "outliers":
+			cfg.Outliers = *outliers
+		case "strict":
+			cfg.Strict = *strict
+		case "format":
+			cfg.Format = *format
+		case "obj-dir":
+			cfg.ObjDir = *objDir
+		case "charts":
+			cfg.Charts = *charts
+		}
+	})
+
+	if err != nil {
+		return config{}, false, err
+	}
+
+	return cfg, *dump, cfg.validate()
+}
+
+func formatThresholds(thresholds []float64) string {
+	fields := make([]string, len(thresholds))
+	for i, threshold := range thresholds {
+		fields[i] = strconv.FormatFloat(threshold, 'g', -1, 64)
+	}
+	return strings.Join(fields, ",")
+}
+
+func parseThresholds(list string) ([]float64, error) {
+	thresholds := make([]float64, 0)
+	for _, field := range strings.Split(list, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse threshold %q", field)
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func (cfg config) validate() error {
+	if cfg.Vectors < 0 {
+		return fmt.Errorf("vectors can't be negative, got %d", cfg.Vectors)
+	}
+	if cfg.MaxError < 0 || math.IsNaN(cfg.MaxError) {
+		return fmt.Errorf("max error can't be negative, got %g", cfg.MaxError)
+	}
+	if cfg.Outliers < 0 {
+		return fmt.Errorf("outliers can't be negative, got %d", cfg.Outliers)
+	}
+	for _, threshold := range cfg.Thresholds {
+		if threshold < 0 || math.IsNaN(threshold) || math.IsInf(threshold, 0) {
+			return fmt.Errorf("thresholds must be finite and not negative, got %g", threshold)
+		}
+	}
+	if cfg.Format != "markdown" && cfg.Format != "csv" {
+		return fmt.Errorf("unknown format %q, expected markdown or csv", cfg.Format)
+	}
+	if _, err := cfg.compressors(); err != nil {
+		return err
+	}
+	_, err := cfg.codecs()
+	return err
+}
+
+// codecs looks up the codecs the config names.
+func (cfg config) codecs() ([]unitpacking.Codec, error) {
+	if len(cfg.Codecs) == 0 {
+		return unitpacking.Codecs(), nil
+	}
+
+	codecs := make([]unitpacking.Codec, 0, len(cfg.Codecs))
+	for _, name := range cfg.Codecs {
+		codec, ok := unitpacking.CodecByName(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown codec %q", name)
+		}
+		codecs = append(codecs, codec)
+	}
+	return codecs, nil
+}
+
+// compressors looks up the compressors the config names.
+func (cfg config) compressors() ([]compressor, error) {
+	selected := make([]compressor, 0, len(cfg.Compressors))
+	for _, name := range cfg.Compressors {
+		c, err := compressorByName(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, c)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("at least one compressor is needed")
+	}
+	return selected, nil
+}
@@ -1,24 +1,34 @@
+// Command benchmark measures the error, size and speed of every packing
+// method on random unit vectors and on normals generated for a directory of
+// models.
+//
+//	benchmark -data ./models -vectors 1000000 -format csv
+//	benchmark -config benchmark.json -obj-dir ""
+//	benchmark -vectors 1000000 -charts docs
+//
+// Flags override the values read from -config, and -dump-config prints the
+// resulting config so a run can be repeated elsewhere.
 package main
 
 import (
-	"bufio"
 	"bytes"
-	"compress/flate"
 	"encoding/binary"
-	"errors"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/EliCDavis/mango"
 	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/meshio"
 	"github.com/recolude/unitpacking/unitpacking"
 )
 
@@ -27,8 +37,31 @@ func formatSize(sizeInBytes int) string {
 }
 
 type dataset struct {
-	set     string
-	entries []runResultEntry
+	set        string
+	thresholds []float64
+	entries    []runResultEntry
+}
+
+// writeHeader writes the column names for datasets measured against the
+// given angular error thresholds.
+func writeHeader(out io.Writer, thresholds []float64, csv bool) error {
+	if csv {
+		columns := []string{"dataset", "method", "runtime", "average error", "mean angular error", "rms angular error", "max angular error", "p50 angular error", "p95 angular error", "p99 angular error", "p99.9 angular error"}
+		for _, threshold := range thresholds {
+			columns = append(columns, fmt.Sprintf("over %g degrees", threshold))
+		}
+		columns = append(columns, "uncompressed", "compressed", "compression ratio", "byte shuffled compression ratio", "bit shuffled compression ratio")
+		_, err := fmt.Fprintf(out, "\"%s\"\n", strings.Join(columns, "\", \""))
+		return err
+	}
+
+	columns := []string{"Dataset", "Method", "Runtime", "Average Error", "Mean Angle", "RMS Angle", "Max Angle", "P50", "P95", "P99", "P99.9"}
+	for _, threshold := range thresholds {
+		columns = append(columns, fmt.Sprintf("Over %g°", threshold))
+	}
+	columns = append(columns, "Uncompressed", "Compressed", "Compression Ratio", "Byte Shuffled Ratio", "Bit Shuffled Ratio")
+	_, err := fmt.Fprintf(out, "| %s |\n|%s\n", strings.Join(columns, " | "), strings.Repeat("-|", len(columns)))
+	return err
 }
 
 func (ds dataset) WriteCSV(out io.Writer) (int, error) {
@@ -46,19 +79,18 @@ func (ds dataset) WriteCSV(out io.Writer) (int, error) {
 			avgErrOut = fmt.Sprintf("%.6f", *e.avgError)
 		}
 
-		compressedFmted := fmt.Sprintf("%.4f", float64(e.uncomressed)/float64(e.compressed))
-
-		n, err := fmt.Fprintf(
-			out,
-			"\"%s\", \"%s\", %s, %s, %d, %d, %s\n",
-			ds.set,
-			e.method,
-			runtimeOut,
-			avgErrOut,
-			e.uncomressed,
-			e.compressed,
-			compressedFmted,
+		columns := []string{fmt.Sprintf("\"%s\"", ds.set), fmt.Sprintf("\"%s\"", e.method), runtimeOut, avgErrOut}
+		columns = append(columns, e.angularColumns(len(ds.thresholds), true)...)
+		columns = append(
+			columns,
+			fmt.Sprintf("%d", e.uncomressed),
+			fmt.Sprintf("%d", e.compressed),
+			fmt.Sprintf("%.4f", e.compressionRatio()),
+			fmt.Sprintf("%.4f", e.byteShuffledRatio()),
+			fmt.Sprintf("%.4f", e.bitShuffledRatio()),
 		)
+
+		n, err := fmt.Fprintln(out, strings.Join(columns, ", "))
 		writtenCount += n
 		if err != nil {
 			return writtenCount, err
@@ -83,24 +115,18 @@ func (ds dataset) Write(out io.Writer) (int, error) {
 			avgErrOut = fmt.Sprintf("%.6f", *e.avgError)
 		}
 
-		compressedFmted := fmt.Sprintf("%.4f", float64(e.uncomressed)/float64(e.compressed))
-
-		// compressing it made it worse.
-		if e.compressed > e.uncomressed {
-			compressedFmted = fmt.Sprintf("<div style=\"color:red\">%s</div>", compressedFmted)
-		}
-
-		n, err := fmt.Fprintf(
-			out,
-			"| %s | %s | %s | %s | %s | %s | %s |\n",
-			ds.set,
-			e.method,
-			runtimeOut,
-			avgErrOut,
+		columns := []string{ds.set, e.method, runtimeOut, avgErrOut}
+		columns = append(columns, e.angularColumns(len(ds.thresholds), false)...)
+		columns = append(
+			columns,
 			formatSize(e.uncomressed),
 			formatSize(e.compressed),
-			compressedFmted,
+			formatRatio(e.compressionRatio()),
+			formatRatio(e.byteShuffledRatio()),
+			formatRatio(e.bitShuffledRatio()),
 		)
+
+		n, err := fmt.Fprintf(out, "| %s |\n", strings.Join(columns, " | "))
 		writtenCount += n
 		if err != nil {
 			return writtenCount, err
@@ -111,143 +137,206 @@ func (ds dataset) Write(out io.Writer) (int, error) {
 }
 
 type runResultEntry struct {
-	method      string
-	compressed  int
-	uncomressed int
-	duration    *time.Duration
-	avgError    *float64
+	method       string
+	compressed   int
+	uncomressed  int
+	byteShuffled int
+	bitShuffled  int
+	duration     *time.Duration
+	avgError     *float64
+
+	// angular is the angular error in degrees, and outliers the vectors
+	// that decoded furthest off. Both are nil when the entry doesn't decode
+	// anything
+	angular  *unitpacking.ErrorSummary
+	outliers *outliers
+
+	// compression is how each compressor did on the packed data
+	compression []compressionResult
 }
 
-func (rre runResultEntry) compressionRatio() float64 {
-	return float64(rre.uncomressed) / float64(rre.compressed)
-}
+// angularColumns formats the entry's mean, RMS, max and percentile angular
+// errors, followed by the fraction of vectors over each threshold.
+func (rre runResultEntry) angularColumns(thresholds int, csv bool) []string {
+	columns := make([]string, 0, 7+thresholds)
+	if rre.angular == nil {
+		notAvailable := "N/A"
+		if csv {
+			notAvailable = "NA"
+		}
+		for i := 0; i < 7+thresholds; i++ {
+			columns = append(columns, notAvailable)
+		}
+		return columns
+	}
 
-type unitWriter interface {
-	pack(v vector.Vector3) []byte
-	unpack(b []byte) vector.Vector3
-	method() string
+	a := rre.angular
+	for _, degrees := range []float64{a.Mean, a.RMS, a.Max, a.P50, a.P95, a.P99, a.P999} {
+		if csv {
+			columns = append(columns, fmt.Sprintf("%.6f", degrees))
+		} else {
+			columns = append(columns, fmt.Sprintf("%.4f°", degrees))
+		}
+	}
+	for _, fraction := range a.OverThreshold {
+		if csv {
+			columns = append(columns, fmt.Sprintf("%.6f", fraction))
+		} else {
+			columns = append(columns, fmt.Sprintf("%.4f%%", fraction*100))
+		}
+	}
+	return columns
 }
 
-type alg24Writer struct{ out io.Writer }
-
-func (alg24w alg24Writer) method() string                 { return "alg24" }
-func (alg24w alg24Writer) pack(v vector.Vector3) []byte   { return unitpacking.PackAlg24(v) }
-func (alg24w alg24Writer) unpack(b []byte) vector.Vector3 { return unitpacking.UnpackAlg24(b) }
-
-type coarse24Writer struct{ out io.Writer }
-
-func (coarse24w coarse24Writer) method() string                 { return "coarse24" }
-func (coarse24w coarse24Writer) pack(v vector.Vector3) []byte   { return unitpacking.PackCoarse24(v) }
-func (coarse24w coarse24Writer) unpack(b []byte) vector.Vector3 { return unitpacking.UnpackCoarse24(b) }
-
-type octQuad16Writer struct{ out io.Writer }
-
-func (octQuad16w octQuad16Writer) method() string               { return "octquad16" }
-func (octQuad16w octQuad16Writer) pack(v vector.Vector3) []byte { return unitpacking.PackOctQuad16(v) }
-func (octQuad16w octQuad16Writer) unpack(b []byte) vector.Vector3 {
-	return unitpacking.UnpackOctQuad16(b)
+func (rre runResultEntry) compressionRatio() float64 {
+	return float64(rre.uncomressed) / float64(rre.compressed)
 }
 
-type octQuad24Writer struct{ out io.Writer }
-
-func (octQuad24w octQuad24Writer) method() string               { return "octquad24" }
-func (octQuad24w octQuad24Writer) pack(v vector.Vector3) []byte { return unitpacking.PackOctQuad24(v) }
-func (octQuad24w octQuad24Writer) unpack(b []byte) vector.Vector3 {
-	return unitpacking.UnpackOctQuad24(b)
+func (rre runResultEntry) byteShuffledRatio() float64 {
+	return float64(rre.uncomressed) / float64(rre.byteShuffled)
 }
 
-type octQuad32Writer struct{ out io.Writer }
-
-func (octQuad32w octQuad32Writer) method() string               { return "octquad32" }
-func (octQuad32w octQuad32Writer) pack(v vector.Vector3) []byte { return unitpacking.PackOctQuad32(v) }
-func (octQuad32w octQuad32Writer) unpack(b []byte) vector.Vector3 {
-	return unitpacking.UnpackOctQuad32(b)
+func (rre runResultEntry) bitShuffledRatio() float64 {
+	return float64(rre.uncomressed) / float64(rre.bitShuffled)
 }
 
-type oct16Writer struct{ out io.Writer }
-
-func (oct16w oct16Writer) method() string                 { return "oct16" }
-func (oct16w oct16Writer) pack(v vector.Vector3) []byte   { return unitpacking.PackOct16(v) }
-func (oct16w oct16Writer) unpack(b []byte) vector.Vector3 { return unitpacking.UnpackOct16(b) }
-
-type oct24Writer struct{ out io.Writer }
-
-func (oct24w oct24Writer) method() string                 { return "oct24" }
-func (oct24w oct24Writer) pack(v vector.Vector3) []byte   { return unitpacking.PackOct24(v) }
-func (oct24w oct24Writer) unpack(b []byte) vector.Vector3 { return unitpacking.UnpackOct24(b) }
+func formatRatio(ratio float64) string {
+	formatted := fmt.Sprintf("%.4f", ratio)
 
-type oct32Writer struct{ out io.Writer }
+	// compressing it made it worse.
+	if ratio < 1 {
+		formatted = fmt.Sprintf("<div style=\"color:red\">%s</div>", formatted)
+	}
 
-func (oct32w oct32Writer) method() string                 { return "oct32" }
-func (oct32w oct32Writer) pack(v vector.Vector3) []byte   { return unitpacking.PackOct32(v) }
-func (oct32w oct32Writer) unpack(b []byte) vector.Vector3 { return unitpacking.UnpackOct32(b) }
+	return formatted
+}
 
-func assertLowErr(unpacked, original vector.Vector3) {
-	if math.Abs(original.X()-unpacked.X()) > 0.1 {
-		panic("errr")
+// compress measures the config's compressors on the packed data, filling the
+// compression columns from the first of them.
+func (rre *runResultEntry) compress(cfg config, data []byte, width int) error {
+	selected, err := cfg.compressors()
+	if err != nil {
+		return err
 	}
 
-	if math.Abs(original.Y()-unpacked.Y()) > 0.1 {
-		panic("errr")
+	results, err := measureCompression(selected, data, width)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rre.method, err)
 	}
 
-	if math.Abs(original.Z()-unpacked.Z()) > 0.1 {
-		panic("errr")
-	}
+	rre.uncomressed = len(data)
+	rre.compressed = results[0].size
+	rre.byteShuffled = results[1].size
+	rre.bitShuffled = results[2].size
+	rre.compression = results
+	return nil
 }
 
-func runBenchEnry(unitVectors []vector.Vector3, uw unitWriter) runResultEntry {
+func runBenchEnry(cfg config, unitVectors []vector.Vector3, codec unitpacking.Codec) (runResultEntry, error) {
 	accErr := 0.0
+	angular := unitpacking.ErrorStats{Thresholds: cfg.Thresholds}
+	worst := newOutliers(cfg.MaxError, cfg.Outliers)
 
 	// Just time it...
 	start := time.Now()
 	for _, v := range unitVectors {
-		uw.unpack(uw.pack(v))
+		codec.Unpack(codec.Pack(v))
 	}
 	duration := time.Since(start)
 
 	// Now calculate error and compression
 	out := bytes.Buffer{}
-	compressedOut := bytes.Buffer{}
-	comressedWriter, err := flate.NewWriter(&compressedOut, 9)
-	if err != nil {
-		panic(err)
-	}
 	for x, v := range unitVectors {
-		unpacked := uw.unpack(uw.pack(v))
-		out.Write(uw.pack(v))
-		comressedWriter.Write(uw.pack(v))
+		packed := codec.Pack(v)
+		unpacked := codec.Unpack(packed)
+		out.Write(packed)
 		accErr += math.Abs(v.X() - unpacked.X())
 		accErr += math.Abs(v.Y() - unpacked.Y())
 		accErr += math.Abs(v.Z() - unpacked.Z())
-		assertLowErr(unpacked, v)
-		if math.IsNaN(accErr) {
-			panic("somehow got to nan: " + fmt.Sprint(x))
-		}
+		angular.Add(v, unpacked)
+		worst.check(x, v, unpacked, packed)
 	}
 
-	comressedWriter.Flush()
-
 	avgErr := accErr / float64(len(unitVectors)*3)
-	return runResultEntry{
-		method:      uw.method(),
-		compressed:  compressedOut.Len(),
-		uncomressed: out.Len(),
-		avgError:    &avgErr,
-		duration:    &duration,
-	}
+	summary := angular.Summary()
+	entry := runResultEntry{
+		method:   codec.Name(),
+		avgError: &avgErr,
+		duration: &duration,
+		angular:  &summary,
+		outliers: worst,
+	}
+	return entry, entry.compress(cfg, out.Bytes(), codec.Size())
 }
 
-func runDataset(unitVectors []vector.Vector3, name string, methods []unitWriter) dataset {
+func runDataset(cfg config, unitVectors []vector.Vector3, name string, methods []unitpacking.Codec) (dataset, error) {
 	results := make([]runResultEntry, len(methods)+1)
-	results[0] = runbaseline(unitVectors)
+	var err error
+	if results[0], err = runbaseline(cfg, unitVectors); err != nil {
+		return dataset{}, err
+	}
 	for i, m := range methods {
-		results[i+1] = runBenchEnry(unitVectors, m)
+		if results[i+1], err = runBenchEnry(cfg, unitVectors, m); err != nil {
+			return dataset{}, err
+		}
 	}
 	return dataset{
-		set:     name,
-		entries: results,
+		set:        name,
+		thresholds: cfg.Thresholds,
+		entries:    results,
+	}, nil
+}
+
+// runGLTFEntries measures normals stored in the vertex buffer of a glTF file,
+// once per encoding. Errors come from reading the written file back, the same
+// as a viewer would see them.
+func runGLTFEntries(cfg config, model mango.Mesh, normals []vector.Vector3) ([]runResultEntry, error) {
+	entries := make([]runResultEntry, 0)
+	for _, encoding := range meshio.GLTFNormalEncodings() {
+		data, err := meshio.EncodeGLTFNormals(normals, encoding)
+		if err != nil {
+			return nil, err
+		}
+
+		start := time.Now()
+		glb := bytes.Buffer{}
+		if err := meshio.WriteGLB(&glb, model, normals, encoding); err != nil {
+			return nil, err
+		}
+		read, err := meshio.ReadGLTF(&glb)
+		if err != nil {
+			return nil, err
+		}
+		duration := time.Since(start)
+
+		stride := len(data) / len(normals)
+		accErr := 0.0
+		angular := unitpacking.ErrorStats{Thresholds: cfg.Thresholds}
+		worst := newOutliers(cfg.MaxError, cfg.Outliers)
+		for i, v := range normals {
+			unpacked := read.Normals[i]
+			accErr += math.Abs(v.X() - unpacked.X())
+			accErr += math.Abs(v.Y() - unpacked.Y())
+			accErr += math.Abs(v.Z() - unpacked.Z())
+			angular.Add(v, unpacked)
+			worst.check(i, v, unpacked, data[i*stride:(i+1)*stride])
+		}
+		avgErr := accErr / float64(len(normals)*3)
+		summary := angular.Summary()
+
+		entry := runResultEntry{
+			method:   fmt.Sprintf("glTF %s", encoding),
+			avgError: &avgErr,
+			duration: &duration,
+			angular:  &summary,
+			outliers: worst,
+		}
+		if err := entry.compress(cfg, data, stride); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
 	}
+	return entries, nil
 }
 
 func getDatasetPathsFromDir(dir string) ([]string, error) {
@@ -261,7 +350,7 @@ func getDatasetPathsFromDir(dir string) ([]string, error) {
 		if file.IsDir() {
 			continue
 		}
-		if strings.ToLower(filepath.Ext(file.Name())) == ".obj" {
+		if _, ok := meshio.Readers[strings.ToLower(filepath.Ext(file.Name()))]; ok {
 			validFiles = append(validFiles, file.Name())
 		}
 	}
@@ -321,147 +410,38 @@ func calcSmoothNormals(m mango.Mesh) []vector.Vector3 {
 	return normals
 }
 
-func firstWords(value string, count int) (string, string) {
-	// Loop over all indexes in the string.
-	for i := range value {
-		// If we encounter a space, reduce the count.
-		if value[i] == ' ' {
-			count--
-			// When no more words required, return a substring.
-			if count == 0 {
-				return value[0:i], value[i+1:]
-			}
-		}
-	}
-	// Return the entire string.
-	return value, ""
-}
-
-func strToVector(str string) (*vector.Vector3, error) {
-	components := strings.Split(str, " ")
-
-	if len(components) != 3 {
-		return nil, errors.New("unable to parse: " + str)
-	}
-
-	xParse, err := strconv.ParseFloat(components[0], 64)
-	if err != nil {
-		return nil, errors.New("unable to parse X componenent: " + components[0])
-	}
-
-	yParse, err := strconv.ParseFloat(components[1], 64)
-	if err != nil {
-		return nil, errors.New("unable to parse Y componenent: " + components[1])
-	}
-
-	zParse, err := strconv.ParseFloat(components[2], 64)
-	if err != nil {
-		return nil, errors.New("unable to parse Z componenent: " + components[2])
-	}
-
-	v := vector.NewVector3(xParse, yParse, zParse)
-	return &v, nil
-}
-
-func strToFaceIndexes(str string) (int, int, int, error) {
-	components := strings.Split(str, " ")
-
-	if len(components) != 3 {
-		return -1, -1, -1, fmt.Errorf("unable to parse: (%s)", str)
-	}
-
-	v1Components := strings.Split(components[0], "/")
-	v1Parse, err := strconv.Atoi(v1Components[0])
-	if err != nil {
-		return -1, -1, -1, errors.New("unable to parse X componenent: " + v1Components[0])
-	}
-
-	v2Components := strings.Split(components[1], "/")
-	v2Parse, err := strconv.Atoi(v2Components[0])
-	if err != nil {
-		return -1, -1, -1, errors.New("unable to parse Y componenent: " + v2Components[1])
-	}
-
-	v3Components := strings.Split(components[2], "/")
-	v3Parse, err := strconv.Atoi(v3Components[0])
-	if err != nil {
-		return -1, -1, -1, errors.New("unable to parse Z componenent: " + v3Components[0])
-	}
-
-	return v1Parse, v2Parse, v3Parse, nil
-}
-
-func importOBJ(objStream io.Reader) (mango.Mesh, error) {
-	if objStream == nil {
-		return mango.NewEmptyMesh(), errors.New("Need a reader to read obj from")
-	}
-
-	vertices := make([]vector.Vector3, 0)
-	faces := make([]mango.Tri, 0)
-
-	scanner := bufio.NewScanner(objStream)
-	for scanner.Scan() {
-		ln := scanner.Text()
-		firstWord, rest := firstWords(ln, 1)
-		if firstWord == "v" {
-			vector, err := strToVector(strings.TrimSpace(rest))
-			if err != nil {
-				return mango.NewEmptyMesh(), err
-			}
-			vertices = append(vertices, *vector)
-		}
-
-		if firstWord == "f" {
-			v1, v2, v3, err := strToFaceIndexes(strings.TrimSpace(rest))
-			if err != nil {
-				return mango.NewEmptyMesh(), err
-			}
-
-			faces = append(faces, mango.NewTri(v1-1, v2-1, v3-1))
+// authoredNormals returns the normals stored in the model's file, skipping any
+// without a direction, like the all zero facet normals some STL exporters
+// write.
+func authoredNormals(m meshio.Mesh) []vector.Vector3 {
+	normals := make([]vector.Vector3, 0, len(m.Normals))
+	for _, n := range m.Normals {
+		if n.Length() == 0 || math.IsNaN(n.Length()) {
+			continue
 		}
+		normals = append(normals, n.Normalized())
 	}
-
-	if scanner.Err() != nil {
-		return mango.NewEmptyMesh(), scanner.Err()
-	}
-
-	return mango.NewMesh(vertices, faces), nil
-}
-
-func loadModel(filePath string) (mango.Mesh, error) {
-	dat, err := os.Open(filePath)
-	if err != nil {
-		return mango.NewEmptyMesh(), err
-	}
-	return importOBJ(dat)
+	return normals
 }
 
-func runbaseline(unitVectors []vector.Vector3) runResultEntry {
-	compressedOut := bytes.Buffer{}
-	comressedWriter, err := flate.NewWriter(&compressedOut, 9)
-	if err != nil {
-		panic(err)
-	}
+func runbaseline(cfg config, unitVectors []vector.Vector3) (runResultEntry, error) {
+	out := bytes.Buffer{}
 
 	b := make([]byte, 4)
 	for _, v := range unitVectors {
 		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v.X())))
-		comressedWriter.Write(b)
+		out.Write(b)
 
 		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v.Y())))
-		comressedWriter.Write(b)
+		out.Write(b)
 
 		binary.LittleEndian.PutUint32(b, math.Float32bits(float32(v.Z())))
-		comressedWriter.Write(b)
+		out.Write(b)
 	}
 
-	comressedWriter.Flush()
-
-	return runResultEntry{
-		method:      "Baseline",
-		compressed:  compressedOut.Len(),
-		uncomressed: len(unitVectors) * 12,
-	}
+	// Shuffle by each 32bit float rather than each whole vector
+	entry := runResultEntry{method: "Baseline"}
+	return entry, entry.compress(cfg, out.Bytes(), 4)
 }
 
 func writeObj(mesh mango.Mesh, normals []vector.Vector3, out io.Writer) error {
@@ -489,53 +469,101 @@ func writeObj(mesh mango.Mesh, normals []vector.Vector3, out io.Writer) error {
 	return nil
 }
 
-func main() {
-	numOfVectors := 10000000
-	unitVectors := make([]vector.Vector3, numOfVectors)
+// randomDatasetName names the random dataset after how many vectors it has.
+func randomDatasetName(count int) string {
+	if count >= 1000000 && count%1000000 == 0 {
+		return fmt.Sprintf("%d million random", count/1000000)
+	}
+	return fmt.Sprintf("%d random", count)
+}
 
-	for i := 0; i < numOfVectors; i++ {
-		unitVectors[i] = vector.NewVector3(
-			(rand.Float64()*2.0)-1,
-			(rand.Float64()*2.0)-1,
-			(rand.Float64()*2.0)-1,
-		).Normalized()
+// runModelDataset benchmarks normals calculated for a model, writing the
+// model out with them when the config has somewhere to put it.
+func runModelDataset(cfg config, model mango.Mesh, normals []vector.Vector3, name string, methods []unitpacking.Codec) (dataset, error) {
+	if cfg.ObjDir != "" {
+		f, err := os.Create(filepath.Join(cfg.ObjDir, name+".obj"))
+		if err != nil {
+			return dataset{}, err
+		}
+		if err := writeObj(model, normals, f); err != nil {
+			f.Close()
+			return dataset{}, err
+		}
+		if err := f.Close(); err != nil {
+			return dataset{}, err
+		}
 	}
 
-	pathToLoadFrom := "../../../common-3d-test-models/data"
-	writeCSV := false
-	// pathToLoadFrom := os.Args[1]
-	availableFiles, err := getDatasetPathsFromDir(pathToLoadFrom)
+	set, err := runDataset(cfg, normals, name, methods)
+	if err != nil {
+		return dataset{}, err
+	}
+	gltfEntries, err := runGLTFEntries(cfg, model, normals)
 	if err != nil {
-		panic(err)
+		return dataset{}, err
 	}
+	set.entries = append(set.entries, gltfEntries...)
+	return set, nil
+}
 
-	unitWriters := []unitWriter{
-		alg24Writer{os.Stdout},
-		coarse24Writer{os.Stdout},
-		oct16Writer{os.Stdout},
-		oct24Writer{os.Stdout},
-		oct32Writer{os.Stdout},
-		octQuad16Writer{os.Stdout},
-		octQuad24Writer{os.Stdout},
-		octQuad32Writer{os.Stdout},
+func run(cfg config, out io.Writer) error {
+	unitWriters, err := cfg.codecs()
+	if err != nil {
+		return err
 	}
 
-	if writeCSV {
-		fmt.Fprintln(os.Stdout, "\"dataset\", \"method\", \"runtime\", \"average error\", \"uncompressed\", \"compressed\", \"compression ratio\"")
-	} else {
-		fmt.Fprintln(os.Stdout, "| Dataset | Method | Runtime | Average Error | Uncompressed | Compressed | Compression Ratio |")
-		fmt.Fprintln(os.Stdout, "|-|-|-|-|-|-|-|")
+	if cfg.ObjDir != "" {
+		if err := os.MkdirAll(cfg.ObjDir, 0755); err != nil {
+			return err
+		}
 	}
 
-	if writeCSV {
-		runDataset(unitVectors, "10 million random", unitWriters).WriteCSV(os.Stdout)
-	} else {
-		runDataset(unitVectors, "10 million random", unitWriters).Write(os.Stdout)
+	writeCSV := cfg.Format == "csv"
+	written := make([]dataset, 0)
+	write := func(set dataset) error {
+		written = append(written, set)
+		if writeCSV {
+			_, err := set.WriteCSV(out)
+			return err
+		}
+		_, err := set.Write(out)
+		return err
+	}
+
+	if err := writeHeader(out, cfg.Thresholds, writeCSV); err != nil {
+		return err
+	}
+
+	if cfg.Vectors > 0 {
+		// A seeded source generates the same vectors on every machine
+		r := rand.New(rand.NewSource(cfg.Seed))
+		unitVectors := make([]vector.Vector3, cfg.Vectors)
+		for i := range unitVectors {
+			unitVectors[i] = vector.NewVector3(
+				(r.Float64()*2.0)-1,
+				(r.Float64()*2.0)-1,
+				(r.Float64()*2.0)-1,
+			).Normalized()
+		}
+
+		set, err := runDataset(cfg, unitVectors, randomDatasetName(cfg.Vectors), unitWriters)
+		if err != nil {
+			return err
+		}
+		if err := write(set); err != nil {
+			return err
+		}
+	}
+
+	availableFiles, err := getDatasetPathsFromDir(cfg.Data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "skipping models: %s\n", err)
 	}
 
 	for _, f := range availableFiles {
-		model, err := loadModel(filepath.Join(pathToLoadFrom, f))
+		loaded, err := meshio.ReadFile(filepath.Join(cfg.Data, f))
 		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s\n", err)
 			continue
 		}
 
@@ -543,32 +571,78 @@ func main() {
 		extension := filepath.Ext(datasetName)
 		datasetName = datasetName[0 : len(datasetName)-len(extension)]
 
-		flatNormals := calcFlatNormals(model)
-		flatName := fmt.Sprintf("%s flat", datasetName)
-		flatOut, _ := os.Create(flatName + ".obj")
-		writeObj(model, flatNormals, flatOut)
-		flatSet := runDataset(flatNormals, flatName, unitWriters)
-		if writeCSV {
-			_, err = flatSet.WriteCSV(os.Stdout)
-		} else {
-			_, err = flatSet.Write(os.Stdout)
+		// Point clouds have no faces to calculate normals from
+		if len(loaded.Triangles) > 0 {
+			model := loaded.Mango()
+
+			flatSet, err := runModelDataset(cfg, model, calcFlatNormals(model), fmt.Sprintf("%s flat", datasetName), unitWriters)
+			if err != nil {
+				return err
+			}
+			if err := write(flatSet); err != nil {
+				return err
+			}
+
+			smoothSet, err := runModelDataset(cfg, model, calcSmoothNormals(model), fmt.Sprintf("%s smooth", datasetName), unitWriters)
+			if err != nil {
+				return err
+			}
+			if err := write(smoothSet); err != nil {
+				return err
+			}
 		}
-		if err != nil {
-			panic(err)
+
+		if normals := authoredNormals(loaded); len(normals) > 0 {
+			set, err := runDataset(cfg, normals, fmt.Sprintf("%s authored", datasetName), unitWriters)
+			if err != nil {
+				return err
+			}
+			if err := write(set); err != nil {
+				return err
+			}
 		}
+	}
 
-		smoothNormals := calcSmoothNormals(model)
-		smoothName := fmt.Sprintf("%s smooth", datasetName)
-		smoothOut, _ := os.Create(smoothName + ".obj")
-		writeObj(model, smoothNormals, smoothOut)
-		smoothSet := runDataset(smoothNormals, smoothName, unitWriters)
-		if writeCSV {
-			_, err = smoothSet.WriteCSV(os.Stdout)
-		} else {
-			_, err = smoothSet.Write(os.Stdout)
+	// The compression and outlier reports go to stderr alongside CSV so stdout stays one table
+	report := out
+	if writeCSV {
+		report = os.Stderr
+	}
+	if err := writeCompression(report, written, writeCSV); err != nil {
+		return err
+	}
+	total, err := writeOutliers(report, written, cfg.MaxError, writeCSV)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Charts != "" {
+		if err := writeCharts(cfg.Charts, written); err != nil {
+			return err
 		}
-		if err != nil {
-			panic(err)
+	}
+	if cfg.Strict && total > 0 {
+		return fmt.Errorf("%d vectors decoded with a component off by more than %g", total, cfg.MaxError)
+	}
+	return nil
+}
+
+func main() {
+	cfg, dump, err := parseConfig(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if dump {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg); err != nil {
+			log.Fatal(err)
 		}
+		return
+	}
+
+	if err := run(cfg, os.Stdout); err != nil {
+		log.Fatal(err)
 	}
 }
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// outlier is a vector that decoded with a component further from the
+// original than the benchmark allows.
+type outlier struct {
+	index    int
+	original vector.Vector3
+	code     []byte
+	decoded  vector.Vector3
+	degrees  float64
+}
+
+// outliers counts the vectors a method decodes with a component off by more
+// than maxError, keeping the worst of them by angular error.
+type outliers struct {
+	maxError float64
+	keep     int
+	count    int
+	worst    []outlier
+}
+
+func newOutliers(maxError float64, keep int) *outliers {
+	return &outliers{maxError: maxError, keep: keep, worst: make([]outlier, 0, keep)}
+}
+
+func componentError(original, decoded vector.Vector3) float64 {
+	worst := math.Max(math.Abs(original.X()-decoded.X()), math.Abs(original.Y()-decoded.Y()))
+	worst = math.Max(worst, math.Abs(original.Z()-decoded.Z()))
+	if math.IsNaN(worst) {
+		return math.Inf(1)
+	}
+	return worst
+}
+
+// check records the vector if it decoded too far from the original.
+func (o *outliers) check(index int, original, decoded vector.Vector3, code []byte) {
+	if componentError(original, decoded) <= o.maxError {
+		return
+	}
+	o.count++
+
+	// Vectors without a direction sort as the worst of all
+	degrees := unitpacking.AngularError(original, decoded) * 180 / math.Pi
+	if math.IsNaN(degrees) {
+		degrees = math.Inf(1)
+	}
+
+	// Keep the worst sorted first, inserting the new one in place
+	position := len(o.worst)
+	for position > 0 && o.worst[position-1].degrees < degrees {
+		position--
+	}
+	if position >= o.keep {
+		return
+	}
+	if len(o.worst) < o.keep {
+		o.worst = append(o.worst, outlier{})
+	}
+	copy(o.worst[position+1:], o.worst[position:])
+	o.worst[position] = outlier{
+		index:    index,
+		original: original,
+		code:     append([]byte(nil), code...),
+		decoded:  decoded,
+		degrees:  degrees,
+	}
+}
+
+func formatVector(v vector.Vector3) string {
+	return fmt.Sprintf("(%.6f, %.6f, %.6f)", v.X(), v.Y(), v.Z())
+}
+
+// writeOutliers lists the worst outliers of every method across the
+// datasets, returning how many outliers there were in total.
+func writeOutliers(out io.Writer, datasets []dataset, maxError float64, csv bool) (int, error) {
+	total, runs := 0, 0
+	for _, ds := range datasets {
+		for _, e := range ds.entries {
+			if e.outliers != nil && e.outliers.count > 0 {
+				total += e.outliers.count
+				runs++
+			}
+		}
+	}
+
+	if csv {
+		if _, err := fmt.Fprintln(out, "\"dataset\", \"method\", \"outliers\", \"index\", \"original\", \"code\", \"decoded\", \"angular error\""); err != nil {
+			return total, err
+		}
+	} else {
+		if total == 0 {
+			_, err := fmt.Fprintf(out, "\n### Outliers\n\nNo vector decoded with a component off by more than %g.\n", maxError)
+			return total, err
+		}
+
+		_, err := fmt.Fprintf(
+			out,
+			"\n### Outliers\n\n%d vectors across %d runs decoded with a component off by more than %g. The worst of each run:\n\n| Dataset | Method | Outliers | Index | Original | Code | Decoded | Angular Error |\n|-|-|-|-|-|-|-|-|\n",
+			total, runs, maxError,
+		)
+		if err != nil {
+			return total, err
+		}
+	}
+
+	for _, ds := range datasets {
+		for _, e := range ds.entries {
+			if e.outliers == nil {
+				continue
+			}
+			for _, o := range e.outliers.worst {
+				columns := []string{
+					ds.set,
+					e.method,
+					fmt.Sprintf("%d", e.outliers.count),
+					fmt.Sprintf("%d", o.index),
+					formatVector(o.original),
+					fmt.Sprintf("%x", o.code),
+					formatVector(o.decoded),
+					fmt.Sprintf("%.6f", o.degrees),
+				}
+				if math.IsInf(o.degrees, 1) {
+					columns[7] = "NaN"
+				} else if !csv {
+					columns[7] += "°"
+				}
+
+				var err error
+				if csv {
+					for _, i := range []int{0, 1, 4, 5, 6} {
+						columns[i] = fmt.Sprintf("%q", columns[i])
+					}
+					_, err = fmt.Fprintln(out, strings.Join(columns, ", "))
+				} else {
+					_, err = fmt.Fprintf(out, "| %s |\n", strings.Join(columns, " | "))
+				}
+				if err != nil {
+					return total, err
+				}
+			}
+		}
+	}
+	return total, nil
+}
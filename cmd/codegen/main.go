@@ -0,0 +1,103 @@
+// Command codegen writes unitpacking decoders for shaders, and full packing
+// implementations for other languages.
+//
+//	codegen -lang glsl -codecs oct24,octquad16 -out unitpacking.glsl
+//	codegen -lang csharp -namespace Recolude -out UnitPacking.cs -tests UnitPackingTests.cs
+//	codegen -lang c -out unitpacking.h
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+var languages = []string{"glsl", "hlsl", "csharp", "c"}
+
+func parseCodecs(names string) ([]unitpacking.Codec, error) {
+	if names == "" {
+		return unitpacking.Codecs(), nil
+	}
+
+	codecs := make([]unitpacking.Codec, 0)
+	for _, name := range strings.Split(names, ",") {
+		codec, ok := unitpacking.CodecByName(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown codec %q", name)
+		}
+		codecs = append(codecs, codec)
+	}
+	return codecs, nil
+}
+
+// writeFile writes to stdout when no path is given.
+func writeFile(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func main() {
+	langFlag := flag.String("lang", "glsl", "language to generate, one of "+strings.Join(languages, ", "))
+	codecsFlag := flag.String("codecs", "", "comma separated codecs to generate, defaults to all of them")
+	outFlag := flag.String("out", "", "file to write to, defaults to stdout")
+	namespaceFlag := flag.String("namespace", "Recolude", "namespace of the generated C# classes")
+	testsFlag := flag.String("tests", "", "file to write C# tests to, skipped when empty")
+	vectorsFlag := flag.String("vectors", "vectors.txt", "path the C# tests load unitpacking/testdata/vectors.txt from")
+	flag.Parse()
+
+	codecs, err := parseCodecs(*codecsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch strings.ToLower(*langFlag) {
+	case "glsl":
+		err = writeFile(*outFlag, func(w io.Writer) error {
+			return unitpacking.WriteShaderDecoder(w, unitpacking.GLSL, codecs...)
+		})
+
+	case "hlsl":
+		err = writeFile(*outFlag, func(w io.Writer) error {
+			return unitpacking.WriteShaderDecoder(w, unitpacking.HLSL, codecs...)
+		})
+
+	case "csharp", "cs":
+		err = writeFile(*outFlag, func(w io.Writer) error {
+			return unitpacking.WriteCSharp(w, *namespaceFlag, codecs...)
+		})
+		if err == nil && *testsFlag != "" {
+			err = writeFile(*testsFlag, func(w io.Writer) error {
+				return unitpacking.WriteCSharpTests(w, *namespaceFlag, *vectorsFlag, codecs...)
+			})
+		}
+
+	case "c":
+		err = writeFile(*outFlag, func(w io.Writer) error {
+			return unitpacking.WriteCHeader(w, codecs...)
+		})
+
+	default:
+		err = fmt.Errorf("unknown language %q, expected one of %s", *langFlag, strings.Join(languages, ", "))
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
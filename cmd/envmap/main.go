@@ -0,0 +1,204 @@
+// Command envmap resamples equirectangular (lat-long) environment maps into
+// octahedral ones, and back again.
+//
+//	envmap oct -size 512 -padding 2 sky.png sky_oct.png
+//	envmap equirect -padding 2 -reference sky.png sky_oct.png sky_back.png
+//
+// Converting to octahedral also converts the result back to
+// equirectangular and reports how far it drifted from the original.
+// Converting back reports the same when given the original as a reference.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"math"
+	"os"
+
+	"github.com/recolude/unitpacking/internal/imageutil"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	fmt.Fprintf(os.Stderr, "  envmap oct [flags] equirect.png oct.png\n")
+	fmt.Fprintf(os.Stderr, "  envmap equirect [flags] oct.png equirect.png\n\n")
+	fmt.Fprintf(os.Stderr, "run envmap oct -h or envmap equirect -h to list flags\n")
+}
+
+// writePNG saves the image with 16 bits per channel, or converts it down to 8
+// bits first.
+func writePNG(path string, img *image.RGBA64, sixteenBit bool) error {
+	var out image.Image = img
+	if !sixteenBit {
+		nrgba := image.NewNRGBA(img.Bounds())
+		draw.Draw(nrgba, nrgba.Bounds(), img, img.Bounds().Min, draw.Src)
+		out = nrgba
+	}
+
+	return imageutil.WritePNG(path, out)
+}
+
+// report prints how far the round tripped image's color channels are from
+// the original's, scaled so 1 is full intensity.
+func report(original, roundTripped image.Image) error {
+	bounds := original.Bounds()
+	if bounds.Size() != roundTripped.Bounds().Size() {
+		return fmt.Errorf(
+			"reference is %dx%d, but the result is %dx%d",
+			bounds.Dx(), bounds.Dy(), roundTripped.Bounds().Dx(), roundTripped.Bounds().Dy(),
+		)
+	}
+
+	offset := roundTripped.Bounds().Min.Sub(bounds.Min)
+	sumSquared := 0.0
+	maxErr := 0.0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, _ := original.At(x, y).RGBA()
+			r2, g2, b2, _ := roundTripped.At(x+offset.X, y+offset.Y).RGBA()
+			for _, diff := range []float64{
+				float64(r1) - float64(r2),
+				float64(g1) - float64(g2),
+				float64(b1) - float64(b2),
+			} {
+				diff = math.Abs(diff) / 0xFFFF
+				sumSquared += diff * diff
+				maxErr = math.Max(maxErr, diff)
+			}
+		}
+	}
+
+	rms := math.Sqrt(sumSquared / float64(bounds.Dx()*bounds.Dy()*3))
+	fmt.Printf("pixels  %d\n", bounds.Dx()*bounds.Dy())
+	fmt.Printf("rms     %.6f (%.3f of 255)\n", rms, rms*255)
+	fmt.Printf("max     %.6f (%.3f of 255)\n", maxErr, maxErr*255)
+	if rms > 0 {
+		fmt.Printf("psnr    %.2f dB\n", 20*math.Log10(1/rms))
+	} else {
+		fmt.Printf("psnr    inf\n")
+	}
+	return nil
+}
+
+func toOct(args []string) error {
+	set := flag.NewFlagSet("oct", flag.ExitOnError)
+	size := set.Int("size", 0, "width and height of the octahedral image without padding, defaults to keeping roughly the same number of texels as the input")
+	padding := set.Int("padding", 1, "texels of border on each side, filled from across the octahedron's edges")
+	sixteenBit := set.Bool("16", false, "write 16 bits per channel")
+	roundTrip := set.String("roundtrip", "", "also write the image converted back to equirectangular to this path")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if set.NArg() != 2 {
+		return fmt.Errorf("expected an input and an output path, got %d arguments", set.NArg())
+	}
+
+	src, err := imageutil.ReadPNG(set.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	bounds := src.Bounds()
+	if *size == 0 {
+		*size = int(math.Round(math.Sqrt(float64(bounds.Dx() * bounds.Dy()))))
+	}
+
+	oct, err := unitpacking.EquirectToOct(src, *size, *padding)
+	if err != nil {
+		return err
+	}
+	if err := writePNG(set.Arg(1), oct, *sixteenBit); err != nil {
+		return err
+	}
+
+	back, err := unitpacking.OctToEquirect(oct, *padding, bounds.Dx(), bounds.Dy())
+	if err != nil {
+		return err
+	}
+	if *roundTrip != "" {
+		if err := writePNG(*roundTrip, back, *sixteenBit); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("%dx%d equirectangular to %dx%d octahedral (%d texels of padding)\n", bounds.Dx(), bounds.Dy(), *size, *size, *padding)
+	return report(src, back)
+}
+
+func toEquirect(args []string) error {
+	set := flag.NewFlagSet("equirect", flag.ExitOnError)
+	padding := set.Int("padding", 1, "texels of border on each side of the octahedral image")
+	width := set.Int("width", 0, "width of the equirectangular image, defaults to twice the height")
+	height := set.Int("height", 0, "height of the equirectangular image, defaults to the octahedral image's size or half the width")
+	sixteenBit := set.Bool("16", false, "write 16 bits per channel")
+	reference := set.String("reference", "", "original equirectangular image to report the error against")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+	if set.NArg() != 2 {
+		return fmt.Errorf("expected an input and an output path, got %d arguments", set.NArg())
+	}
+
+	src, err := imageutil.ReadPNG(set.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var original image.Image
+	if *reference != "" {
+		if original, err = imageutil.ReadPNG(*reference); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case *width == 0 && *height == 0 && original != nil:
+		*width, *height = original.Bounds().Dx(), original.Bounds().Dy()
+	case *width == 0 && *height == 0:
+		*height = src.Bounds().Dx() - (2 * *padding)
+		*width = *height * 2
+	case *width == 0:
+		*width = *height * 2
+	case *height == 0:
+		*height = *width / 2
+	}
+
+	equirect, err := unitpacking.OctToEquirect(src, *padding, *width, *height)
+	if err != nil {
+		return err
+	}
+	if err := writePNG(set.Arg(1), equirect, *sixteenBit); err != nil {
+		return err
+	}
+
+	if original == nil {
+		return nil
+	}
+	return report(original, equirect)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "oct":
+		err = toOct(os.Args[2:])
+	case "equirect":
+		err = toEquirect(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,281 @@
+// Command errormap samples the whole sphere, packs and unpacks every sample
+// with each codec, and renders the angular error as heatmaps in octahedral,
+// equirectangular and globe projections.
+//
+//	errormap -codecs alg24,oct16 -size 512 -out maps
+//	errormap -codecs oct16 -projections oct -region 0.9,0.4,1,0.5 -lattice -out maps
+//
+// Each image is written to <codec>_<projection>.png. Colors ramp from black
+// through red and yellow to white at -max degrees. With -lattice, the
+// vectors the samples decoded to are drawn as cyan points, showing the shape
+// of each code's cell. -region zooms in on part of each projection, which
+// is where the lattice of a finer codec becomes visible.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/internal/imageutil"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+var latticeColor = color.NRGBA{R: 0, G: 0xFF, B: 0xFF, A: 0xFF}
+
+// region is the part of a projection an image covers, in the projection's
+// image coordinates.
+type region struct {
+	u0, v0, u1, v1 float64
+}
+
+func parseRegion(s string) (region, error) {
+	fields := parseList(s)
+	if len(fields) != 4 {
+		return region{}, fmt.Errorf("region needs 4 comma separated values, got %q", s)
+	}
+	values := make([]float64, 4)
+	for i, field := range fields {
+		value, err := strconv.ParseFloat(field, 64)
+		if err != nil || value < 0 || value > 1 {
+			return region{}, fmt.Errorf("region values must be between 0 and 1, got %q", field)
+		}
+		values[i] = value
+	}
+	r := region{values[0], values[1], values[2], values[3]}
+	if r.u1 <= r.u0 || r.v1 <= r.v0 {
+		return region{}, fmt.Errorf("region %q is empty", s)
+	}
+	return r, nil
+}
+
+// errorMap is the worst error found in each pixel of a projection, in
+// degrees, and every code the samples packed to.
+type errorMap struct {
+	view    region
+	width   int
+	height  int
+	errs    []float64
+	covered []bool
+	stats   unitpacking.ErrorStats
+	lattice map[string]vector.Vector3
+}
+
+// sampleRow packs and unpacks samples×samples directions spread evenly over
+// each pixel of a row.
+func sampleRow(codec unitpacking.Codec, proj projection, m *errorMap, y, samples int) ([]float64, map[string]vector.Vector3) {
+	rowErrs := make([]float64, 0, m.width*samples*samples)
+	codes := make(map[string]vector.Vector3)
+	for x := 0; x < m.width; x++ {
+		for sy := 0; sy < samples; sy++ {
+			for sx := 0; sx < samples; sx++ {
+				u := (float64(x) + (float64(sx)+0.5)/float64(samples)) / float64(m.width)
+				v := (float64(y) + (float64(sy)+0.5)/float64(samples)) / float64(m.height)
+				dir, ok := proj.direction(m.view.u0+u*(m.view.u1-m.view.u0), m.view.v0+v*(m.view.v1-m.view.v0))
+				if !ok {
+					continue
+				}
+
+				code := codec.Pack(dir)
+				decoded := codec.Unpack(code)
+				degrees := unitpacking.AngularError(dir, decoded) * 180 / math.Pi
+				if math.IsNaN(degrees) {
+					degrees = 180
+				}
+
+				i := x + y*m.width
+				m.errs[i] = math.Max(m.errs[i], degrees)
+				m.covered[i] = true
+				rowErrs = append(rowErrs, degrees)
+				codes[string(code)] = decoded
+			}
+		}
+	}
+	return rowErrs, codes
+}
+
+func sampleProjection(codec unitpacking.Codec, proj projection, view region, size, samples int) *errorMap {
+	// Keep the region's proportions, so zooming in doesn't stretch it
+	width := int(math.Max(math.Round(float64(size*proj.aspect)*(view.u1-view.u0)/(view.v1-view.v0)), 1))
+	m := &errorMap{
+		view:    view,
+		width:   width,
+		height:  size,
+		errs:    make([]float64, width*size),
+		covered: make([]bool, width*size),
+		lattice: make(map[string]vector.Vector3),
+	}
+
+	// Rows only write to their own pixels, so they can be sampled in
+	// parallel. Their results are merged in order afterwards.
+	rowErrs := make([][]float64, m.height)
+	rowCodes := make([]map[string]vector.Vector3, m.height)
+	rows := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := range rows {
+				rowErrs[y], rowCodes[y] = sampleRow(codec, proj, m, y, samples)
+			}
+		}()
+	}
+	for y := 0; y < m.height; y++ {
+		rows <- y
+	}
+	close(rows)
+	wg.Wait()
+
+	for y := range rowErrs {
+		for _, e := range rowErrs[y] {
+			m.stats.AddDegrees(e)
+		}
+		for code, decoded := range rowCodes[y] {
+			m.lattice[code] = decoded
+		}
+	}
+	return m
+}
+
+// render colors each pixel by its error, scaled so maxDegrees is white.
+// Pixels off the sphere are left transparent.
+func (m *errorMap) render(maxDegrees float64, lattice bool, proj projection) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, m.width, m.height))
+	for y := 0; y < m.height; y++ {
+		for x := 0; x < m.width; x++ {
+			i := x + y*m.width
+			if !m.covered[i] {
+				continue
+			}
+			t := 0.0
+			if maxDegrees > 0 {
+				t = m.errs[i] / maxDegrees
+			}
+			img.SetNRGBA(x, y, imageutil.HeatColor(t))
+		}
+	}
+
+	if lattice {
+		for _, decoded := range m.lattice {
+			u, v := proj.uv(decoded)
+			x := int(math.Floor((u - m.view.u0) / (m.view.u1 - m.view.u0) * float64(m.width)))
+			y := int(math.Floor((v - m.view.v0) / (m.view.v1 - m.view.v0) * float64(m.height)))
+			if u == m.view.u1 {
+				x = m.width - 1
+			}
+			if v == m.view.v1 {
+				y = m.height - 1
+			}
+
+			// Points outside the image are left off, SetNRGBA ignores them
+			img.SetNRGBA(x, y, latticeColor)
+		}
+	}
+	return img
+}
+
+func parseList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func run() error {
+	codecsFlag := flag.String("codecs", "", "comma separated codecs to map, defaults to all of them")
+	projectionsFlag := flag.String("projections", "oct,equirect,globe", "comma separated projections to render, from oct, equirect and globe")
+	size := flag.Int("size", 512, "height of each image in pixels, equirect and globe images are twice as wide as the region they cover is tall")
+	samples := flag.Int("samples", 2, "samples per pixel along each axis, each pixel shows the worst of them")
+	maxDegrees := flag.Float64("max", 0, "error in degrees drawn as white, defaults to the largest error in each image")
+	lattice := flag.Bool("lattice", false, "draw the vectors samples decode to as points")
+	regionFlag := flag.String("region", "0,0,1,1", "part of each projection to render as u0,v0,u1,v1, from 0,0 in the top left to 1,1 in the bottom right")
+	out := flag.String("out", ".", "directory to write images to")
+	flag.Parse()
+
+	if *size < 1 || *samples < 1 {
+		return fmt.Errorf("size and samples must be positive, got %d and %d", *size, *samples)
+	}
+
+	view, err := parseRegion(*regionFlag)
+	if err != nil {
+		return err
+	}
+
+	codecs := unitpacking.Codecs()
+	if names := parseList(*codecsFlag); len(names) > 0 {
+		codecs = make([]unitpacking.Codec, 0, len(names))
+		for _, name := range names {
+			codec, ok := unitpacking.CodecByName(name)
+			if !ok {
+				return fmt.Errorf("unknown codec %q", name)
+			}
+			codecs = append(codecs, codec)
+		}
+	}
+
+	selected := make([]projection, 0)
+	for _, name := range parseList(*projectionsFlag) {
+		proj, err := projectionByName(name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, proj)
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+
+	fmt.Printf("%-10s %-9s %10s %10s %10s %10s %8s\n", "codec", "view", "mean", "p99", "max", "white", "codes")
+	for _, codec := range codecs {
+		for _, proj := range selected {
+			m := sampleProjection(codec, proj, view, *size, *samples)
+			summary := m.stats.Summary()
+
+			scale := *maxDegrees
+			if scale <= 0 {
+				scale = summary.Max
+			}
+
+			path := filepath.Join(*out, fmt.Sprintf("%s_%s.png", codec.Name(), proj.name))
+			if err := imageutil.WritePNG(path, m.render(scale, *lattice, proj)); err != nil {
+				return err
+			}
+
+			fmt.Printf(
+				"%-10s %-9s %9.5f° %9.5f° %9.5f° %9.5f° %8d\n",
+				codec.Name(), proj.name, summary.Mean, summary.P99, summary.Max, scale, len(m.lattice),
+			)
+		}
+	}
+	return nil
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: errormap [flags]\n\nprojections:\n")
+		for _, p := range projections {
+			fmt.Fprintf(os.Stderr, "  %-9s %s\n", p.name, p.description)
+		}
+		fmt.Fprintf(os.Stderr, "\nflags:\n")
+		flag.PrintDefaults()
+	}
+
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// projection lays the sphere out flat. Image coordinates u and v run from 0
+// to 1 across the whole image, left to right and top to bottom.
+type projection struct {
+	name        string
+	description string
+
+	// aspect is the image's width over its height
+	aspect int
+
+	// direction returns the direction at a point of the image, or false when
+	// the point is off the sphere
+	direction func(u, v float64) (vector.Vector3, bool)
+
+	// uv returns where a direction lands in the image
+	uv func(dir vector.Vector3) (u, v float64)
+}
+
+var projections = []projection{
+	{
+		name:        "oct",
+		description: "octahedral square, +Z in the center and -Z in the corners",
+		aspect:      1,
+		direction: func(u, v float64) (vector.Vector3, bool) {
+			return unitpacking.FromOctUV(vector.NewVector2(u*2-1, v*2-1)), true
+		},
+		uv: func(dir vector.Vector3) (float64, float64) {
+			uv := unitpacking.MapToOctUV(dir.Normalized())
+			return (uv.X() + 1) / 2, (uv.Y() + 1) / 2
+		},
+	},
+	{
+		name:        "equirect",
+		description: "longitude and latitude, +X in the center and +Z along the top",
+		aspect:      2,
+		direction: func(u, v float64) (vector.Vector3, bool) {
+			return unitpacking.EquirectDirection(u, v), true
+		},
+		uv: unitpacking.EquirectUV,
+	},
+	{
+		name:        "globe",
+		description: "orthographic globes facing +X on the left and -X on the right, +Z up",
+		aspect:      2,
+		direction:   globeDirection,
+		uv:          globeUV,
+	},
+}
+
+func projectionByName(name string) (projection, error) {
+	names := make([]string, len(projections))
+	for i, p := range projections {
+		if p.name == name {
+			return p, nil
+		}
+		names[i] = p.name
+	}
+	return projection{}, fmt.Errorf("unknown projection %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// globeDirection views the hemisphere facing +X from +X on the left half of
+// the image, and the one facing -X from -X on the right half. Both have +Z
+// up.
+func globeDirection(u, v float64) (vector.Vector3, bool) {
+	back := u >= 0.5
+	if back {
+		u -= 0.5
+	}
+
+	x := u*4 - 1
+	y := v*2 - 1
+	r2 := x*x + y*y
+	if r2 > 1 {
+		return vector.Vector3{}, false
+	}
+
+	depth := math.Sqrt(1 - r2)
+	if back {
+		return vector.NewVector3(-depth, -x, -y), true
+	}
+	return vector.NewVector3(depth, x, -y), true
+}
+
+func globeUV(dir vector.Vector3) (float64, float64) {
+	dir = dir.Normalized()
+	v := (1 - dir.Z()) / 2
+	if dir.X() < 0 {
+		return 0.5 + (1-dir.Y())/4, v
+	}
+	return (dir.Y() + 1) / 4, v
+}
@@ -3,7 +3,6 @@ package main
 import (
 	"compress/flate"
 	"image"
-	"image/color"
 	"image/png"
 	"math/rand"
 	"os"
@@ -19,10 +18,6 @@ func main() {
 	numOfVectors := width * height
 	unitVectors := make([]vector.Vector3, numOfVectors)
 
-	upLeft := image.Point{0, 0}
-	lowRight := image.Point{width, height}
-	img := image.NewRGBA(image.Rectangle{upLeft, lowRight})
-
 	// Generate a bunch of unit vectors
 	for i := 0; i < numOfVectors; i++ {
 		unitVectors[i] = vector.NewVector3(
@@ -43,23 +38,39 @@ func main() {
 	}
 
 	// Write out unit vectors in packed format
+	encoder := unitpacking.NewEncoder(comressedWriter, unitpacking.Oct24)
+	if err := encoder.EncodeSlice(unitVectors); err != nil {
+		panic(err)
+	}
+
+	if err := encoder.Flush(); err != nil {
+		panic(err)
+	}
+
+	if err := comressedWriter.Close(); err != nil {
+		panic(err)
+	}
+
+	// Store the same packed vectors as pixels of an image
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, width, height), unitpacking.Oct24)
+	if err != nil {
+		panic(err)
+	}
+
 	for x := 0; x < width; x++ {
 		for y := 0; y < height; y++ {
-			data := unitpacking.PackOct24(unitVectors[y+(x*width)])
-			comressedWriter.Write(data)
-			img.Set(x, y, color.RGBA{
-				R: data[0],
-				G: data[1],
-				B: data[2],
-				A: 255,
-			})
+			img.SetNormal(x, y, unitVectors[y+(x*width)])
 		}
 	}
 
-	comressedWriter.Flush()
-
-	// Encode as PNG.
-	f, _ := os.Create("image.png")
-	png.Encode(f, img)
+	// Encode the packed codes as a PNG.
+	f, err := os.Create("image.png")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
 
+	if err := png.Encode(f, img.CodeImage()); err != nil {
+		panic(err)
+	}
 }
@@ -0,0 +1,164 @@
+// Command normalmap converts standard RGB tangent space normal maps into
+// octahedral or hemi-octahedral textures, and back again.
+//
+//	normalmap encode -mapping hemioct -format rg8 -heatmap error.png normal.png packed.png
+//	normalmap decode -mapping hemioct -format rg8 -reference normal.png packed.png normal_decoded.png
+//
+// Encoding reports the angular error of every pixel once decoded. Decoding
+// reports it when given the original normal map as a reference.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/recolude/unitpacking/internal/imageutil"
+)
+
+type options struct {
+	mapping    string
+	format     textureFormat
+	heatmap    string
+	heatmapMax float64
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	fmt.Fprintf(os.Stderr, "  normalmap encode [flags] normal.png packed.png\n")
+	fmt.Fprintf(os.Stderr, "  normalmap decode [flags] packed.png normal.png\n\n")
+	fmt.Fprintf(os.Stderr, "formats:\n")
+	for _, f := range textureFormats {
+		fmt.Fprintf(os.Stderr, "  %-5s %s (%s / %s)\n", f.name, f.description, f.octCodec.Name(), f.hemiCodec.Name())
+	}
+	fmt.Fprintf(os.Stderr, "\nrun normalmap encode -h or normalmap decode -h to list flags\n")
+}
+
+func parseFlags(set *flag.FlagSet, args []string) (options, []string, error) {
+	mapping := set.String("mapping", "oct", "oct for the full sphere, or hemioct for normals that never point into the surface")
+	format := set.String("format", "rg8", "texture layout, one of rg8, rgb8 or rg16")
+	heatmap := set.String("heatmap", "", "write an image of the angular error of each pixel to this path")
+	heatmapMax := set.Float64("heatmap-max", 0, "error in degrees drawn as white in the heatmap, defaults to the largest error")
+	if err := set.Parse(args); err != nil {
+		return options{}, nil, err
+	}
+
+	f, err := formatByName(strings.ToLower(*format))
+	if err != nil {
+		return options{}, nil, err
+	}
+
+	if _, err := f.codec(*mapping); err != nil {
+		return options{}, nil, err
+	}
+
+	if set.NArg() != 2 {
+		return options{}, nil, fmt.Errorf("expected an input and an output path, got %d arguments", set.NArg())
+	}
+
+	return options{mapping: *mapping, format: f, heatmap: *heatmap, heatmapMax: *heatmapMax}, set.Args(), nil
+}
+
+func report(opts options, original, decoded normalGrid) error {
+	errs, err := angularErrors(original, decoded)
+	if err != nil {
+		return err
+	}
+
+	codec, _ := opts.format.codec(opts.mapping)
+	fmt.Printf("%s as %s (%s)\n", opts.mapping, opts.format.name, codec.Name())
+	writeReport(os.Stdout, errs)
+
+	if opts.heatmap == "" {
+		return nil
+	}
+	return imageutil.WritePNG(opts.heatmap, heatmap(errs, decoded.width, decoded.height, opts.heatmapMax))
+}
+
+func encode(args []string) error {
+	set := flag.NewFlagSet("encode", flag.ExitOnError)
+	opts, paths, err := parseFlags(set, args)
+	if err != nil {
+		return err
+	}
+	codec, _ := opts.format.codec(opts.mapping)
+
+	img, err := imageutil.ReadPNG(paths[0])
+	if err != nil {
+		return err
+	}
+	normals := readNormalMap(img)
+
+	texture := encodeTexture(normals, opts.format, codec)
+	if err := imageutil.WritePNG(paths[1], texture); err != nil {
+		return err
+	}
+
+	decoded, err := decodeTexture(texture, opts.format, codec)
+	if err != nil {
+		return err
+	}
+	return report(opts, normals, decoded)
+}
+
+func decode(args []string) error {
+	set := flag.NewFlagSet("decode", flag.ExitOnError)
+	reference := set.String("reference", "", "original normal map to report the angular error against")
+	sixteenBit := set.Bool("16", false, "write a 16 bit per channel normal map")
+	opts, paths, err := parseFlags(set, args)
+	if err != nil {
+		return err
+	}
+	codec, _ := opts.format.codec(opts.mapping)
+
+	img, err := imageutil.ReadPNG(paths[0])
+	if err != nil {
+		return err
+	}
+
+	decoded, err := decodeTexture(img, opts.format, codec)
+	if err != nil {
+		return err
+	}
+
+	if err := imageutil.WritePNG(paths[1], writeNormalMap(decoded, *sixteenBit)); err != nil {
+		return err
+	}
+
+	if *reference == "" {
+		if opts.heatmap != "" {
+			return fmt.Errorf("a heatmap requires a -reference normal map")
+		}
+		return nil
+	}
+
+	original, err := imageutil.ReadPNG(*reference)
+	if err != nil {
+		return err
+	}
+	return report(opts, readNormalMap(original), decoded)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "encode":
+		err = encode(os.Args[2:])
+	case "decode":
+		err = decode(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
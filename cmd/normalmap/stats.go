@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+
+	"github.com/recolude/unitpacking/internal/imageutil"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// angularErrors measures, in degrees, how far each decoded normal is from
+// the original one.
+func angularErrors(original, decoded normalGrid) ([]float64, error) {
+	if original.width != decoded.width || original.height != decoded.height {
+		return nil, fmt.Errorf(
+			"reference is %dx%d, but the texture is %dx%d",
+			original.width, original.height, decoded.width, decoded.height,
+		)
+	}
+
+	errs := make([]float64, len(original.normals))
+	for i := range errs {
+		errs[i] = unitpacking.AngularError(original.normals[i], decoded.normals[i]) * 180 / math.Pi
+	}
+	return errs, nil
+}
+
+func writeReport(w io.Writer, errs []float64) {
+	stats := unitpacking.ErrorStats{}
+	for _, e := range errs {
+		stats.AddDegrees(e)
+	}
+	summary := stats.Summary()
+
+	fmt.Fprintf(w, "pixels  %d\n", summary.Count)
+	fmt.Fprintf(w, "mean    %.6f°\n", summary.Mean)
+	fmt.Fprintf(w, "rms     %.6f°\n", summary.RMS)
+	fmt.Fprintf(w, "p50     %.6f°\n", summary.P50)
+	fmt.Fprintf(w, "p95     %.6f°\n", summary.P95)
+	fmt.Fprintf(w, "p99     %.6f°\n", summary.P99)
+	fmt.Fprintf(w, "p99.9   %.6f°\n", summary.P999)
+	fmt.Fprintf(w, "max     %.6f°\n", summary.Max)
+}
+
+// heatmap colors each pixel by its error. Errors at or above maxDegrees are
+// white. When maxDegrees isn't positive, the largest error is used.
+func heatmap(errs []float64, width, height int, maxDegrees float64) image.Image {
+	if maxDegrees <= 0 {
+		for _, e := range errs {
+			maxDegrees = math.Max(maxDegrees, e)
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := 0.0
+			if maxDegrees > 0 {
+				t = errs[x+(y*width)] / maxDegrees
+			}
+			img.SetNRGBA(x, y, imageutil.HeatColor(t))
+		}
+	}
+	return img
+}
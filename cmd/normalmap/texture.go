@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// textureFormat is a layout for storing packed normals in the channels of a
+// PNG. Channels hold the code's bytes in little endian order, so a shader can
+// rebuild the word with r | g << 8 | b << 16 and hand oct codes to the
+// decoders from cmd/codegen.
+type textureFormat struct {
+	name        string
+	sixteenBit  bool
+	octCodec    unitpacking.Codec
+	hemiCodec   unitpacking.Codec
+	description string
+}
+
+var textureFormats = []textureFormat{
+	{"rg8", false, unitpacking.Oct16, unitpacking.HemiOct16, "8 bits per coordinate in R and G"},
+	{"rgb8", false, unitpacking.Oct24, unitpacking.HemiOct24, "12 bits per coordinate across R, G and B"},
+	{"rg16", true, unitpacking.Oct32, unitpacking.HemiOct32, "16 bits per coordinate in R and G of a 16 bit PNG"},
+}
+
+func formatByName(name string) (textureFormat, error) {
+	for _, f := range textureFormats {
+		if f.name == name {
+			return f, nil
+		}
+	}
+	return textureFormat{}, fmt.Errorf("unknown format %q, expected rg8, rgb8 or rg16", name)
+}
+
+func (f textureFormat) codec(mapping string) (unitpacking.Codec, error) {
+	switch mapping {
+	case "oct":
+		return f.octCodec, nil
+	case "hemioct":
+		return f.hemiCodec, nil
+	}
+	return nil, fmt.Errorf("unknown mapping %q, expected oct or hemioct", mapping)
+}
+
+// normalGrid holds one normal per pixel, row by row.
+type normalGrid struct {
+	width   int
+	height  int
+	normals []vector.Vector3
+}
+
+func newNormalGrid(width, height int) normalGrid {
+	return normalGrid{width, height, make([]vector.Vector3, width*height)}
+}
+
+func (g normalGrid) at(x, y int) vector.Vector3 {
+	return g.normals[x+(y*g.width)]
+}
+
+func (g normalGrid) set(x, y int, v vector.Vector3) {
+	g.normals[x+(y*g.width)] = v
+}
+
+// readNormalMap reads a standard tangent space normal map, where each channel
+// maps [0, max] to [-1, 1]. Normals are renormalized, as quantizing each
+// channel on its own leaves them slightly off unit length.
+func readNormalMap(img image.Image) normalGrid {
+	bounds := img.Bounds()
+	grid := newNormalGrid(bounds.Dx(), bounds.Dy())
+
+	for y := 0; y < grid.height; y++ {
+		for x := 0; x < grid.width; x++ {
+			c := color.NRGBA64Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+			n := vector.NewVector3(
+				(float64(c.R)/65535.0)*2-1,
+				(float64(c.G)/65535.0)*2-1,
+				(float64(c.B)/65535.0)*2-1,
+			)
+
+			if n.Length() == 0 {
+				n = vector.NewVector3(0, 0, 1)
+			}
+			grid.set(x, y, n.Normalized())
+		}
+	}
+	return grid
+}
+
+func toChannel(n float64, max float64) float64 {
+	return math.Round(unitpacking.Clamp((n+1)/2, 0, 1) * max)
+}
+
+// writeNormalMap converts normals back into a standard tangent space normal
+// map with 8 or 16 bits per channel.
+func writeNormalMap(grid normalGrid, sixteenBit bool) image.Image {
+	rect := image.Rect(0, 0, grid.width, grid.height)
+	if sixteenBit {
+		img := image.NewNRGBA64(rect)
+		for y := 0; y < grid.height; y++ {
+			for x := 0; x < grid.width; x++ {
+				n := grid.at(x, y)
+				img.SetNRGBA64(x, y, color.NRGBA64{
+					R: uint16(toChannel(n.X(), 65535)),
+					G: uint16(toChannel(n.Y(), 65535)),
+					B: uint16(toChannel(n.Z(), 65535)),
+					A: 0xFFFF,
+				})
+			}
+		}
+		return img
+	}
+
+	img := image.NewNRGBA(rect)
+	for y := 0; y < grid.height; y++ {
+		for x := 0; x < grid.width; x++ {
+			n := grid.at(x, y)
+			img.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(toChannel(n.X(), 255)),
+				G: uint8(toChannel(n.Y(), 255)),
+				B: uint8(toChannel(n.Z(), 255)),
+				A: 0xFF,
+			})
+		}
+	}
+	return img
+}
+
+// encodeTexture packs every normal with the codec and lays the codes out in
+// the texture format.
+func encodeTexture(grid normalGrid, format textureFormat, codec unitpacking.Codec) image.Image {
+	rect := image.Rect(0, 0, grid.width, grid.height)
+	if format.sixteenBit {
+		img := image.NewNRGBA64(rect)
+		for y := 0; y < grid.height; y++ {
+			for x := 0; x < grid.width; x++ {
+				b := codec.Pack(grid.at(x, y))
+				img.SetNRGBA64(x, y, color.NRGBA64{
+					R: uint16(b[0]) | uint16(b[1])<<8,
+					G: uint16(b[2]) | uint16(b[3])<<8,
+					A: 0xFFFF,
+				})
+			}
+		}
+		return img
+	}
+
+	img := image.NewNRGBA(rect)
+	for y := 0; y < grid.height; y++ {
+		for x := 0; x < grid.width; x++ {
+			b := append(codec.Pack(grid.at(x, y)), 0)
+			img.SetNRGBA(x, y, color.NRGBA{R: b[0], G: b[1], B: b[2], A: 0xFF})
+		}
+	}
+	return img
+}
+
+// decodeTexture reads codes back out of a texture written by encodeTexture.
+// The PNG's bit depth must match the format, since converting between depths
+// would scramble the codes.
+func decodeTexture(img image.Image, format textureFormat, codec unitpacking.Codec) (normalGrid, error) {
+	bounds := img.Bounds()
+	grid := newNormalGrid(bounds.Dx(), bounds.Dy())
+
+	switch img.(type) {
+	case *image.NRGBA64, *image.RGBA64:
+		if !format.sixteenBit {
+			return grid, fmt.Errorf("%s textures are 8 bit PNGs, but got a 16 bit one", format.name)
+		}
+	case *image.NRGBA, *image.RGBA:
+		if format.sixteenBit {
+			return grid, fmt.Errorf("%s textures are 16 bit PNGs, but got an 8 bit one", format.name)
+		}
+	default:
+		return grid, fmt.Errorf("%s textures are RGB PNGs, but got %T", format.name, img)
+	}
+
+	b := make([]byte, 4)
+	for y := 0; y < grid.height; y++ {
+		for x := 0; x < grid.width; x++ {
+			c := color.NRGBA64Model.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.NRGBA64)
+			if c.A != 0xFFFF {
+				return grid, fmt.Errorf("pixel %d, %d isn't opaque, so its code can't be recovered", x, y)
+			}
+
+			if format.sixteenBit {
+				b[0], b[1], b[2], b[3] = byte(c.R), byte(c.R>>8), byte(c.G), byte(c.G>>8)
+			} else {
+				b[0], b[1], b[2] = byte(c.R>>8), byte(c.G>>8), byte(c.B>>8)
+			}
+			grid.set(x, y, codec.Unpack(b[:codec.Size()]))
+		}
+	}
+	return grid, nil
+}
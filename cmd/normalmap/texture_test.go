@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomGrid(width, height int, upperHemisphere bool) normalGrid {
+	r := rand.New(rand.NewSource(37))
+	grid := newNormalGrid(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			n := vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64()).Normalized()
+			if upperHemisphere && n.Z() < 0 {
+				n = vector.NewVector3(n.X(), n.Y(), -n.Z())
+			}
+			grid.set(x, y, n)
+		}
+	}
+	return grid
+}
+
+func TestTexture_RoundTrip(t *testing.T) {
+	for _, format := range textureFormats {
+		for _, mapping := range []string{"oct", "hemioct"} {
+			t.Run(format.name+"/"+mapping, func(t *testing.T) {
+				codec, err := format.codec(mapping)
+				assert.NoError(t, err)
+
+				grid := randomGrid(7, 5, mapping == "hemioct")
+
+				// Through an actual PNG, so the bit depth written is the one
+				// read back
+				var buf bytes.Buffer
+				assert.NoError(t, png.Encode(&buf, encodeTexture(grid, format, codec)))
+				img, err := png.Decode(&buf)
+				assert.NoError(t, err)
+
+				decoded, err := decodeTexture(img, format, codec)
+				assert.NoError(t, err)
+				for i, n := range grid.normals {
+					assert.Equal(t, codec.Unpack(codec.Pack(n)), decoded.normals[i])
+				}
+			})
+		}
+	}
+}
+
+func TestTexture_ChannelLayout(t *testing.T) {
+	n := vector.NewVector3(0.3, -0.2, 0.9).Normalized()
+	grid := newNormalGrid(1, 1)
+	grid.set(0, 0, n)
+
+	for _, format := range textureFormats {
+		for _, mapping := range []string{"oct", "hemioct"} {
+			t.Run(format.name+"/"+mapping, func(t *testing.T) {
+				codec, err := format.codec(mapping)
+				assert.NoError(t, err)
+				b := append(codec.Pack(n), 0)
+
+				// Code bytes are spread across the channels in little endian
+				// order
+				c := color.NRGBA64Model.Convert(encodeTexture(grid, format, codec).At(0, 0)).(color.NRGBA64)
+				assert.Equal(t, uint16(0xFFFF), c.A)
+				switch format.name {
+				case "rg8":
+					assert.Equal(t, []uint16{uint16(b[0]) * 0x101, uint16(b[1]) * 0x101, 0}, []uint16{c.R, c.G, c.B})
+				case "rgb8":
+					assert.Equal(t, []uint16{uint16(b[0]) * 0x101, uint16(b[1]) * 0x101, uint16(b[2]) * 0x101}, []uint16{c.R, c.G, c.B})
+				case "rg16":
+					assert.Equal(t, []uint16{uint16(b[0]) | uint16(b[1])<<8, uint16(b[2]) | uint16(b[3])<<8, 0}, []uint16{c.R, c.G, c.B})
+				default:
+					t.Fatalf("no expected layout for %s", format.name)
+				}
+			})
+		}
+	}
+}
+
+func TestTexture_WrongBitDepth(t *testing.T) {
+	grid := randomGrid(2, 2, false)
+	rg8, err := formatByName("rg8")
+	assert.NoError(t, err)
+	rg16, err := formatByName("rg16")
+	assert.NoError(t, err)
+
+	_, err = decodeTexture(encodeTexture(grid, rg8, rg8.octCodec), rg16, rg16.octCodec)
+	assert.Error(t, err)
+	_, err = decodeTexture(encodeTexture(grid, rg16, rg16.octCodec), rg8, rg8.octCodec)
+	assert.Error(t, err)
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+)
+
+// Missing vectors, like those packed as a codec's null code, are written as
+// NaN in raw binary, empty fields in CSV, and null in JSON. Reading them back
+// gives a vector of NaNs, which the sentinel policy packs as the null code.
+
+var formats = []string{"f32", "f64", "csv", "json"}
+
+func missing() vector.Vector3 {
+	return vector.NewVector3(math.NaN(), math.NaN(), math.NaN())
+}
+
+func isMissing(v vector.Vector3) bool {
+	return math.IsNaN(v.X()) && math.IsNaN(v.Y()) && math.IsNaN(v.Z())
+}
+
+// vectorReader reads vectors one at a time, returning io.EOF once the input
+// ends.
+type vectorReader interface {
+	Read() (vector.Vector3, error)
+}
+
+// vectorWriter writes vectors one at a time. Close finishes off the output,
+// but leaves the underlying writer open.
+type vectorWriter interface {
+	Write(v vector.Vector3) error
+	Close() error
+}
+
+func newVectorReader(format string, r io.Reader) (vectorReader, error) {
+	switch format {
+	case "f32":
+		return &rawReader{in: bufio.NewReader(r), size: 4}, nil
+	case "f64":
+		return &rawReader{in: bufio.NewReader(r), size: 8}, nil
+	case "csv":
+		in := csv.NewReader(r)
+		in.FieldsPerRecord = 3
+		in.TrimLeadingSpace = true
+		return &csvReader{in: in}, nil
+	case "json":
+		return &jsonReader{in: json.NewDecoder(r)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(formats, ", "))
+}
+
+func newVectorWriter(format string, w io.Writer) (vectorWriter, error) {
+	switch format {
+	case "f32":
+		return &rawWriter{out: w, size: 4}, nil
+	case "f64":
+		return &rawWriter{out: w, size: 8}, nil
+	case "csv":
+		return &csvWriter{out: csv.NewWriter(w)}, nil
+	case "json":
+		return &jsonWriter{out: w}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, expected one of %s", format, strings.Join(formats, ", "))
+}
+
+// rawReader reads little endian float triples, 4 or 8 bytes per component.
+type rawReader struct {
+	in   *bufio.Reader
+	size int
+}
+
+func (r *rawReader) Read() (vector.Vector3, error) {
+	buf := make([]byte, r.size*3)
+	if _, err := io.ReadFull(r.in, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return vector.Vector3{}, fmt.Errorf("input ends partway through a vector")
+		}
+		return vector.Vector3{}, err
+	}
+
+	components := make([]float64, 3)
+	for i := range components {
+		if r.size == 4 {
+			components[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:])))
+		} else {
+			components[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[i*8:]))
+		}
+	}
+	return vector.NewVector3(components[0], components[1], components[2]), nil
+}
+
+type rawWriter struct {
+	out  io.Writer
+	size int
+}
+
+func (w *rawWriter) Write(v vector.Vector3) error {
+	buf := make([]byte, w.size*3)
+	for i, component := range []float64{v.X(), v.Y(), v.Z()} {
+		if w.size == 4 {
+			binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(component)))
+		} else {
+			binary.LittleEndian.PutUint64(buf[i*8:], math.Float64bits(component))
+		}
+	}
+	_, err := w.out.Write(buf)
+	return err
+}
+
+func (w *rawWriter) Close() error {
+	return nil
+}
+
+// csvReader reads one x,y,z vector per record. The first record is skipped as
+// a header when none of its fields are numbers, and a first record with only
+// some numbers is an error like any other.
+type csvReader struct {
+	in   *csv.Reader
+	read bool
+}
+
+func (r *csvReader) Read() (vector.Vector3, error) {
+	for {
+		record, err := r.in.Read()
+		if err != nil {
+			return vector.Vector3{}, err
+		}
+		first := !r.read
+		r.read = true
+
+		if record[0] == "" && record[1] == "" && record[2] == "" {
+			return missing(), nil
+		}
+
+		components := make([]float64, 3)
+		numbers := 0
+		var parseErr error
+		for i, field := range record {
+			if components[i], err = strconv.ParseFloat(field, 64); err != nil {
+				if parseErr == nil {
+					parseErr = err
+				}
+				continue
+			}
+			numbers++
+		}
+
+		if parseErr == nil {
+			return vector.NewVector3(components[0], components[1], components[2]), nil
+		}
+		if !first || numbers > 0 {
+			line, _ := r.in.FieldPos(0)
+			return vector.Vector3{}, fmt.Errorf("line %d: %w", line, parseErr)
+		}
+	}
+}
+
+type csvWriter struct {
+	out    *csv.Writer
+	header bool
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (w *csvWriter) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	w.header = true
+	return w.out.Write([]string{"x", "y", "z"})
+}
+
+func (w *csvWriter) Write(v vector.Vector3) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	record := []string{"", "", ""}
+	if !isMissing(v) {
+		record = []string{formatFloat(v.X()), formatFloat(v.Y()), formatFloat(v.Z())}
+	}
+	return w.out.Write(record)
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.out.Flush()
+	return w.out.Error()
+}
+
+// jsonReader streams the elements of a JSON array of [x, y, z] arrays.
+type jsonReader struct {
+	in      *json.Decoder
+	started bool
+}
+
+func (r *jsonReader) Read() (vector.Vector3, error) {
+	if !r.started {
+		r.started = true
+		token, err := r.in.Token()
+		if err == io.EOF {
+			return vector.Vector3{}, fmt.Errorf("expected a JSON array of vectors, got no input")
+		}
+		if err != nil {
+			return vector.Vector3{}, err
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return vector.Vector3{}, fmt.Errorf("expected a JSON array of vectors, got %v", token)
+		}
+	}
+
+	if !r.in.More() {
+		if _, err := r.in.Token(); err != nil {
+			return vector.Vector3{}, err
+		}
+		return vector.Vector3{}, io.EOF
+	}
+
+	var components *[]float64
+	if err := r.in.Decode(&components); err != nil {
+		return vector.Vector3{}, err
+	}
+	if components == nil {
+		return missing(), nil
+	}
+	if len(*components) != 3 {
+		return vector.Vector3{}, fmt.Errorf("expected vectors of 3 components, got %d", len(*components))
+	}
+	return vector.NewVector3((*components)[0], (*components)[1], (*components)[2]), nil
+}
+
+type jsonWriter struct {
+	out   io.Writer
+	count int
+}
+
+func (w *jsonWriter) Write(v vector.Vector3) error {
+	prefix := ",\n  "
+	if w.count == 0 {
+		prefix = "[\n  "
+	}
+	w.count++
+
+	element := "null"
+	if !isMissing(v) {
+		for _, component := range []float64{v.X(), v.Y(), v.Z()} {
+			if math.IsNaN(component) || math.IsInf(component, 0) {
+				return fmt.Errorf("JSON can't hold the vector %v, only finite components or null", v)
+			}
+		}
+		element = fmt.Sprintf("[%s, %s, %s]", formatFloat(v.X()), formatFloat(v.Y()), formatFloat(v.Z()))
+	}
+	_, err := io.WriteString(w.out, prefix+element)
+	return err
+}
+
+func (w *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if w.count == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(w.out, closing)
+	return err
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func readAll(t *testing.T, format string, in io.Reader) ([]vector.Vector3, error) {
+	r, err := newVectorReader(format, in)
+	assert.NoError(t, err)
+
+	vectors := make([]vector.Vector3, 0)
+	for {
+		v, err := r.Read()
+		if err == io.EOF {
+			return vectors, nil
+		}
+		if err != nil {
+			return vectors, err
+		}
+		vectors = append(vectors, v)
+	}
+}
+
+func writeAll(t *testing.T, format string, vectors []vector.Vector3) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	w, err := newVectorWriter(format, &buf)
+	assert.NoError(t, err)
+
+	for _, v := range vectors {
+		if err := w.Write(v); err != nil {
+			return &buf, err
+		}
+	}
+	return &buf, w.Close()
+}
+
+// assertSameVectors compares component by component, treating NaN as equal
+// to NaN.
+func assertSameVectors(t *testing.T, expected, actual []vector.Vector3) {
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		e := []float64{expected[i].X(), expected[i].Y(), expected[i].Z()}
+		a := []float64{actual[i].X(), actual[i].Y(), actual[i].Z()}
+		for j := range e {
+			if math.IsNaN(e[j]) {
+				assert.True(t, math.IsNaN(a[j]), "vector %d: expected NaN, got %v", i, actual[i])
+				continue
+			}
+			assert.Equal(t, e[j], a[j], "vector %d", i)
+		}
+	}
+}
+
+func TestFormats_RoundTrip(t *testing.T) {
+	// Every component is exactly representable as a float32
+	vectors := []vector.Vector3{
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(-0.5, 0.25, 0.75),
+		missing(),
+		vector.NewVector3(float64(float32(1e-3)), -1e3, 0.125),
+	}
+
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			buf, err := writeAll(t, format, vectors)
+			assert.NoError(t, err)
+
+			read, err := readAll(t, format, buf)
+			assert.NoError(t, err)
+			assertSameVectors(t, vectors, read)
+			assert.True(t, isMissing(read[2]))
+		})
+	}
+}
+
+func TestFormats_Empty(t *testing.T) {
+	for _, format := range formats {
+		t.Run(format, func(t *testing.T) {
+			buf, err := writeAll(t, format, nil)
+			assert.NoError(t, err)
+
+			read, err := readAll(t, format, buf)
+			assert.NoError(t, err)
+			assert.Empty(t, read)
+		})
+	}
+}
+
+func TestFormats_PartlyNaN(t *testing.T) {
+	vectors := []vector.Vector3{vector.NewVector3(math.NaN(), 0.5, math.Inf(-1))}
+
+	for _, format := range []string{"f32", "f64", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			buf, err := writeAll(t, format, vectors)
+			assert.NoError(t, err)
+
+			read, err := readAll(t, format, buf)
+			assert.NoError(t, err)
+			assertSameVectors(t, vectors, read)
+			assert.False(t, isMissing(read[0]))
+		})
+	}
+
+	// JSON has no way of writing NaN or infinity
+	_, err := writeAll(t, "json", vectors)
+	assert.Error(t, err)
+}
+
+func TestCSV_Header(t *testing.T) {
+	for name, tc := range map[string]struct {
+		csv      string
+		expected []vector.Vector3
+	}{
+		"header":    {"x,y,z\n1,2,3\n", []vector.Vector3{vector.NewVector3(1, 2, 3)}},
+		"no header": {"1,2,3\n4, 5, 6\n", []vector.Vector3{vector.NewVector3(1, 2, 3), vector.NewVector3(4, 5, 6)}},
+		"missing":   {",,\n1,2,3\n", []vector.Vector3{missing(), vector.NewVector3(1, 2, 3)}},
+		"nan":       {"NaN,0,1\n", []vector.Vector3{vector.NewVector3(math.NaN(), 0, 1)}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			read, err := readAll(t, "csv", strings.NewReader(tc.csv))
+			assert.NoError(t, err)
+			assertSameVectors(t, tc.expected, read)
+		})
+	}
+}
+
+func TestCSV_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		csv     string
+		message string
+	}{
+		"typo in first record": {"1,abc,3\n4,5,6\n", "line 1"},
+		"typo after header":    {"x,y,z\n1,2,3\n4,abc,6\n", "line 3"},
+		"second header":        {"x,y,z\nx,y,z\n", "line 2"},
+		"wrong field count":    {"1,2\n", "wrong number of fields"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readAll(t, "csv", strings.NewReader(tc.csv))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+}
+
+func TestJSON_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not an array":   `{"x": 1}`,
+		"no input":       ``,
+		"wrong length":   `[[1, 2]]`,
+		"not terminated": `[[1, 2, 3]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := readAll(t, "json", strings.NewReader(input))
+			assert.Error(t, err)
+		})
+	}
+}
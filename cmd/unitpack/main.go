@@ -0,0 +1,418 @@
+// Command unitpack packs files of vectors with any of the library's codecs,
+// and unpacks them again. Vectors are read and written as raw little endian
+// float32 or float64 triples, CSV, or a JSON array of [x, y, z] arrays.
+// Inputs and outputs default to stdin and stdout, so commands can be chained
+// together in a pipeline:
+//
+//	unitpack encode --codec oct24 --format csv normals.csv normals.oct24
+//	unitpack decode --codec oct24 --format json < normals.oct24
+//	unitpack inspect --codec oct24 normals.oct24
+//	unitpack convert --codec oct32 --to-codec oct16 < normals.oct32 > normals.oct16
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage:\n")
+	fmt.Fprintf(os.Stderr, "  unitpack encode  --codec name [--format f32|f64|csv|json] [--policy name] [input [output]]\n")
+	fmt.Fprintf(os.Stderr, "  unitpack decode  --codec name [--format f32|f64|csv|json] [input [output]]\n")
+	fmt.Fprintf(os.Stderr, "  unitpack inspect --codec name [-n count] [input]\n")
+	fmt.Fprintf(os.Stderr, "  unitpack convert --codec name --to-codec name [input [output]]\n\n")
+	fmt.Fprintf(os.Stderr, "inputs and outputs default to stdin and stdout, or pass - for either\n\n")
+	fmt.Fprintf(os.Stderr, "codecs: %s\n", strings.Join(codecNames(), ", "))
+}
+
+func codecNames() []string {
+	names := make([]string, 0)
+	for _, c := range unitpacking.AllCodecs() {
+		names = append(names, c.Name())
+	}
+	return names
+}
+
+func codecByName(name string) (unitpacking.Codec, error) {
+	if name == "" {
+		return nil, fmt.Errorf("a codec is required, one of %s", strings.Join(codecNames(), ", "))
+	}
+	codec, ok := unitpacking.CodecByName(strings.ToLower(name))
+	if !ok {
+		return nil, fmt.Errorf("unknown codec %q, expected one of %s", name, strings.Join(codecNames(), ", "))
+	}
+	return codec, nil
+}
+
+func policyByName(name string) (unitpacking.Policy, error) {
+	policies := []unitpacking.Policy{
+		unitpacking.PolicyNormalize,
+		unitpacking.PolicyClamp,
+		unitpacking.PolicyError,
+		unitpacking.PolicySentinel,
+	}
+
+	names := make([]string, len(policies))
+	for i, p := range policies {
+		if p.String() == name {
+			return p, nil
+		}
+		names[i] = p.String()
+	}
+	return 0, fmt.Errorf("unknown policy %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// paths picks the input and output out of the positional arguments, where
+// missing paths and "-" stand for stdin and stdout.
+func paths(set *flag.FlagSet, max int) (in, out string, err error) {
+	if set.NArg() > max {
+		return "", "", fmt.Errorf("expected at most %d paths, got %d", max, set.NArg())
+	}
+	in, out = "-", "-"
+	if set.NArg() > 0 {
+		in = set.Arg(0)
+	}
+	if set.NArg() > 1 {
+		out = set.Arg(1)
+	}
+	return in, out, nil
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// output buffers everything written to a file or stdout.
+type output struct {
+	*bufio.Writer
+	file *os.File
+}
+
+func createOutput(path string) (*output, error) {
+	file := os.Stdout
+	if path != "-" {
+		var err error
+		if file, err = os.Create(path); err != nil {
+			return nil, err
+		}
+	}
+	return &output{Writer: bufio.NewWriter(file), file: file}, nil
+}
+
+func (o *output) Close() error {
+	err := o.Flush()
+	if o.file != os.Stdout {
+		if closeErr := o.file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// readCode reads the next packed code, returning io.EOF when the input ends
+// cleanly between codes.
+func readCode(in io.Reader, code []byte) error {
+	_, err := io.ReadFull(in, code)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("input ends partway through a %d byte code", len(code))
+	}
+	return err
+}
+
+func isNullCode(codec unitpacking.Codec, code []byte) bool {
+	nullCodec, ok := codec.(unitpacking.NullCodec)
+	return ok && unitpacking.IsNull(nullCodec, code)
+}
+
+func encode(args []string) error {
+	set := flag.NewFlagSet("encode", flag.ExitOnError)
+	codecName := set.String("codec", "", "codec to pack vectors with")
+	format := set.String("format", "f32", "format of the input vectors, one of f32, f64, csv or json")
+	policyName := set.String("policy", "normalize", "what to do with vectors that aren't unit length, one of normalize, clamp, error or sentinel")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	codec, err := codecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	policy, err := policyByName(*policyName)
+	if err != nil {
+		return err
+	}
+	inPath, outPath, err := paths(set, 2)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	vectors, err := newVectorReader(*format, in)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(outPath)
+	if err != nil {
+		return err
+	}
+
+	validator := unitpacking.Validator{Policy: policy}
+	for i := 0; ; i++ {
+		v, err := vectors.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("vector %d: %w", i, err)
+		}
+
+		packed, err := validator.Pack(codec, v)
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("vector %d %v: %w", i, v, err)
+		}
+
+		if _, err := out.Write(packed); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func decode(args []string) error {
+	set := flag.NewFlagSet("decode", flag.ExitOnError)
+	codecName := set.String("codec", "", "codec the vectors were packed with")
+	format := set.String("format", "f32", "format to write the vectors in, one of f32, f64, csv or json")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	codec, err := codecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	inPath, outPath, err := paths(set, 2)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(outPath)
+	if err != nil {
+		return err
+	}
+
+	vectors, err := newVectorWriter(*format, out)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	buffered := bufio.NewReader(in)
+	code := make([]byte, codec.Size())
+	for {
+		err := readCode(buffered, code)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return err
+		}
+
+		v := missing()
+		if !isNullCode(codec, code) {
+			v = codec.Unpack(code)
+		}
+		if err := vectors.Write(v); err != nil {
+			out.Close()
+			return err
+		}
+	}
+
+	if err := vectors.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func inspect(args []string) error {
+	set := flag.NewFlagSet("inspect", flag.ExitOnError)
+	codecName := set.String("codec", "", "codec the vectors were packed with")
+	limit := set.Int("n", 10, "number of vectors to list, or -1 for all of them")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	codec, err := codecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	inPath, _, err := paths(set, 1)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	buffered := bufio.NewReader(in)
+	code := make([]byte, codec.Size())
+	distinct := make(map[string]struct{})
+	count, nulls := 0, 0
+	for ; ; count++ {
+		err := readCode(buffered, code)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("after %d vectors: %w", count, err)
+		}
+
+		distinct[string(code)] = struct{}{}
+		null := isNullCode(codec, code)
+		if null {
+			nulls++
+		}
+
+		if *limit < 0 || count < *limit {
+			if null {
+				fmt.Fprintf(out, "%8d  %x  null\n", count, code)
+			} else {
+				v := codec.Unpack(code)
+				fmt.Fprintf(out, "%8d  %x  % .9f % .9f % .9f\n", count, code, v.X(), v.Y(), v.Z())
+			}
+		}
+	}
+
+	if *limit >= 0 && count > *limit {
+		fmt.Fprintf(out, "     ...\n")
+	}
+	fmt.Fprintf(out, "\ncodec     %s (%d bytes)\n", codec.Name(), codec.Size())
+	fmt.Fprintf(out, "vectors   %d\n", count)
+	fmt.Fprintf(out, "distinct  %d\n", len(distinct))
+	if _, ok := codec.(unitpacking.NullCodec); ok {
+		fmt.Fprintf(out, "nulls     %d\n", nulls)
+	}
+	return nil
+}
+
+func convert(args []string) error {
+	set := flag.NewFlagSet("convert", flag.ExitOnError)
+	codecName := set.String("codec", "", "codec the vectors were packed with")
+	toCodecName := set.String("to-codec", "", "codec to repack the vectors with")
+	if err := set.Parse(args); err != nil {
+		return err
+	}
+
+	from, err := codecByName(*codecName)
+	if err != nil {
+		return err
+	}
+	to, err := codecByName(*toCodecName)
+	if err != nil {
+		return err
+	}
+	inPath, outPath, err := paths(set, 2)
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(inPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := createOutput(outPath)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewReader(in)
+	code := make([]byte, from.Size())
+	for i := 0; ; i++ {
+		err := readCode(buffered, code)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			out.Close()
+			return err
+		}
+
+		// Null codes stay null, as long as the new codec has one
+		var packed []byte
+		if isNullCode(from, code) {
+			nullCodec, ok := to.(unitpacking.NullCodec)
+			if !ok {
+				out.Close()
+				return fmt.Errorf("vector %d: %w", i, unitpacking.ErrNoNullCode)
+			}
+			packed = nullCodec.Null()
+		} else {
+			packed = to.Pack(from.Unpack(code))
+		}
+
+		if _, err := out.Write(packed); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	return out.Close()
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	commands := map[string]func([]string) error{
+		"encode":  encode,
+		"decode":  decode,
+		"inspect": inspect,
+		"convert": convert,
+	}
+
+	command, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	if err := command(os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+}
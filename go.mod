@@ -1,9 +1,15 @@
 module github.com/recolude/unitpacking
 
-go 1.15
+go 1.18
 
 require (
 	github.com/EliCDavis/mango v0.0.0-20200728134254-a2070d7f465a
 	github.com/EliCDavis/vector v0.0.0-20200616023845-ce88265e47b5
 	github.com/stretchr/testify v1.7.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
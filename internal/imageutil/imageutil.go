@@ -0,0 +1,53 @@
+// Package imageutil holds the PNG and color helpers shared by the commands
+// that read and write images.
+package imageutil
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// ReadPNG decodes the PNG at the path.
+func ReadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+// WritePNG encodes the image as a PNG at the path.
+func WritePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// HeatColor ramps from black through red and yellow to white as t goes from
+// 0 to 1.
+func HeatColor(t float64) color.NRGBA {
+	t = unitpacking.Clamp(t, 0, 1) * 3
+	channel := func(v float64) uint8 {
+		return uint8(math.Round(unitpacking.Clamp(v, 0, 1) * 255))
+	}
+	return color.NRGBA{R: channel(t), G: channel(t - 1), B: channel(t - 2), A: 0xFF}
+}
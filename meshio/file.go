@@ -0,0 +1,40 @@
+package meshio
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Readers maps the file extensions ReadFile understands to the function that
+// reads them.
+var Readers = map[string]func(io.Reader) (Mesh, error){
+	".glb":  ReadGLTF,
+	".gltf": ReadGLTF,
+	".obj":  ReadOBJ,
+	".ply":  ReadPLY,
+	".stl":  ReadSTL,
+}
+
+// ReadFile reads a mesh from disk, picking the format by the file's
+// extension.
+func ReadFile(path string) (Mesh, error) {
+	read, ok := Readers[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return Mesh{}, fmt.Errorf("%s: unsupported mesh format", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return Mesh{}, err
+	}
+	defer f.Close()
+
+	mesh, err := read(f)
+	if err != nil {
+		return Mesh{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return mesh, nil
+}
@@ -0,0 +1,753 @@
+package meshio
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/EliCDavis/mango"
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+// glTF accessor component types
+const (
+	gltfByte          = 5120
+	gltfUnsignedByte  = 5121
+	gltfShort         = 5122
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfFloat         = 5126
+)
+
+// glTF buffer view targets and primitive modes
+const (
+	gltfArrayBuffer        = 34962
+	gltfElementArrayBuffer = 34963
+	gltfTriangles          = 4
+)
+
+// GLB container magic numbers
+const (
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+const gltfQuantizationExtension = "KHR_mesh_quantization"
+
+// GLTFOctNormalAttribute is the application specific attribute the Oct
+// encodings store their normals in. glTF has no octahedral normal type, so
+// viewers that don't know the attribute fall back to flat shading.
+const GLTFOctNormalAttribute = "_NORMAL_OCT"
+
+var gltfComponentSizes = map[int]int{
+	gltfByte:          1,
+	gltfUnsignedByte:  1,
+	gltfShort:         2,
+	gltfUnsignedShort: 2,
+	gltfUnsignedInt:   4,
+	gltfFloat:         4,
+}
+
+var gltfTypeComponents = map[string]int{
+	"SCALAR": 1,
+	"VEC2":   2,
+	"VEC3":   3,
+	"VEC4":   4,
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Mesh *int `json:"mesh,omitempty"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices,omitempty"`
+	Mode       *int           `json:"mode,omitempty"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfAccessor struct {
+	BufferView    *int            `json:"bufferView,omitempty"`
+	ByteOffset    int             `json:"byteOffset,omitempty"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized,omitempty"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Min           []float64       `json:"min,omitempty"`
+	Max           []float64       `json:"max,omitempty"`
+	Sparse        json.RawMessage `json:"sparse,omitempty"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset,omitempty"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride,omitempty"`
+	Target     int `json:"target,omitempty"`
+}
+
+type gltfBuffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
+
+type gltfDocument struct {
+	Asset              gltfAsset        `json:"asset"`
+	ExtensionsUsed     []string         `json:"extensionsUsed,omitempty"`
+	ExtensionsRequired []string         `json:"extensionsRequired,omitempty"`
+	Scene              *int             `json:"scene,omitempty"`
+	Scenes             []gltfScene      `json:"scenes,omitempty"`
+	Nodes              []gltfNode       `json:"nodes,omitempty"`
+	Meshes             []gltfMesh       `json:"meshes,omitempty"`
+	Accessors          []gltfAccessor   `json:"accessors,omitempty"`
+	BufferViews        []gltfBufferView `json:"bufferViews,omitempty"`
+	Buffers            []gltfBuffer     `json:"buffers,omitempty"`
+}
+
+// GLTFNormalEncoding picks how WriteGLTF and WriteGLB store normals.
+//
+// glTF requires every vertex attribute to start on a 4 byte boundary, so
+// encodings smaller than that are padded out to 4 bytes per normal.
+type GLTFNormalEncoding int
+
+const (
+	// GLTFNormalsFloat stores normals as three 32 bit floats, 12 bytes per
+	// normal. This is the only encoding core glTF allows.
+	GLTFNormalsFloat GLTFNormalEncoding = iota
+
+	// GLTFNormalsByte stores xyz as normalized signed bytes, 4 bytes per
+	// normal once padded. Requires KHR_mesh_quantization.
+	GLTFNormalsByte
+
+	// GLTFNormalsShort stores xyz as normalized signed shorts, 8 bytes per
+	// normal once padded. Requires KHR_mesh_quantization.
+	GLTFNormalsShort
+
+	// GLTFNormalsOct16 stores Oct16 codes as two normalized signed bytes in
+	// the GLTFOctNormalAttribute attribute, 4 bytes per normal once padded.
+	GLTFNormalsOct16
+
+	// GLTFNormalsOct32 stores Oct32 codes as two normalized signed shorts in
+	// the GLTFOctNormalAttribute attribute, 4 bytes per normal.
+	GLTFNormalsOct32
+)
+
+// GLTFNormalEncodings returns every way glTF files can store normals.
+func GLTFNormalEncodings() []GLTFNormalEncoding {
+	return []GLTFNormalEncoding{
+		GLTFNormalsFloat,
+		GLTFNormalsByte,
+		GLTFNormalsShort,
+		GLTFNormalsOct16,
+		GLTFNormalsOct32,
+	}
+}
+
+func (e GLTFNormalEncoding) String() string {
+	switch e {
+	case GLTFNormalsFloat:
+		return "float"
+	case GLTFNormalsByte:
+		return "byte"
+	case GLTFNormalsShort:
+		return "short"
+	case GLTFNormalsOct16:
+		return "oct16"
+	case GLTFNormalsOct32:
+		return "oct32"
+	}
+	return fmt.Sprintf("GLTFNormalEncoding(%d)", int(e))
+}
+
+// gltfNormalLayout is how an encoding's normals appear in the file.
+type gltfNormalLayout struct {
+	attribute     string
+	componentType int
+	accessorType  string
+	stride        int
+}
+
+func (e GLTFNormalEncoding) layout() (gltfNormalLayout, error) {
+	switch e {
+	case GLTFNormalsFloat:
+		return gltfNormalLayout{"NORMAL", gltfFloat, "VEC3", 12}, nil
+	case GLTFNormalsByte:
+		return gltfNormalLayout{"NORMAL", gltfByte, "VEC3", 4}, nil
+	case GLTFNormalsShort:
+		return gltfNormalLayout{"NORMAL", gltfShort, "VEC3", 8}, nil
+	case GLTFNormalsOct16:
+		return gltfNormalLayout{GLTFOctNormalAttribute, gltfByte, "VEC2", 4}, nil
+	case GLTFNormalsOct32:
+		return gltfNormalLayout{GLTFOctNormalAttribute, gltfShort, "VEC2", 4}, nil
+	}
+	return gltfNormalLayout{}, fmt.Errorf("unknown normal encoding %d", int(e))
+}
+
+// quantized reports whether the encoding needs KHR_mesh_quantization.
+func (e GLTFNormalEncoding) quantized() bool {
+	return e == GLTFNormalsByte || e == GLTFNormalsShort
+}
+
+// EncodeGLTFNormals lays normals out the way they appear in the vertex buffer
+// of a glTF file written with the given encoding, padding included.
+func EncodeGLTFNormals(normals []vector.Vector3, encoding GLTFNormalEncoding) ([]byte, error) {
+	layout, err := encoding.layout()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(normals)*layout.stride)
+	for i, n := range normals {
+		b := out[i*layout.stride:]
+		switch encoding {
+		case GLTFNormalsFloat:
+			binary.LittleEndian.PutUint32(b[0:], math.Float32bits(float32(n.X())))
+			binary.LittleEndian.PutUint32(b[4:], math.Float32bits(float32(n.Y())))
+			binary.LittleEndian.PutUint32(b[8:], math.Float32bits(float32(n.Z())))
+
+		case GLTFNormalsByte:
+			b[0] = byte(snorm(n.X(), 127))
+			b[1] = byte(snorm(n.Y(), 127))
+			b[2] = byte(snorm(n.Z(), 127))
+
+		case GLTFNormalsShort:
+			binary.LittleEndian.PutUint16(b[0:], uint16(snorm(n.X(), 32767)))
+			binary.LittleEndian.PutUint16(b[2:], uint16(snorm(n.Y(), 32767)))
+			binary.LittleEndian.PutUint16(b[4:], uint16(snorm(n.Z(), 32767)))
+
+		// Oct codes are offset by half their range, so removing the offset
+		// leaves exactly the snorm value glTF expects
+		case GLTFNormalsOct16:
+			code := unitpacking.PackOct16Uint16(n)
+			b[0] = byte(int(code>>8) - 128)
+			b[1] = byte(int(code&0xFF) - 128)
+
+		case GLTFNormalsOct32:
+			code := unitpacking.PackOct32Uint32(n)
+			binary.LittleEndian.PutUint16(b[0:], uint16(int(code>>16)-32768))
+			binary.LittleEndian.PutUint16(b[2:], uint16(int(code&0xFFFF)-32768))
+		}
+	}
+	return out, nil
+}
+
+func snorm(v float64, scale float64) int {
+	return int(math.Round(unitpacking.Clamp(v, -1, 1) * scale))
+}
+
+// gltfBuilder appends buffer views and accessors to a document, keeping
+// everything in a single buffer.
+type gltfBuilder struct {
+	doc gltfDocument
+	bin bytes.Buffer
+}
+
+func (b *gltfBuilder) addView(data []byte, stride, target int) int {
+	// Every view starts 4 byte aligned, which covers the alignment of any
+	// component type
+	for b.bin.Len()%4 != 0 {
+		b.bin.WriteByte(0)
+	}
+
+	b.doc.BufferViews = append(b.doc.BufferViews, gltfBufferView{
+		Buffer:     0,
+		ByteOffset: b.bin.Len(),
+		ByteLength: len(data),
+		ByteStride: stride,
+		Target:     target,
+	})
+	b.bin.Write(data)
+	return len(b.doc.BufferViews) - 1
+}
+
+func (b *gltfBuilder) addAccessor(accessor gltfAccessor) int {
+	b.doc.Accessors = append(b.doc.Accessors, accessor)
+	return len(b.doc.Accessors) - 1
+}
+
+func buildGLTF(mesh mango.Mesh, normals []vector.Vector3, encoding GLTFNormalEncoding) (gltfDocument, []byte, error) {
+	vertices := mesh.Vertices()
+	if len(vertices) == 0 {
+		return gltfDocument{}, nil, errors.New("mesh has no vertices")
+	}
+	if len(mesh.Triangles()) == 0 {
+		return gltfDocument{}, nil, errors.New("mesh has no triangles")
+	}
+	if normals != nil && len(normals) != len(vertices) {
+		return gltfDocument{}, nil, fmt.Errorf("%d normals for %d vertices", len(normals), len(vertices))
+	}
+
+	builder := gltfBuilder{
+		doc: gltfDocument{Asset: gltfAsset{Version: "2.0", Generator: "unitpacking"}},
+	}
+	primitive := gltfPrimitive{Attributes: make(map[string]int)}
+
+	positions := make([]byte, len(vertices)*12)
+	lower := []float64{math.Inf(1), math.Inf(1), math.Inf(1)}
+	upper := []float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	for i, v := range vertices {
+		for c, component := range []float64{v.X(), v.Y(), v.Z()} {
+			// Bounds have to match the stored floats, not the originals
+			stored := float32(component)
+			binary.LittleEndian.PutUint32(positions[i*12+c*4:], math.Float32bits(stored))
+			lower[c] = math.Min(lower[c], float64(stored))
+			upper[c] = math.Max(upper[c], float64(stored))
+		}
+	}
+	positionView := builder.addView(positions, 12, gltfArrayBuffer)
+	primitive.Attributes["POSITION"] = builder.addAccessor(gltfAccessor{
+		BufferView:    &positionView,
+		ComponentType: gltfFloat,
+		Count:         len(vertices),
+		Type:          "VEC3",
+		Min:           lower,
+		Max:           upper,
+	})
+
+	if normals != nil {
+		layout, err := encoding.layout()
+		if err != nil {
+			return gltfDocument{}, nil, err
+		}
+		data, err := EncodeGLTFNormals(normals, encoding)
+		if err != nil {
+			return gltfDocument{}, nil, err
+		}
+
+		normalView := builder.addView(data, layout.stride, gltfArrayBuffer)
+		primitive.Attributes[layout.attribute] = builder.addAccessor(gltfAccessor{
+			BufferView:    &normalView,
+			ComponentType: layout.componentType,
+			Normalized:    layout.componentType != gltfFloat,
+			Count:         len(normals),
+			Type:          layout.accessorType,
+		})
+
+		if encoding.quantized() {
+			builder.doc.ExtensionsUsed = []string{gltfQuantizationExtension}
+			builder.doc.ExtensionsRequired = []string{gltfQuantizationExtension}
+		}
+	}
+
+	// Pick the smallest index type that can address every vertex
+	indexType, indexSize := gltfUnsignedInt, 4
+	if len(vertices) <= math.MaxUint16 {
+		indexType, indexSize = gltfUnsignedShort, 2
+	}
+	indices := make([]byte, len(mesh.Triangles())*3*indexSize)
+	for i, tri := range mesh.Triangles() {
+		for c, index := range []int{tri.P1(), tri.P2(), tri.P3()} {
+			if index < 0 || index >= len(vertices) {
+				return gltfDocument{}, nil, fmt.Errorf("triangle %d: vertex index %d is out of range of %d vertices", i, index, len(vertices))
+			}
+			if indexSize == 2 {
+				binary.LittleEndian.PutUint16(indices[(i*3+c)*2:], uint16(index))
+			} else {
+				binary.LittleEndian.PutUint32(indices[(i*3+c)*4:], uint32(index))
+			}
+		}
+	}
+	indexView := builder.addView(indices, 0, gltfElementArrayBuffer)
+	indexAccessor := builder.addAccessor(gltfAccessor{
+		BufferView:    &indexView,
+		ComponentType: indexType,
+		Count:         len(mesh.Triangles()) * 3,
+		Type:          "SCALAR",
+	})
+	primitive.Indices = &indexAccessor
+
+	meshIndex, sceneIndex := 0, 0
+	builder.doc.Meshes = []gltfMesh{{Primitives: []gltfPrimitive{primitive}}}
+	builder.doc.Nodes = []gltfNode{{Mesh: &meshIndex}}
+	builder.doc.Scenes = []gltfScene{{Nodes: []int{0}}}
+	builder.doc.Scene = &sceneIndex
+	builder.doc.Buffers = []gltfBuffer{{ByteLength: builder.bin.Len()}}
+
+	return builder.doc, builder.bin.Bytes(), nil
+}
+
+// WriteGLTF writes the mesh and one normal per vertex as a .gltf file, with
+// its binary data embedded as a base64 data URI. Normals may be nil.
+func WriteGLTF(w io.Writer, mesh mango.Mesh, normals []vector.Vector3, encoding GLTFNormalEncoding) error {
+	doc, bin, err := buildGLTF(mesh, normals, encoding)
+	if err != nil {
+		return err
+	}
+
+	doc.Buffers[0].URI = "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(bin)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteGLB writes the mesh and one normal per vertex as a binary .glb file.
+// Normals may be nil.
+func WriteGLB(w io.Writer, mesh mango.Mesh, normals []vector.Vector3, encoding GLTFNormalEncoding) error {
+	doc, bin, err := buildGLTF(mesh, normals, encoding)
+	if err != nil {
+		return err
+	}
+
+	jsonChunk, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	jsonChunk = padChunk(jsonChunk, ' ')
+	binChunk := padChunk(bin, 0)
+
+	header := make([]byte, 20)
+	binary.LittleEndian.PutUint32(header[0:], glbMagic)
+	binary.LittleEndian.PutUint32(header[4:], 2)
+	binary.LittleEndian.PutUint32(header[8:], uint32(12+8+len(jsonChunk)+8+len(binChunk)))
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(jsonChunk)))
+	binary.LittleEndian.PutUint32(header[16:], glbChunkJSON)
+
+	binHeader := make([]byte, 8)
+	binary.LittleEndian.PutUint32(binHeader[0:], uint32(len(binChunk)))
+	binary.LittleEndian.PutUint32(binHeader[4:], glbChunkBIN)
+
+	for _, part := range [][]byte{header, jsonChunk, binHeader, binChunk} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// padChunk pads a GLB chunk out to a multiple of 4 bytes.
+func padChunk(chunk []byte, pad byte) []byte {
+	for len(chunk)%4 != 0 {
+		chunk = append(chunk, pad)
+	}
+	return chunk
+}
+
+func splitGLB(data []byte) (jsonChunk, binChunk []byte, err error) {
+	if len(data) < 20 {
+		return nil, nil, errors.New("GLB is too short")
+	}
+	if version := binary.LittleEndian.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported GLB version %d", version)
+	}
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("GLB claims %d bytes but only has %d", length, len(data))
+	}
+
+	for offset := 12; offset+8 <= length; {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		offset += 8
+		if chunkLength > length-offset {
+			return nil, nil, fmt.Errorf("GLB chunk at byte %d runs past the end of the file", offset-8)
+		}
+		chunk := data[offset : offset+chunkLength]
+		offset += chunkLength
+
+		switch {
+		case jsonChunk == nil && chunkType != glbChunkJSON:
+			return nil, nil, errors.New("GLB does not start with a JSON chunk")
+		case jsonChunk == nil:
+			jsonChunk = chunk
+		case binChunk == nil && chunkType == glbChunkBIN:
+			binChunk = chunk
+		}
+	}
+
+	if jsonChunk == nil {
+		return nil, nil, errors.New("GLB has no JSON chunk")
+	}
+	return jsonChunk, binChunk, nil
+}
+
+func loadGLTFBuffers(doc gltfDocument, binChunk []byte) ([][]byte, error) {
+	buffers := make([][]byte, len(doc.Buffers))
+	for i, buffer := range doc.Buffers {
+		var data []byte
+		switch {
+		case buffer.URI == "" && i == 0 && binChunk != nil:
+			data = binChunk
+
+		case buffer.URI == "":
+			return nil, fmt.Errorf("buffer %d has no data", i)
+
+		case strings.HasPrefix(buffer.URI, "data:"):
+			comma := strings.Index(buffer.URI, ",")
+			if comma < 0 || !strings.HasSuffix(buffer.URI[:comma], ";base64") {
+				return nil, fmt.Errorf("buffer %d: data URI is not base64", i)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(buffer.URI[comma+1:])
+			if err != nil {
+				return nil, fmt.Errorf("buffer %d: %w", i, err)
+			}
+			data = decoded
+
+		default:
+			return nil, fmt.Errorf("buffer %d: external file %q is not supported", i, buffer.URI)
+		}
+
+		if len(data) < buffer.ByteLength {
+			return nil, fmt.Errorf("buffer %d: expected %d bytes, got %d", i, buffer.ByteLength, len(data))
+		}
+		buffers[i] = data[:buffer.ByteLength]
+	}
+	return buffers, nil
+}
+
+// readGLTFAccessor reads every component of an accessor of the given type as
+// floats, applying the accessor's normalization.
+func readGLTFAccessor(doc gltfDocument, buffers [][]byte, index int, accessorType string) ([]float64, error) {
+	if index < 0 || index >= len(doc.Accessors) {
+		return nil, fmt.Errorf("accessor %d does not exist", index)
+	}
+	accessor := doc.Accessors[index]
+	if accessor.Type != accessorType {
+		return nil, fmt.Errorf("accessor %d: expected %s, got %s", index, accessorType, accessor.Type)
+	}
+	if accessor.Sparse != nil {
+		return nil, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+	size, ok := gltfComponentSizes[accessor.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("accessor %d: unknown component type %d", index, accessor.ComponentType)
+	}
+	if accessor.Count < 0 {
+		return nil, fmt.Errorf("accessor %d: invalid count %d", index, accessor.Count)
+	}
+
+	components := gltfTypeComponents[accessorType]
+	values := make([]float64, accessor.Count*components)
+
+	// Accessors without a buffer view are all zeros
+	if accessor.BufferView == nil || accessor.Count == 0 {
+		return values, nil
+	}
+
+	viewIndex := *accessor.BufferView
+	if viewIndex < 0 || viewIndex >= len(doc.BufferViews) {
+		return nil, fmt.Errorf("accessor %d: buffer view %d does not exist", index, viewIndex)
+	}
+	view := doc.BufferViews[viewIndex]
+	if view.Buffer < 0 || view.Buffer >= len(buffers) {
+		return nil, fmt.Errorf("buffer view %d: buffer %d does not exist", viewIndex, view.Buffer)
+	}
+	buffer := buffers[view.Buffer]
+	if view.ByteOffset < 0 || view.ByteLength < 0 || view.ByteOffset+view.ByteLength > len(buffer) {
+		return nil, fmt.Errorf("buffer view %d runs past the end of buffer %d", viewIndex, view.Buffer)
+	}
+	data := buffer[view.ByteOffset : view.ByteOffset+view.ByteLength]
+
+	stride := view.ByteStride
+	if stride == 0 {
+		stride = components * size
+	}
+	if accessor.ByteOffset < 0 || accessor.ByteOffset+stride*(accessor.Count-1)+components*size > len(data) {
+		return nil, fmt.Errorf("accessor %d runs past the end of buffer view %d", index, viewIndex)
+	}
+
+	for i := 0; i < accessor.Count; i++ {
+		element := data[accessor.ByteOffset+i*stride:]
+		for c := 0; c < components; c++ {
+			values[i*components+c] = gltfComponent(element[c*size:], accessor.ComponentType, accessor.Normalized)
+		}
+	}
+	return values, nil
+}
+
+func gltfComponent(b []byte, componentType int, normalized bool) float64 {
+	var value, scale float64
+	switch componentType {
+	case gltfByte:
+		value, scale = float64(int8(b[0])), 127
+	case gltfUnsignedByte:
+		value, scale = float64(b[0]), 255
+	case gltfShort:
+		value, scale = float64(int16(binary.LittleEndian.Uint16(b))), 32767
+	case gltfUnsignedShort:
+		value, scale = float64(binary.LittleEndian.Uint16(b)), 65535
+	case gltfUnsignedInt:
+		value, scale = float64(binary.LittleEndian.Uint32(b)), 4294967295
+	default:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+
+	if !normalized {
+		return value
+	}
+	return math.Max(value/scale, -1)
+}
+
+// ReadGLTF reads the triangles out of a .gltf file with embedded buffers or a
+// binary .glb file.
+//
+// Every triangle primitive of every mesh is read, ignoring node transforms.
+// Normals are read from NORMAL, or from GLTFOctNormalAttribute when there is
+// no NORMAL, and texture coordinates from TEXCOORD_0. Quantized normals are
+// renormalized, the same as a shader would.
+func ReadGLTF(r io.Reader) (Mesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Mesh{}, err
+	}
+
+	jsonChunk, binChunk := data, []byte(nil)
+	if len(data) >= 4 && binary.LittleEndian.Uint32(data) == glbMagic {
+		if jsonChunk, binChunk, err = splitGLB(data); err != nil {
+			return Mesh{}, err
+		}
+	}
+
+	doc := gltfDocument{}
+	if err := json.Unmarshal(jsonChunk, &doc); err != nil {
+		return Mesh{}, fmt.Errorf("not a glTF file: %w", err)
+	}
+	if !strings.HasPrefix(doc.Asset.Version, "2.") {
+		return Mesh{}, fmt.Errorf("unsupported glTF version %q", doc.Asset.Version)
+	}
+	for _, extension := range doc.ExtensionsRequired {
+		if extension != gltfQuantizationExtension {
+			return Mesh{}, fmt.Errorf("unsupported required extension %q", extension)
+		}
+	}
+
+	buffers, err := loadGLTFBuffers(doc, binChunk)
+	if err != nil {
+		return Mesh{}, err
+	}
+
+	mesh := Mesh{
+		Vertices:  make([]vector.Vector3, 0),
+		TexCoords: make([]vector.Vector2, 0),
+		Normals:   make([]vector.Vector3, 0),
+		Triangles: make([]Triangle, 0),
+	}
+	for m, gltfMesh := range doc.Meshes {
+		for p, primitive := range gltfMesh.Primitives {
+			if primitive.Mode != nil && *primitive.Mode != gltfTriangles {
+				continue
+			}
+			if err := readGLTFPrimitive(doc, buffers, primitive, &mesh); err != nil {
+				return Mesh{}, fmt.Errorf("mesh %d, primitive %d: %w", m, p, err)
+			}
+		}
+	}
+	return mesh, nil
+}
+
+func readGLTFPrimitive(doc gltfDocument, buffers [][]byte, primitive gltfPrimitive, mesh *Mesh) error {
+	positionAccessor, ok := primitive.Attributes["POSITION"]
+	if !ok {
+		return errors.New("missing POSITION")
+	}
+	positions, err := readGLTFAccessor(doc, buffers, positionAccessor, "VEC3")
+	if err != nil {
+		return err
+	}
+	count := len(positions) / 3
+
+	vertexBase, normalBase, texCoordBase := len(mesh.Vertices), len(mesh.Normals), len(mesh.TexCoords)
+	for i := 0; i < count; i++ {
+		mesh.Vertices = append(mesh.Vertices, vector.NewVector3(positions[i*3], positions[i*3+1], positions[i*3+2]))
+	}
+
+	hasNormals := false
+	if accessor, ok := primitive.Attributes["NORMAL"]; ok {
+		normals, err := readGLTFAccessor(doc, buffers, accessor, "VEC3")
+		if err != nil {
+			return err
+		}
+		if len(normals) != len(positions) {
+			return fmt.Errorf("%d normals for %d vertices", len(normals)/3, count)
+		}
+		for i := 0; i < count; i++ {
+			n := vector.NewVector3(normals[i*3], normals[i*3+1], normals[i*3+2])
+			if length := n.Length(); length > 0 {
+				n = n.DivByConstant(length)
+			}
+			mesh.Normals = append(mesh.Normals, n)
+		}
+		hasNormals = true
+	} else if accessor, ok := primitive.Attributes[GLTFOctNormalAttribute]; ok {
+		uvs, err := readGLTFAccessor(doc, buffers, accessor, "VEC2")
+		if err != nil {
+			return err
+		}
+		if len(uvs)/2 != count {
+			return fmt.Errorf("%d normals for %d vertices", len(uvs)/2, count)
+		}
+		for i := 0; i < count; i++ {
+			mesh.Normals = append(mesh.Normals, unitpacking.FromOctUV(vector.NewVector2(uvs[i*2], uvs[i*2+1])))
+		}
+		hasNormals = true
+	}
+
+	hasTexCoords := false
+	if accessor, ok := primitive.Attributes["TEXCOORD_0"]; ok {
+		texCoords, err := readGLTFAccessor(doc, buffers, accessor, "VEC2")
+		if err != nil {
+			return err
+		}
+		if len(texCoords)/2 != count {
+			return fmt.Errorf("%d texture coordinates for %d vertices", len(texCoords)/2, count)
+		}
+		for i := 0; i < count; i++ {
+			mesh.TexCoords = append(mesh.TexCoords, vector.NewVector2(texCoords[i*2], texCoords[i*2+1]))
+		}
+		hasTexCoords = true
+	}
+
+	// Primitives without indices draw their vertices in order
+	var indices []float64
+	if primitive.Indices != nil {
+		if indices, err = readGLTFAccessor(doc, buffers, *primitive.Indices, "SCALAR"); err != nil {
+			return err
+		}
+	} else {
+		indices = make([]float64, count)
+		for i := range indices {
+			indices[i] = float64(i)
+		}
+	}
+	if len(indices)%3 != 0 {
+		return fmt.Errorf("%d indices is not a whole number of triangles", len(indices))
+	}
+
+	for t := 0; t < len(indices); t += 3 {
+		tri := Triangle{}
+		for c := range tri {
+			index := int(indices[t+c])
+			if index < 0 || index >= count {
+				return fmt.Errorf("vertex index %d is out of range of %d vertices", index, count)
+			}
+			tri[c] = Corner{Vertex: vertexBase + index, TexCoord: -1, Normal: -1}
+			if hasNormals {
+				tri[c].Normal = normalBase + index
+			}
+			if hasTexCoords {
+				tri[c].TexCoord = texCoordBase + index
+			}
+		}
+		mesh.Triangles = append(mesh.Triangles, tri)
+	}
+	return nil
+}
@@ -0,0 +1,290 @@
+package meshio_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/mango"
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/meshio"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func gltfQuad() (mango.Mesh, []vector.Vector3) {
+	mesh := mango.NewMesh(
+		[]vector.Vector3{
+			vector.NewVector3(0, 0, 0),
+			vector.NewVector3(1, 0, 0),
+			vector.NewVector3(1, 1, 0.5),
+			vector.NewVector3(0, 1, -0.25),
+		},
+		[]mango.Tri{mango.NewTri(0, 1, 2), mango.NewTri(0, 2, 3)},
+	)
+	normals := []vector.Vector3{
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(0, 0.6, 0.8),
+		vector.NewVector3(-0.48, 0.6, -0.64),
+		vector.NewVector3(1, 2, 3).Normalized(),
+	}
+	return mesh, normals
+}
+
+func TestWriteGLTF_RoundTrip(t *testing.T) {
+	mesh, normals := gltfQuad()
+	writers := map[string]func(*bytes.Buffer, meshio.GLTFNormalEncoding) error{
+		"glb": func(buf *bytes.Buffer, encoding meshio.GLTFNormalEncoding) error {
+			return meshio.WriteGLB(buf, mesh, normals, encoding)
+		},
+		"gltf": func(buf *bytes.Buffer, encoding meshio.GLTFNormalEncoding) error {
+			return meshio.WriteGLTF(buf, mesh, normals, encoding)
+		},
+	}
+
+	for format, write := range writers {
+		for _, encoding := range meshio.GLTFNormalEncodings() {
+			t.Run(format+" "+encoding.String(), func(t *testing.T) {
+				buf := bytes.Buffer{}
+				if !assert.NoError(t, write(&buf, encoding)) {
+					return
+				}
+
+				read, err := meshio.ReadGLTF(&buf)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				assert.Equal(t, mesh.Vertices(), read.Vertices)
+				assert.Empty(t, read.TexCoords)
+				assert.Equal(t, []meshio.Triangle{
+					{corner(0, -1, 0), corner(1, -1, 1), corner(2, -1, 2)},
+					{corner(0, -1, 0), corner(2, -1, 2), corner(3, -1, 3)},
+				}, read.Triangles)
+
+				if !assert.Len(t, read.Normals, len(normals)) {
+					return
+				}
+				for i, n := range normals {
+					switch encoding {
+					case meshio.GLTFNormalsOct16:
+						assert.Equal(t, unitpacking.UnpackOct16Uint16(unitpacking.PackOct16Uint16(n)), read.Normals[i])
+					case meshio.GLTFNormalsOct32:
+						assert.Equal(t, unitpacking.UnpackOct32Uint32(unitpacking.PackOct32Uint32(n)), read.Normals[i])
+					case meshio.GLTFNormalsByte:
+						assert.Less(t, unitpacking.AngularError(n, read.Normals[i]), 0.01)
+					default:
+						assert.Less(t, unitpacking.AngularError(n, read.Normals[i]), 1e-4)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestWriteGLB_Layout(t *testing.T) {
+	mesh, normals := gltfQuad()
+	for encoding, expected := range map[meshio.GLTFNormalEncoding]struct {
+		attribute     string
+		componentType int
+		stride        int
+		quantized     bool
+	}{
+		meshio.GLTFNormalsFloat: {"NORMAL", 5126, 12, false},
+		meshio.GLTFNormalsByte:  {"NORMAL", 5120, 4, true},
+		meshio.GLTFNormalsShort: {"NORMAL", 5122, 8, true},
+		meshio.GLTFNormalsOct16: {"_NORMAL_OCT", 5120, 4, false},
+		meshio.GLTFNormalsOct32: {"_NORMAL_OCT", 5122, 4, false},
+	} {
+		t.Run(encoding.String(), func(t *testing.T) {
+			buf := bytes.Buffer{}
+			if !assert.NoError(t, meshio.WriteGLB(&buf, mesh, normals, encoding)) {
+				return
+			}
+
+			glb := buf.Bytes()
+			assert.Equal(t, "glTF", string(glb[:4]))
+			assert.Equal(t, uint32(2), binary.LittleEndian.Uint32(glb[4:]))
+			assert.Equal(t, uint32(len(glb)), binary.LittleEndian.Uint32(glb[8:]))
+			assert.Zero(t, len(glb)%4)
+
+			jsonLength := binary.LittleEndian.Uint32(glb[12:])
+			doc := struct {
+				ExtensionsRequired []string `json:"extensionsRequired"`
+				Meshes             []struct {
+					Primitives []struct {
+						Attributes map[string]int `json:"attributes"`
+					} `json:"primitives"`
+				} `json:"meshes"`
+				Accessors []struct {
+					BufferView    int  `json:"bufferView"`
+					ComponentType int  `json:"componentType"`
+					Normalized    bool `json:"normalized"`
+				} `json:"accessors"`
+				BufferViews []struct {
+					ByteOffset int `json:"byteOffset"`
+					ByteLength int `json:"byteLength"`
+					ByteStride int `json:"byteStride"`
+				} `json:"bufferViews"`
+			}{}
+			if !assert.NoError(t, json.Unmarshal(glb[20:20+jsonLength], &doc)) {
+				return
+			}
+
+			if expected.quantized {
+				assert.Equal(t, []string{"KHR_mesh_quantization"}, doc.ExtensionsRequired)
+			} else {
+				assert.Empty(t, doc.ExtensionsRequired)
+			}
+
+			attributes := doc.Meshes[0].Primitives[0].Attributes
+			assert.Len(t, attributes, 2)
+			normalAccessor, ok := attributes[expected.attribute]
+			if !assert.True(t, ok) {
+				return
+			}
+
+			accessor := doc.Accessors[normalAccessor]
+			view := doc.BufferViews[accessor.BufferView]
+			assert.Equal(t, expected.componentType, accessor.ComponentType)
+			assert.Equal(t, expected.componentType != 5126, accessor.Normalized)
+			assert.Equal(t, expected.stride, view.ByteStride)
+			assert.Equal(t, expected.stride*len(normals), view.ByteLength)
+			for _, view := range doc.BufferViews {
+				assert.Zero(t, view.ByteOffset%4)
+			}
+		})
+	}
+}
+
+func TestEncodeGLTFNormals(t *testing.T) {
+	n := vector.NewVector3(-0.48, 0.6, -0.64)
+
+	data, err := meshio.EncodeGLTFNormals([]vector.Vector3{n}, meshio.GLTFNormalsByte)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{byte(0x100 - 61), 76, byte(0x100 - 81), 0}, data)
+	}
+
+	// Oct codes only lose their offset
+	data, err = meshio.EncodeGLTFNormals([]vector.Vector3{n}, meshio.GLTFNormalsOct16)
+	if assert.NoError(t, err) {
+		code := unitpacking.PackOct16Uint16(n)
+		assert.Equal(t, []byte{byte(code>>8) - 128, byte(code) - 128, 0, 0}, data)
+	}
+
+	_, err = meshio.EncodeGLTFNormals([]vector.Vector3{n}, meshio.GLTFNormalEncoding(99))
+	assert.Error(t, err)
+}
+
+func TestReadGLTF_Interleaved(t *testing.T) {
+	// Positions and normals share a buffer view, texture coordinates are
+	// normalized unsigned bytes and there are no indices
+	buf := bytes.Buffer{}
+	for _, v := range [][6]float32{
+		{0, 0, 0, 0, 0, 2},
+		{1, 0, 0, 0, 0, 1},
+		{0, 1, 0, 0, 0, 1},
+	} {
+		binary.Write(&buf, binary.LittleEndian, v)
+	}
+	buf.Write([]byte{0, 0, 255, 0, 0, 255})
+
+	data := "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	mesh, err := meshio.ReadGLTF(strings.NewReader(`{
+		"asset": {"version": "2.0"},
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0, "NORMAL": 1, "TEXCOORD_0": 2}}]}],
+		"accessors": [
+			{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"},
+			{"bufferView": 0, "byteOffset": 12, "componentType": 5126, "count": 3, "type": "VEC3"},
+			{"bufferView": 1, "componentType": 5121, "normalized": true, "count": 3, "type": "VEC2"}
+		],
+		"bufferViews": [
+			{"buffer": 0, "byteLength": 72, "byteStride": 24},
+			{"buffer": 0, "byteOffset": 72, "byteLength": 6}
+		],
+		"buffers": [{"uri": "` + data + `", "byteLength": 78}]
+	}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []vector.Vector3{vector.NewVector3(0, 0, 0), vector.NewVector3(1, 0, 0), vector.NewVector3(0, 1, 0)}, mesh.Vertices)
+	assert.Equal(t, []vector.Vector3{vector.NewVector3(0, 0, 1), vector.NewVector3(0, 0, 1), vector.NewVector3(0, 0, 1)}, mesh.Normals)
+	assert.Equal(t, []vector.Vector2{vector.NewVector2(0, 0), vector.NewVector2(1, 0), vector.NewVector2(0, 1)}, mesh.TexCoords)
+	assert.Equal(t, []meshio.Triangle{{corner(0, 0, 0), corner(1, 1, 1), corner(2, 2, 2)}}, mesh.Triangles)
+}
+
+func TestReadGLTF_Errors(t *testing.T) {
+	positions := `"accessors": [{"bufferView": 0, "componentType": 5126, "count": 3, "type": "VEC3"}],
+		"bufferViews": [{"buffer": 0, "byteLength": 36}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}]`
+
+	for name, tc := range map[string]struct {
+		gltf    string
+		message string
+	}{
+		"not json":           {"solid", "not a glTF file"},
+		"version":            {`{"asset": {"version": "1.0"}}`, "unsupported glTF version \"1.0\""},
+		"extension":          {`{"asset": {"version": "2.0"}, "extensionsRequired": ["KHR_draco_mesh_compression"]}`, "unsupported required extension"},
+		"external buffer":    {`{"asset": {"version": "2.0"}, "buffers": [{"uri": "mesh.bin", "byteLength": 4}]}`, "buffer 0: external file \"mesh.bin\" is not supported"},
+		"short buffer":       {`{"asset": {"version": "2.0"}, "buffers": [{"uri": "data:application/octet-stream;base64,AAAA", "byteLength": 4}]}`, "buffer 0: expected 4 bytes, got 3"},
+		"no position":        {`{"asset": {"version": "2.0"}, "meshes": [{"primitives": [{"attributes": {}}]}]}`, "mesh 0, primitive 0: missing POSITION"},
+		"missing accessor":   {`{"asset": {"version": "2.0"}, "meshes": [{"primitives": [{"attributes": {"POSITION": 3}}]}]}`, "accessor 3 does not exist"},
+		"past buffer":        {`{"asset": {"version": "2.0"}, "buffers": [{"uri": "data:application/octet-stream;base64,AAAA", "byteLength": 3}], ` + positions + `}`, "buffer view 0 runs past the end of buffer 0"},
+		"wrong type":         {`{"asset": {"version": "2.0"}, "accessors": [{"componentType": 5126, "count": 3, "type": "VEC2"}], "meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}]}`, "accessor 0: expected VEC3, got VEC2"},
+		"partial triangle":   {`{"asset": {"version": "2.0"}, "accessors": [{"componentType": 5126, "count": 4, "type": "VEC3"}], "meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}]}`, "4 indices is not a whole number of triangles"},
+		"index out of range": {`{"asset": {"version": "2.0"}, "accessors": [{"componentType": 5126, "count": 3, "type": "VEC3"}, {"componentType": 5123, "count": 3, "type": "SCALAR", "bufferView": 0}], "bufferViews": [{"buffer": 0, "byteLength": 6}], "buffers": [{"uri": "data:application/octet-stream;base64,AAABAAMA", "byteLength": 6}], "meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "indices": 1}]}]}`, "vertex index 3 is out of range of 3 vertices"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := meshio.ReadGLTF(strings.NewReader(tc.gltf))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+
+	mesh, normals := gltfQuad()
+	buf := bytes.Buffer{}
+	if assert.NoError(t, meshio.WriteGLB(&buf, mesh, normals, meshio.GLTFNormalsOct16)) {
+		glb := buf.Bytes()
+		_, err := meshio.ReadGLTF(bytes.NewReader(glb[:len(glb)-4]))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "GLB claims")
+		}
+	}
+}
+
+func TestWriteGLTF_Errors(t *testing.T) {
+	mesh, normals := gltfQuad()
+	buf := bytes.Buffer{}
+
+	assert.Error(t, meshio.WriteGLB(&buf, mesh, normals[:3], meshio.GLTFNormalsFloat))
+	assert.Error(t, meshio.WriteGLB(&buf, mango.NewEmptyMesh(), nil, meshio.GLTFNormalsFloat))
+	assert.Error(t, meshio.WriteGLTF(&buf, mango.NewMesh(mesh.Vertices(), []mango.Tri{mango.NewTri(0, 1, 4)}), nil, meshio.GLTFNormalsFloat))
+	assert.Error(t, meshio.WriteGLTF(&buf, mesh, normals, meshio.GLTFNormalEncoding(99)))
+
+	// Normals are optional
+	assert.NoError(t, meshio.WriteGLB(&buf, mesh, nil, meshio.GLTFNormalsOct16))
+}
+
+func TestReadFile_GLB(t *testing.T) {
+	mesh, normals := gltfQuad()
+	path := filepath.Join(t.TempDir(), "quad.GLB")
+	f, err := os.Create(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, meshio.WriteGLB(f, mesh, normals, meshio.GLTFNormalsShort))
+	assert.NoError(t, f.Close())
+
+	read, err := meshio.ReadFile(path)
+	if assert.NoError(t, err) {
+		assert.Len(t, read.Triangles, 2)
+		assert.True(t, read.HasNormals())
+	}
+}
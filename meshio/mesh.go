@@ -0,0 +1,71 @@
+// Package meshio reads triangle meshes from common 3D file formats, keeping
+// the normals they were authored with so they can be packed as is, and writes
+// them as glTF with packed normals.
+package meshio
+
+import (
+	"github.com/EliCDavis/mango"
+	"github.com/EliCDavis/vector"
+)
+
+// Corner is one corner of a triangle, indexing into each of a mesh's pools.
+// Indices are -1 when the corner has no texture coordinate or normal.
+type Corner struct {
+	Vertex   int
+	TexCoord int
+	Normal   int
+}
+
+// Triangle is three corners in counter clockwise order.
+type Triangle [3]Corner
+
+// Mesh is a triangle mesh whose corners index into separate pools of
+// vertices, texture coordinates and normals, the way OBJ files store them.
+// The same vertex can have a different normal at each corner it appears in,
+// like along a hard edge.
+type Mesh struct {
+	Vertices  []vector.Vector3
+	TexCoords []vector.Vector2
+	Normals   []vector.Vector3
+	Triangles []Triangle
+}
+
+// HasNormals reports whether every corner of every triangle has a normal.
+func (m Mesh) HasNormals() bool {
+	if len(m.Triangles) == 0 {
+		return false
+	}
+	for _, tri := range m.Triangles {
+		for _, corner := range tri {
+			if corner.Normal < 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CornerNormals returns the normal of every corner with one, in triangle
+// order. Normals shared between corners are repeated, the same as they would
+// be once the mesh is unwelded for the GPU.
+func (m Mesh) CornerNormals() []vector.Vector3 {
+	normals := make([]vector.Vector3, 0, len(m.Triangles)*3)
+	for _, tri := range m.Triangles {
+		for _, corner := range tri {
+			if corner.Normal >= 0 {
+				normals = append(normals, m.Normals[corner.Normal])
+			}
+		}
+	}
+	return normals
+}
+
+// Mango converts the mesh's vertices and triangles to a mango.Mesh, dropping
+// texture coordinates and normals.
+func (m Mesh) Mango() mango.Mesh {
+	tris := make([]mango.Tri, len(m.Triangles))
+	for i, tri := range m.Triangles {
+		tris[i] = mango.NewTri(tri[0].Vertex, tri[1].Vertex, tri[2].Vertex)
+	}
+	return mango.NewMesh(m.Vertices, tris)
+}
@@ -0,0 +1,185 @@
+package meshio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+)
+
+// ReadOBJ reads the geometry out of a Wavefront OBJ file.
+//
+// Faces can have any number of vertices, and are triangulated as a fan
+// around their first vertex, which is correct for convex polygons. Corners
+// can be written as v, v/vt, v//vn or v/vt/vn, and negative indices count
+// back from the most recently read element. Materials, groups, smoothing
+// groups, lines and points are ignored.
+func ReadOBJ(r io.Reader) (Mesh, error) {
+	mesh := Mesh{
+		Vertices:  make([]vector.Vector3, 0),
+		TexCoords: make([]vector.Vector2, 0),
+		Normals:   make([]vector.Vector3, 0),
+		Triangles: make([]Triangle, 0),
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	lineNumber := 0
+	line := ""
+	for scanner.Scan() {
+		lineNumber++
+
+		// A trailing backslash continues the statement on the next line
+		text := scanner.Text()
+		if strings.HasSuffix(text, "\\") {
+			line += strings.TrimSuffix(text, "\\") + " "
+			continue
+		}
+		line += text
+
+		if err := readOBJStatement(&mesh, line); err != nil {
+			return Mesh{}, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		line = ""
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Mesh{}, err
+	}
+
+	if err := readOBJStatement(&mesh, line); err != nil {
+		return Mesh{}, fmt.Errorf("line %d: %w", lineNumber, err)
+	}
+	return mesh, nil
+}
+
+func readOBJStatement(mesh *Mesh, line string) error {
+	if comment := strings.IndexByte(line, '#'); comment >= 0 {
+		line = line[:comment]
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch fields[0] {
+	case "v":
+		// Some exporters append a vertex color after the position
+		components, err := parseFloats(fields[1:], 3, 7)
+		if err != nil {
+			return fmt.Errorf("vertex: %w", err)
+		}
+		mesh.Vertices = append(mesh.Vertices, vector.NewVector3(components[0], components[1], components[2]))
+
+	case "vt":
+		components, err := parseFloats(fields[1:], 1, 3)
+		if err != nil {
+			return fmt.Errorf("texture coordinate: %w", err)
+		}
+		components = append(components, 0)
+		mesh.TexCoords = append(mesh.TexCoords, vector.NewVector2(components[0], components[1]))
+
+	case "vn":
+		components, err := parseFloats(fields[1:], 3, 3)
+		if err != nil {
+			return fmt.Errorf("normal: %w", err)
+		}
+		mesh.Normals = append(mesh.Normals, vector.NewVector3(components[0], components[1], components[2]))
+
+	case "f":
+		if len(fields) < 4 {
+			return fmt.Errorf("face needs at least 3 vertices, got %d", len(fields)-1)
+		}
+
+		corners := make([]Corner, len(fields)-1)
+		for i, field := range fields[1:] {
+			corner, err := parseOBJCorner(mesh, field)
+			if err != nil {
+				return fmt.Errorf("face vertex %d: %w", i+1, err)
+			}
+			corners[i] = corner
+		}
+
+		for i := 1; i < len(corners)-1; i++ {
+			mesh.Triangles = append(mesh.Triangles, Triangle{corners[0], corners[i], corners[i+1]})
+		}
+	}
+
+	return nil
+}
+
+func parseFloats(fields []string, min, max int) ([]float64, error) {
+	if len(fields) < min || len(fields) > max {
+		if min == max {
+			return nil, fmt.Errorf("expected %d components, got %d", min, len(fields))
+		}
+		return nil, fmt.Errorf("expected %d to %d components, got %d", min, max, len(fields))
+	}
+
+	components := make([]float64, len(fields))
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("component %d: %w", i+1, err)
+		}
+		components[i] = f
+	}
+	return components, nil
+}
+
+// parseOBJCorner reads a single v/vt/vn corner of a face.
+func parseOBJCorner(mesh *Mesh, field string) (Corner, error) {
+	parts := strings.Split(field, "/")
+	if len(parts) > 3 {
+		return Corner{}, fmt.Errorf("unable to parse %q", field)
+	}
+
+	corner := Corner{Vertex: -1, TexCoord: -1, Normal: -1}
+	pools := []struct {
+		name  string
+		index *int
+		count int
+	}{
+		{"vertex", &corner.Vertex, len(mesh.Vertices)},
+		{"texture coordinate", &corner.TexCoord, len(mesh.TexCoords)},
+		{"normal", &corner.Normal, len(mesh.Normals)},
+	}
+
+	for i, part := range parts {
+		if part == "" && i > 0 {
+			continue
+		}
+
+		index, err := resolveOBJIndex(part, pools[i].count)
+		if err != nil {
+			return Corner{}, fmt.Errorf("%s index: %w", pools[i].name, err)
+		}
+		*pools[i].index = index
+	}
+	return corner, nil
+}
+
+// resolveOBJIndex converts a 1 based OBJ index into a 0 based one. Negative
+// indices are relative to the end of the elements read so far, with -1 being
+// the last.
+func resolveOBJIndex(str string, count int) (int, error) {
+	index, err := strconv.Atoi(str)
+	if err != nil {
+		return -1, fmt.Errorf("unable to parse %q", str)
+	}
+
+	resolved := index - 1
+	if index < 0 {
+		resolved = count + index
+	}
+
+	if index == 0 || resolved < 0 || resolved >= count {
+		return -1, fmt.Errorf("%d is out of range of the %d read so far", index, count)
+	}
+	return resolved, nil
+}
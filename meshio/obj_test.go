@@ -0,0 +1,179 @@
+package meshio_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/meshio"
+	"github.com/stretchr/testify/assert"
+)
+
+func corner(v, vt, vn int) meshio.Corner {
+	return meshio.Corner{Vertex: v, TexCoord: vt, Normal: vn}
+}
+
+func TestReadOBJ_Triangle(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader(`
+# a single triangle
+v 0 0 0
+v 1 0 0
+v 0 1 0
+f 1 2 3
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []vector.Vector3{
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(1, 0, 0),
+		vector.NewVector3(0, 1, 0),
+	}, mesh.Vertices)
+	assert.Equal(t, []meshio.Triangle{{corner(0, -1, -1), corner(1, -1, -1), corner(2, -1, -1)}}, mesh.Triangles)
+	assert.False(t, mesh.HasNormals())
+	assert.Empty(t, mesh.CornerNormals())
+}
+
+func TestReadOBJ_NGons(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader(`
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v -1 1 0
+f 1 2 3 4 5
+f 1 2 3 4
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []meshio.Triangle{
+		{corner(0, -1, -1), corner(1, -1, -1), corner(2, -1, -1)},
+		{corner(0, -1, -1), corner(2, -1, -1), corner(3, -1, -1)},
+		{corner(0, -1, -1), corner(3, -1, -1), corner(4, -1, -1)},
+		{corner(0, -1, -1), corner(1, -1, -1), corner(2, -1, -1)},
+		{corner(0, -1, -1), corner(2, -1, -1), corner(3, -1, -1)},
+	}, mesh.Triangles)
+}
+
+func TestReadOBJ_CornerFormats(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader(`
+v 0 0 0
+v 1 0 0
+v 0 1 0
+vt 0 0
+vt 1 0
+vt 0.5
+vn 0 0 1
+vn 0 0.6 0.8
+f 1/1 2/2 3/3
+f 1//2 2//1 3//2
+f 1/3/1 2/2/2 3/1/1
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []vector.Vector2{
+		vector.NewVector2(0, 0),
+		vector.NewVector2(1, 0),
+		vector.NewVector2(0.5, 0),
+	}, mesh.TexCoords)
+
+	assert.Equal(t, []meshio.Triangle{
+		{corner(0, 0, -1), corner(1, 1, -1), corner(2, 2, -1)},
+		{corner(0, -1, 1), corner(1, -1, 0), corner(2, -1, 1)},
+		{corner(0, 2, 0), corner(1, 1, 1), corner(2, 0, 0)},
+	}, mesh.Triangles)
+
+	// The first face has no normals
+	assert.False(t, mesh.HasNormals())
+	assert.Len(t, mesh.CornerNormals(), 6)
+}
+
+func TestReadOBJ_NegativeIndices(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader(`
+v 0 0 0
+v 1 0 0
+v 0 1 0
+vn 0 0 1
+f -3//-1 -2//-1 -1//-1
+v 1 1 0
+vn 1 0 0
+f -3//-2 -1//-1 -2//-2
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []meshio.Triangle{
+		{corner(0, -1, 0), corner(1, -1, 0), corner(2, -1, 0)},
+		{corner(1, -1, 0), corner(3, -1, 1), corner(2, -1, 0)},
+	}, mesh.Triangles)
+	assert.True(t, mesh.HasNormals())
+	assert.Equal(t, []vector.Vector3{
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(0, 0, 1),
+		vector.NewVector3(1, 0, 0),
+		vector.NewVector3(0, 0, 1),
+	}, mesh.CornerNormals())
+}
+
+func TestReadOBJ_Whitespace(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader("o thing\r\ng group\nusemtl red\ns 1\nv\t0 0 0 1 0 0\nv  1  0  0 # trailing comment\nv 0 1 0\nf 1 2 \\\n 3\nl 1 2"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Len(t, mesh.Vertices, 3)
+	assert.Equal(t, []meshio.Triangle{{corner(0, -1, -1), corner(1, -1, -1), corner(2, -1, -1)}}, mesh.Triangles)
+}
+
+func TestReadOBJ_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		obj     string
+		message string
+	}{
+		"bad x":              {"v a 0 0", "line 1: vertex: component 1"},
+		"bad y":              {"v 0 b 0", "line 1: vertex: component 2"},
+		"bad z":              {"v 0 0 c", "line 1: vertex: component 3"},
+		"short vertex":       {"v 0 0", "line 1: vertex: expected 3 to 7 components, got 2"},
+		"short normal":       {"vn 0 1", "line 1: normal: expected 3 components, got 2"},
+		"two vertex face":    {"v 0 0 0\nv 1 0 0\nf 1 2", "line 3: face needs at least 3 vertices, got 2"},
+		"zero index":         {"v 0 0 0\nf 0 1 1", "line 2: face vertex 1: vertex index: 0 is out of range"},
+		"index past end":     {"v 0 0 0\nf 1 1 2", "line 2: face vertex 3: vertex index: 2 is out of range"},
+		"negative past end":  {"v 0 0 0\nf 1 1 -2", "line 2: face vertex 3: vertex index: -2 is out of range"},
+		"missing normal":     {"v 0 0 0\nf 1//1 1//1 1//1", "line 2: face vertex 1: normal index: 1 is out of range"},
+		"bad normal index":   {"v 0 0 0\nvn 0 0 1\nf 1//x 1//1 1//1", "line 3: face vertex 1: normal index: unable to parse \"x\""},
+		"bad y index":        {"v 0 0 0\nf 1 y 1", "line 2: face vertex 2: vertex index: unable to parse \"y\""},
+		"too many slashes":   {"v 0 0 0\nf 1/1/1/1 1 1", "line 2: face vertex 1: unable to parse"},
+		"missing vertex":     {"v 0 0 0\nf /1 1 1", "line 2: face vertex 1: vertex index: unable to parse \"\""},
+		"continued error":    {"v 0 0 0\nf 1 1 \\\n 5", "line 3: face vertex 3"},
+		"texture coordinate": {"vt 0 0 0 0", "line 1: texture coordinate: expected 1 to 3 components, got 4"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := meshio.ReadOBJ(strings.NewReader(tc.obj))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+}
+
+func TestMesh_Mango(t *testing.T) {
+	mesh, err := meshio.ReadOBJ(strings.NewReader("v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	converted := mesh.Mango()
+	assert.Equal(t, mesh.Vertices, converted.Vertices())
+	if assert.Len(t, converted.Triangles(), 2) {
+		tri := converted.Triangles()[1]
+		assert.Equal(t, []int{0, 2, 3}, []int{tri.P1(), tri.P2(), tri.P3()})
+	}
+}
@@ -0,0 +1,376 @@
+package meshio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+)
+
+type plyFormat int
+
+const (
+	plyASCII plyFormat = iota
+	plyBinaryLittleEndian
+	plyBinaryBigEndian
+)
+
+// plyType is one of PLY's scalar types, named by its size in bytes and
+// whether it is a float or signed.
+type plyType struct {
+	size   int
+	float  bool
+	signed bool
+}
+
+var plyTypes = map[string]plyType{
+	"char":    {1, false, true},
+	"int8":    {1, false, true},
+	"uchar":   {1, false, false},
+	"uint8":   {1, false, false},
+	"short":   {2, false, true},
+	"int16":   {2, false, true},
+	"ushort":  {2, false, false},
+	"uint16":  {2, false, false},
+	"int":     {4, false, true},
+	"int32":   {4, false, true},
+	"uint":    {4, false, false},
+	"uint32":  {4, false, false},
+	"float":   {4, true, true},
+	"float32": {4, true, true},
+	"double":  {8, true, true},
+	"float64": {8, true, true},
+}
+
+type plyProperty struct {
+	name      string
+	valueType plyType
+
+	// list properties start with a count of countType, followed by that
+	// many values
+	list      bool
+	countType plyType
+}
+
+type plyElement struct {
+	name       string
+	count      int
+	properties []plyProperty
+}
+
+func (e plyElement) propertyIndex(names ...string) int {
+	for _, name := range names {
+		for i, p := range e.properties {
+			if p.name == name && !p.list {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (e plyElement) listIndex(names ...string) int {
+	for _, name := range names {
+		for i, p := range e.properties {
+			if p.name == name && p.list {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// plyValues reads the scalars that make up the body of a PLY file.
+type plyValues interface {
+	read(t plyType) (float64, error)
+}
+
+type plyASCIIValues struct {
+	in *bufio.Reader
+}
+
+func (v plyASCIIValues) read(t plyType) (float64, error) {
+	word := make([]byte, 0, 16)
+	for {
+		b, err := v.in.ReadByte()
+		if err == io.EOF && len(word) > 0 {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if b == ' ' || b == '\t' || b == '\n' || b == '\r' {
+			if len(word) > 0 {
+				break
+			}
+			continue
+		}
+		word = append(word, b)
+	}
+
+	value, err := strconv.ParseFloat(string(word), 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse %q", word)
+	}
+
+	// Round to the declared precision, so ASCII files read the same as
+	// binary ones
+	if t.float && t.size == 4 {
+		value = float64(float32(value))
+	}
+	return value, nil
+}
+
+type plyBinaryValues struct {
+	in    *bufio.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (v *plyBinaryValues) read(t plyType) (float64, error) {
+	b := v.buf[:t.size]
+	if _, err := io.ReadFull(v.in, b); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case t.float && t.size == 4:
+		return float64(math.Float32frombits(v.order.Uint32(b))), nil
+	case t.float:
+		return math.Float64frombits(v.order.Uint64(b)), nil
+	case t.size == 1 && t.signed:
+		return float64(int8(b[0])), nil
+	case t.size == 1:
+		return float64(b[0]), nil
+	case t.size == 2 && t.signed:
+		return float64(int16(v.order.Uint16(b))), nil
+	case t.size == 2:
+		return float64(v.order.Uint16(b)), nil
+	case t.signed:
+		return float64(int32(v.order.Uint32(b))), nil
+	}
+	return float64(v.order.Uint32(b)), nil
+}
+
+func readPLYHeader(in *bufio.Reader) (plyFormat, []plyElement, error) {
+	magic, err := in.ReadString('\n')
+	if err != nil || strings.TrimSpace(magic) != "ply" {
+		return 0, nil, errors.New("not a PLY file")
+	}
+
+	format := plyFormat(-1)
+	elements := make([]plyElement, 0)
+	for lineNumber := 2; ; lineNumber++ {
+		line, err := in.ReadString('\n')
+		if err != nil {
+			return 0, nil, fmt.Errorf("header ends before end_header: %w", err)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "format":
+			if len(fields) != 3 {
+				return 0, nil, fmt.Errorf("line %d: unable to parse format", lineNumber)
+			}
+			switch fields[1] {
+			case "ascii":
+				format = plyASCII
+			case "binary_little_endian":
+				format = plyBinaryLittleEndian
+			case "binary_big_endian":
+				format = plyBinaryBigEndian
+			default:
+				return 0, nil, fmt.Errorf("line %d: unknown format %q", lineNumber, fields[1])
+			}
+
+		case "element":
+			if len(fields) != 3 {
+				return 0, nil, fmt.Errorf("line %d: unable to parse element", lineNumber)
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return 0, nil, fmt.Errorf("line %d: invalid element count %q", lineNumber, fields[2])
+			}
+			elements = append(elements, plyElement{name: fields[1], count: count})
+
+		case "property":
+			if len(elements) == 0 {
+				return 0, nil, fmt.Errorf("line %d: property before any element", lineNumber)
+			}
+			property, err := parsePLYProperty(fields[1:])
+			if err != nil {
+				return 0, nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			element := &elements[len(elements)-1]
+			element.properties = append(element.properties, property)
+
+		case "end_header":
+			if format < 0 {
+				return 0, nil, errors.New("header is missing its format")
+			}
+			return format, elements, nil
+		}
+	}
+}
+
+func parsePLYProperty(fields []string) (plyProperty, error) {
+	if len(fields) == 4 && fields[0] == "list" {
+		countType, ok := plyTypes[fields[1]]
+		if !ok || countType.float {
+			return plyProperty{}, fmt.Errorf("invalid list count type %q", fields[1])
+		}
+		valueType, ok := plyTypes[fields[2]]
+		if !ok {
+			return plyProperty{}, fmt.Errorf("unknown property type %q", fields[2])
+		}
+		return plyProperty{name: fields[3], valueType: valueType, list: true, countType: countType}, nil
+	}
+
+	if len(fields) != 2 {
+		return plyProperty{}, errors.New("unable to parse property")
+	}
+	valueType, ok := plyTypes[fields[0]]
+	if !ok {
+		return plyProperty{}, fmt.Errorf("unknown property type %q", fields[0])
+	}
+	return plyProperty{name: fields[1], valueType: valueType}, nil
+}
+
+// ReadPLY reads the vertices and faces out of a Stanford PLY file, in ASCII or
+// either binary byte order.
+//
+// Normals (nx, ny, nz) and texture coordinates (u and v, or s and t) are read
+// per vertex, so corners index into every pool with their vertex index. Files
+// without faces, like scanned point clouds, come back with vertices and
+// normals but no triangles. Faces are triangulated as a fan around their
+// first vertex, and any other elements are skipped.
+func ReadPLY(r io.Reader) (Mesh, error) {
+	in := bufio.NewReader(r)
+	format, elements, err := readPLYHeader(in)
+	if err != nil {
+		return Mesh{}, err
+	}
+
+	var values plyValues = plyASCIIValues{in}
+	switch format {
+	case plyBinaryLittleEndian:
+		values = &plyBinaryValues{in: in, order: binary.LittleEndian}
+	case plyBinaryBigEndian:
+		values = &plyBinaryValues{in: in, order: binary.BigEndian}
+	}
+
+	mesh := Mesh{
+		Vertices:  make([]vector.Vector3, 0),
+		TexCoords: make([]vector.Vector2, 0),
+		Normals:   make([]vector.Vector3, 0),
+		Triangles: make([]Triangle, 0),
+	}
+
+	hasNormals, hasTexCoords := false, false
+	faces := make([][]int, 0)
+	for _, element := range elements {
+		position := []int{element.propertyIndex("x"), element.propertyIndex("y"), element.propertyIndex("z")}
+		normal := []int{element.propertyIndex("nx", "normal_x"), element.propertyIndex("ny", "normal_y"), element.propertyIndex("nz", "normal_z")}
+		texCoord := []int{element.propertyIndex("u", "s", "texture_u"), element.propertyIndex("v", "t", "texture_v")}
+		indices := element.listIndex("vertex_indices", "vertex_index")
+
+		isVertex := element.name == "vertex" && position[0] >= 0 && position[1] >= 0 && position[2] >= 0
+		hasNormals = hasNormals || (isVertex && normal[0] >= 0 && normal[1] >= 0 && normal[2] >= 0)
+		hasTexCoords = hasTexCoords || (isVertex && texCoord[0] >= 0 && texCoord[1] >= 0)
+
+		for i := 0; i < element.count; i++ {
+			scalars := make([]float64, len(element.properties))
+			var list []int
+			for p, property := range element.properties {
+				if !property.list {
+					if scalars[p], err = values.read(property.valueType); err != nil {
+						return Mesh{}, plyReadError(element, i, property, err)
+					}
+					continue
+				}
+
+				count, err := values.read(property.countType)
+				if err != nil {
+					return Mesh{}, plyReadError(element, i, property, err)
+				}
+				if count < 0 || count != math.Trunc(count) {
+					return Mesh{}, plyReadError(element, i, property, fmt.Errorf("invalid list length %g", count))
+				}
+
+				// The length comes from the file, so items are appended as
+				// they are read rather than allocated up front
+				items := make([]int, 0)
+				for j := 0; j < int(count); j++ {
+					item, err := values.read(property.valueType)
+					if err != nil {
+						return Mesh{}, plyReadError(element, i, property, err)
+					}
+					items = append(items, int(item))
+				}
+				if p == indices {
+					list = items
+				}
+			}
+
+			if isVertex {
+				mesh.Vertices = append(mesh.Vertices, vector.NewVector3(scalars[position[0]], scalars[position[1]], scalars[position[2]]))
+				if hasNormals {
+					mesh.Normals = append(mesh.Normals, vector.NewVector3(scalars[normal[0]], scalars[normal[1]], scalars[normal[2]]))
+				}
+				if hasTexCoords {
+					mesh.TexCoords = append(mesh.TexCoords, vector.NewVector2(scalars[texCoord[0]], scalars[texCoord[1]]))
+				}
+			}
+
+			if element.name == "face" && indices >= 0 {
+				faces = append(faces, list)
+			}
+		}
+	}
+
+	// Faces can come before vertices, so they are resolved once everything
+	// has been read
+	for i, face := range faces {
+		if len(face) < 3 {
+			return Mesh{}, fmt.Errorf("face %d needs at least 3 vertices, got %d", i, len(face))
+		}
+
+		corners := make([]Corner, len(face))
+		for j, index := range face {
+			if index < 0 || index >= len(mesh.Vertices) {
+				return Mesh{}, fmt.Errorf("face %d: vertex index %d is out of range of %d vertices", i, index, len(mesh.Vertices))
+			}
+			corners[j] = Corner{Vertex: index, TexCoord: -1, Normal: -1}
+			if hasTexCoords {
+				corners[j].TexCoord = index
+			}
+			if hasNormals {
+				corners[j].Normal = index
+			}
+		}
+
+		for j := 1; j < len(corners)-1; j++ {
+			mesh.Triangles = append(mesh.Triangles, Triangle{corners[0], corners[j], corners[j+1]})
+		}
+	}
+
+	return mesh, nil
+}
+
+func plyReadError(element plyElement, index int, property plyProperty, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%s %d, property %s: %w", element.name, index, property.name, err)
+}
@@ -0,0 +1,191 @@
+package meshio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/meshio"
+	"github.com/stretchr/testify/assert"
+)
+
+const plyQuadHeader = `ply
+format %s 1.0
+comment a quad with normals
+element vertex 4
+property float x
+property float y
+property float z
+property float nx
+property float ny
+property float nz
+element face 1
+property list uchar int vertex_indices
+end_header
+`
+
+var plyQuadVertices = [][6]float32{
+	{0, 0, 0, 0, 0, 1},
+	{1, 0, 0, 0, 0.6, 0.8},
+	{1, 1, 0, 0, 0, 1},
+	{0, 1, 0, 0, 0, 1},
+}
+
+func binaryPLYQuad(order binary.ByteOrder, format string) []byte {
+	buf := bytes.Buffer{}
+	buf.WriteString(strings.Replace(plyQuadHeader, "%s", format, 1))
+	for _, v := range plyQuadVertices {
+		binary.Write(&buf, order, v)
+	}
+	buf.WriteByte(4)
+	binary.Write(&buf, order, []int32{0, 1, 2, 3})
+	return buf.Bytes()
+}
+
+func assertPLYQuad(t *testing.T, mesh meshio.Mesh) {
+	assert.Equal(t, []vector.Vector3{
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(1, 0, 0),
+		vector.NewVector3(1, 1, 0),
+		vector.NewVector3(0, 1, 0),
+	}, mesh.Vertices)
+
+	assert.Equal(t, vector.NewVector3(0, float64(float32(0.6)), float64(float32(0.8))), mesh.Normals[1])
+	assert.Empty(t, mesh.TexCoords)
+	assert.Equal(t, []meshio.Triangle{
+		{corner(0, -1, 0), corner(1, -1, 1), corner(2, -1, 2)},
+		{corner(0, -1, 0), corner(2, -1, 2), corner(3, -1, 3)},
+	}, mesh.Triangles)
+	assert.True(t, mesh.HasNormals())
+}
+
+func TestReadPLY_ASCII(t *testing.T) {
+	ply := strings.Replace(plyQuadHeader, "%s", "ascii", 1) + `0 0 0 0 0 1
+1 0 0 0 0.6 0.8
+1 1 0 0 0 1
+0 1 0 0 0 1
+4 0 1 2 3
+`
+	mesh, err := meshio.ReadPLY(strings.NewReader(ply))
+	if assert.NoError(t, err) {
+		assertPLYQuad(t, mesh)
+	}
+}
+
+func TestReadPLY_Binary(t *testing.T) {
+	for format, order := range map[string]binary.ByteOrder{
+		"binary_little_endian": binary.LittleEndian,
+		"binary_big_endian":    binary.BigEndian,
+	} {
+		t.Run(format, func(t *testing.T) {
+			mesh, err := meshio.ReadPLY(bytes.NewReader(binaryPLYQuad(order, format)))
+			if assert.NoError(t, err) {
+				assertPLYQuad(t, mesh)
+			}
+		})
+	}
+}
+
+func TestReadPLY_PointCloud(t *testing.T) {
+	// A scan with extra per point data, a double precision normal and an
+	// element the reader doesn't know about
+	buf := bytes.Buffer{}
+	buf.WriteString(`ply
+format binary_little_endian 1.0
+element camera 1
+property list uchar float matrix
+element vertex 2
+property double x
+property double y
+property double z
+property uchar red
+property double nx
+property double ny
+property double nz
+property short confidence
+end_header
+`)
+	buf.WriteByte(2)
+	binary.Write(&buf, binary.LittleEndian, []float32{1, 2})
+	binary.Write(&buf, binary.LittleEndian, []float64{1, 2, 3})
+	buf.WriteByte(200)
+	binary.Write(&buf, binary.LittleEndian, []float64{0, -1, 0})
+	binary.Write(&buf, binary.LittleEndian, int16(-5))
+	binary.Write(&buf, binary.LittleEndian, []float64{4, 5, 6})
+	buf.WriteByte(100)
+	binary.Write(&buf, binary.LittleEndian, []float64{1, 0, 0})
+	binary.Write(&buf, binary.LittleEndian, int16(7))
+
+	mesh, err := meshio.ReadPLY(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []vector.Vector3{vector.NewVector3(1, 2, 3), vector.NewVector3(4, 5, 6)}, mesh.Vertices)
+	assert.Equal(t, []vector.Vector3{vector.NewVector3(0, -1, 0), vector.NewVector3(1, 0, 0)}, mesh.Normals)
+	assert.Empty(t, mesh.Triangles)
+	assert.False(t, mesh.HasNormals())
+}
+
+func TestReadPLY_TexCoordsAndFacesFirst(t *testing.T) {
+	mesh, err := meshio.ReadPLY(strings.NewReader(`ply
+format ascii 1.0
+element face 1
+property list uchar uint vertex_index
+element vertex 3
+property float x
+property float y
+property float z
+property float s
+property float t
+end_header
+3 2 1 0
+0 0 0 0 0
+1 0 0 1 0
+0 1 0 0 1
+`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []vector.Vector2{vector.NewVector2(0, 0), vector.NewVector2(1, 0), vector.NewVector2(0, 1)}, mesh.TexCoords)
+	assert.Empty(t, mesh.Normals)
+	assert.Equal(t, []meshio.Triangle{{corner(2, 2, -1), corner(1, 1, -1), corner(0, 0, -1)}}, mesh.Triangles)
+}
+
+func TestReadPLY_Errors(t *testing.T) {
+	header := "ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\n"
+	for name, tc := range map[string]struct {
+		ply     string
+		message string
+	}{
+		"not ply":           {"obj\n", "not a PLY file"},
+		"no format":         {"ply\nelement vertex 0\nend_header\n", "missing its format"},
+		"unknown format":    {"ply\nformat binary_middle_endian 1.0\n", "unknown format"},
+		"unknown type":      {"ply\nformat ascii 1.0\nelement vertex 1\nproperty quad x\n", "line 4: unknown property type"},
+		"float count":       {"ply\nformat ascii 1.0\nelement face 1\nproperty list float int vertex_indices\n", "invalid list count type"},
+		"orphan property":   {"ply\nformat ascii 1.0\nproperty float x\n", "property before any element"},
+		"no end":            {header, "header ends before end_header"},
+		"bad value":         {header + "end_header\n0 a 0\n", "vertex 0, property y: unable to parse \"a\""},
+		"truncated":         {header + "end_header\n0 0\n", "vertex 0, property z: unexpected EOF"},
+		"short face":        {header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n2 0 0\n", "face 0 needs at least 3 vertices"},
+		"out of range face": {header + "element face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n3 0 0 1\n", "vertex index 1 is out of range"},
+		"negative length":   {header + "element face 1\nproperty list char int vertex_indices\nend_header\n0 0 0\n-3 0 0 0\n", "invalid list length"},
+		"huge length":       {header + "element face 1\nproperty list uint int vertex_indices\nend_header\n0 0 0\n4294967295 0 0 0\n", "face 0, property vertex_indices: unexpected EOF"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := meshio.ReadPLY(strings.NewReader(tc.ply))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+
+	truncated := binaryPLYQuad(binary.LittleEndian, "binary_little_endian")
+	_, err := meshio.ReadPLY(bytes.NewReader(truncated[:len(truncated)-3]))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "face 0, property vertex_indices: unexpected EOF")
+	}
+}
@@ -0,0 +1,150 @@
+package meshio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/EliCDavis/vector"
+)
+
+const (
+	stlHeaderSize = 80
+	stlFacetSize  = 50
+)
+
+// ReadSTL reads the facets out of a binary or ASCII STL file.
+//
+// STL files don't share vertices between facets, so every facet adds three
+// vertices and its one normal, which all three of its corners index. Normals
+// are returned as written, and many exporters write all zero normals and
+// leave them to be recomputed from the winding order.
+func ReadSTL(r io.Reader) (Mesh, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Mesh{}, err
+	}
+
+	// Binary files can start with "solid" too, so the size is the more
+	// reliable tell
+	if len(data) >= stlHeaderSize+4 {
+		count := binary.LittleEndian.Uint32(data[stlHeaderSize:])
+		if uint64(len(data)) == stlHeaderSize+4+uint64(count)*stlFacetSize {
+			return readBinarySTL(data[stlHeaderSize+4:], int(count)), nil
+		}
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("solid")) {
+		return readASCIISTL(data)
+	}
+	return Mesh{}, fmt.Errorf("not an STL file, or a binary one whose size doesn't match its facet count")
+}
+
+func newSTLMesh(facets int) Mesh {
+	return Mesh{
+		Vertices:  make([]vector.Vector3, 0, facets*3),
+		TexCoords: make([]vector.Vector2, 0),
+		Normals:   make([]vector.Vector3, 0, facets),
+		Triangles: make([]Triangle, 0, facets),
+	}
+}
+
+func addSTLFacet(mesh *Mesh, normal vector.Vector3, vertices [3]vector.Vector3) {
+	n := len(mesh.Normals)
+	v := len(mesh.Vertices)
+	mesh.Normals = append(mesh.Normals, normal)
+	mesh.Vertices = append(mesh.Vertices, vertices[:]...)
+	mesh.Triangles = append(mesh.Triangles, Triangle{
+		{Vertex: v, TexCoord: -1, Normal: n},
+		{Vertex: v + 1, TexCoord: -1, Normal: n},
+		{Vertex: v + 2, TexCoord: -1, Normal: n},
+	})
+}
+
+func readBinarySTL(data []byte, count int) Mesh {
+	readVector := func(b []byte) vector.Vector3 {
+		return vector.NewVector3(
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(b))),
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(b[4:]))),
+			float64(math.Float32frombits(binary.LittleEndian.Uint32(b[8:]))),
+		)
+	}
+
+	mesh := newSTLMesh(count)
+	for i := 0; i < count; i++ {
+		facet := data[i*stlFacetSize:]
+		addSTLFacet(&mesh, readVector(facet), [3]vector.Vector3{
+			readVector(facet[12:]),
+			readVector(facet[24:]),
+			readVector(facet[36:]),
+		})
+	}
+	return mesh
+}
+
+func readASCIISTL(data []byte) (Mesh, error) {
+	mesh := newSTLMesh(0)
+
+	var normal vector.Vector3
+	var vertices [3]vector.Vector3
+	vertexCount := 0
+	inFacet := false
+
+	for i, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "facet":
+			if len(fields) != 5 || fields[1] != "normal" {
+				return Mesh{}, fmt.Errorf("line %d: unable to parse facet", i+1)
+			}
+			n, err := parseSTLVector(fields[2:])
+			if err != nil {
+				return Mesh{}, fmt.Errorf("line %d: facet normal: %w", i+1, err)
+			}
+			normal, vertexCount, inFacet = n, 0, true
+
+		case "vertex":
+			if !inFacet || vertexCount == 3 || len(fields) != 4 {
+				return Mesh{}, fmt.Errorf("line %d: unexpected vertex", i+1)
+			}
+			v, err := parseSTLVector(fields[1:])
+			if err != nil {
+				return Mesh{}, fmt.Errorf("line %d: vertex: %w", i+1, err)
+			}
+			vertices[vertexCount] = v
+			vertexCount++
+
+		case "endfacet":
+			if !inFacet || vertexCount != 3 {
+				return Mesh{}, fmt.Errorf("line %d: facet needs 3 vertices, got %d", i+1, vertexCount)
+			}
+			addSTLFacet(&mesh, normal, vertices)
+			inFacet = false
+		}
+	}
+
+	if inFacet {
+		return Mesh{}, fmt.Errorf("file ends partway through a facet")
+	}
+	return mesh, nil
+}
+
+func parseSTLVector(fields []string) (vector.Vector3, error) {
+	components := make([]float64, 3)
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return vector.Vector3{}, fmt.Errorf("component %d: unable to parse %q", i+1, field)
+		}
+		components[i] = f
+	}
+	return vector.NewVector3(components[0], components[1], components[2]), nil
+}
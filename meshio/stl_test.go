@@ -0,0 +1,141 @@
+package meshio_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/meshio"
+	"github.com/stretchr/testify/assert"
+)
+
+func binarySTL(header string, facets [][12]float32) []byte {
+	buf := bytes.Buffer{}
+	title := make([]byte, 80)
+	copy(title, header)
+	buf.Write(title)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(facets)))
+	for _, f := range facets {
+		binary.Write(&buf, binary.LittleEndian, f)
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+	return buf.Bytes()
+}
+
+var stlFacets = [][12]float32{
+	{0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1, 0},
+	{0, 0, -1, 0, 0, 0, 0, 1, 0, 1, 0, 0},
+}
+
+func assertSTLFacets(t *testing.T, mesh meshio.Mesh) {
+	assert.Equal(t, []vector.Vector3{vector.NewVector3(0, 0, 1), vector.NewVector3(0, 0, -1)}, mesh.Normals)
+	assert.Equal(t, []vector.Vector3{
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(1, 0, 0),
+		vector.NewVector3(0, 1, 0),
+		vector.NewVector3(0, 0, 0),
+		vector.NewVector3(0, 1, 0),
+		vector.NewVector3(1, 0, 0),
+	}, mesh.Vertices)
+	assert.Equal(t, []meshio.Triangle{
+		{corner(0, -1, 0), corner(1, -1, 0), corner(2, -1, 0)},
+		{corner(3, -1, 1), corner(4, -1, 1), corner(5, -1, 1)},
+	}, mesh.Triangles)
+	assert.True(t, mesh.HasNormals())
+}
+
+func TestReadSTL_Binary(t *testing.T) {
+	mesh, err := meshio.ReadSTL(bytes.NewReader(binarySTL("binary", stlFacets)))
+	if assert.NoError(t, err) {
+		assertSTLFacets(t, mesh)
+	}
+
+	// Plenty of exporters start binary files with "solid" anyway
+	mesh, err = meshio.ReadSTL(bytes.NewReader(binarySTL("solid exported", stlFacets)))
+	if assert.NoError(t, err) {
+		assertSTLFacets(t, mesh)
+	}
+}
+
+func TestReadSTL_ASCII(t *testing.T) {
+	mesh, err := meshio.ReadSTL(strings.NewReader(`solid two
+  facet normal 0 0 1
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 1 0
+    endloop
+  endfacet
+  facet normal 0 0 -1
+    outer loop
+      vertex 0 0 0
+      vertex 0 1 0
+      vertex 1 0 0
+    endloop
+  endfacet
+endsolid two
+`))
+	if assert.NoError(t, err) {
+		assertSTLFacets(t, mesh)
+	}
+}
+
+func TestReadSTL_Errors(t *testing.T) {
+	truncated := binarySTL("binary", stlFacets)
+	for name, tc := range map[string]struct {
+		stl     string
+		message string
+	}{
+		"truncated binary": {string(truncated[:len(truncated)-1]), "not an STL file"},
+		"bad normal":       {"solid\nfacet normal 0 x 1\n", "line 2: facet normal: component 2"},
+		"two vertices":     {"solid\nfacet normal 0 0 1\nvertex 0 0 0\nvertex 1 0 0\nendfacet\n", "line 5: facet needs 3 vertices, got 2"},
+		"stray vertex":     {"solid\nvertex 0 0 0\n", "line 2: unexpected vertex"},
+		"unfinished":       {"solid\nfacet normal 0 0 1\nvertex 0 0 0\n", "partway through a facet"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := meshio.ReadSTL(strings.NewReader(tc.stl))
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.message)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"tri.OBJ": []byte("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"),
+		"tri.stl": binarySTL("binary", stlFacets[:1]),
+		"tri.ply": []byte("ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n3 0 1 2\n"),
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if !assert.NoError(t, os.WriteFile(path, data, 0644)) {
+			return
+		}
+
+		mesh, err := meshio.ReadFile(path)
+		if assert.NoError(t, err, name) {
+			assert.Len(t, mesh.Triangles, 1, name)
+		}
+	}
+
+	_, err := meshio.ReadFile(filepath.Join(dir, "tri.fbx"))
+	assert.Error(t, err)
+
+	_, err = meshio.ReadFile(filepath.Join(dir, "missing.obj"))
+	assert.Error(t, err)
+
+	broken := filepath.Join(dir, "broken.ply")
+	if assert.NoError(t, os.WriteFile(broken, []byte("nope"), 0644)) {
+		_, err = meshio.ReadFile(broken)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "broken.ply: not a PLY file")
+		}
+	}
+}
@@ -8,49 +8,69 @@ import (
 
 // PackAlg24 converts the x y z components of a normalized vector into a  3
 // bytes for efficient transport. Uses trig to pack X into 12 bytes, Y into 11
-// bytes, and 1 to denote sign of Z.
+// bytes, and 1 to denote sign of Z. X and Y are clamped between -1 and 1,
+// with NaN written as 0, so the X bits are never all 0, which leaves all zero
+// bytes free as the null code. Components are rounded to the nearest step so
+// that packing an unpacked vector gives back the same bytes.
 func PackAlg24(v vector.Vector3) []byte {
+	everything := PackAlg24Uint32(v)
+	return []byte{
+		(byte)(everything & 0xFF),
+		(byte)((everything >> 8) & 0xFF),
+		(byte)((everything >> 16) & 0xFF),
+	}
+}
+
+// PackAlg24Uint32 is PackAlg24 without splitting the result into bytes. X
+// occupies bits 12 through 23, Y bits 1 through 11, and the sign of Z the
+// lowest bit. The upper 8 bits are unused.
+func PackAlg24Uint32(v vector.Vector3) uint32 {
 	// 2 ^ 12 = 4,096;
-	x := uint(math.Floor(v.X()*2047) + 2048)
+	x := uint32(math.Round(clampUnit(v.X())*2047) + 2048)
 
 	// 2^11 = 2048;
-	y := uint(math.Floor(v.Y()*1023) + 1024)
+	y := uint32(math.Round(clampUnit(v.Y())*1023) + 1024)
 
 	// Single byte as to whether or not Z was original positive or
-	// negative
-	zPositive := uint(0)
-	if v.Z() >= 0.0 {
+	// negative. When X and Y leave no room for Z, it unpacks as 0 either
+	// way, so always mark it positive.
+	zPositive := uint32(0)
+	if v.Z() >= 0.0 || algZ((float64(x)-2048.0)/2047.0, (float64(y)-1024.0)/1023.0) == 0 {
 		zPositive = 1
 	}
 
 	// Combine everything into one number
-	everything := (x << 12) | (y << 1) | zPositive
-
-	// Piece out that number
-	return []byte{
-		(byte)(everything & 0xFF),
-		(byte)((everything >> 8) & 0xFF),
-		(byte)((everything >> 16) & 0xFF),
-	}
+	return (x << 12) | (y << 1) | zPositive
 }
 
 // UnpackAlg24 will take a previously packed vector and extract it out of 3
 // bytes
 func UnpackAlg24(b []byte) vector.Vector3 {
-	everything := uint(b[0]) | (uint(b[1]) << 8) | (uint(b[2]) << 16)
+	return UnpackAlg24Uint32(uint32(b[0]) | (uint32(b[1]) << 8) | (uint32(b[2]) << 16))
+}
+
+// UnpackAlg24Uint32 is UnpackAlg24 for a code previously packed with
+// PackAlg24Uint32. The upper 8 bits are ignored.
+func UnpackAlg24Uint32(everything uint32) vector.Vector3 {
 	rawZ := (everything & 1) == 1
 	rawY := (int)((everything >> 1) & 0b11111111111)
-	rawX := (int)(everything >> 12)
+	rawX := (int)((everything >> 12) & 0b111111111111)
 
 	cleanedX := (float64(rawX) - 2048.0) / 2047.0
 	cleanedY := (float64(rawY) - 1024.0) / 1023.0
-	cleanedZ := math.Sqrt(1.0 - (cleanedX * cleanedX) - (cleanedY * cleanedY))
-	if math.IsNaN(cleanedZ) {
-		cleanedZ = 0
-	}
+	cleanedZ := algZ(cleanedX, cleanedY)
 	if !rawZ {
 		cleanedZ *= -1
 	}
 
 	return vector.NewVector3(cleanedX, cleanedY, cleanedZ)
 }
+
+// algZ recalculates the magnitude of Z from the unpacked X and Y
+func algZ(cleanedX, cleanedY float64) float64 {
+	cleanedZ := math.Sqrt(1.0 - (cleanedX * cleanedX) - (cleanedY * cleanedY))
+	if math.IsNaN(cleanedZ) {
+		return 0
+	}
+	return cleanedZ
+}
@@ -0,0 +1,73 @@
+package unitpacking
+
+import (
+	"encoding/binary"
+
+	"github.com/EliCDavis/vector"
+)
+
+// WithByteOrder returns a codec that writes the codes of the one given in the
+// requested byte order. Codecs write their code as a single little endian
+// word, or as a FieldCodec, several words one after the other, like the
+// direction and magnitude of a DirectionMagnitude. Big endian codes reverse
+// the bytes of each word and keep the words in the same order. The returned
+// codec keeps the null code of the original, if it has one.
+//
+// binary.LittleEndian returns the codec as is. Big endian codecs have "-be"
+// appended to their name.
+func WithByteOrder(codec Codec, order binary.ByteOrder) Codec {
+	if !isBigEndian(order) {
+		return codec
+	}
+
+	reversed := bigEndianCodec{codec, codecFields(codec)}
+	if _, ok := codec.(NullCodec); ok {
+		return bigEndianNullCodec{reversed}
+	}
+	return reversed
+}
+
+func isBigEndian(order binary.ByteOrder) bool {
+	b := make([]byte, 2)
+	order.PutUint16(b, 1)
+	return b[0] == 0
+}
+
+// reverseFields reverses the bytes within each field, leaving the fields in
+// the same order.
+func reverseFields(b []byte, fields []int) []byte {
+	out := make([]byte, len(b))
+	start := 0
+	for _, size := range fields {
+		for i := 0; i < size; i++ {
+			out[start+size-1-i] = b[start+i]
+		}
+		start += size
+	}
+	return out
+}
+
+type bigEndianCodec struct {
+	codec  Codec
+	fields []int
+}
+
+func (c bigEndianCodec) Name() string  { return c.codec.Name() + "-be" }
+func (c bigEndianCodec) Size() int     { return c.codec.Size() }
+func (c bigEndianCodec) Fields() []int { return append([]int(nil), c.fields...) }
+
+func (c bigEndianCodec) Pack(v vector.Vector3) []byte {
+	return reverseFields(c.codec.Pack(v), c.fields)
+}
+
+func (c bigEndianCodec) Unpack(b []byte) vector.Vector3 {
+	return c.codec.Unpack(reverseFields(b[:c.codec.Size()], c.fields))
+}
+
+type bigEndianNullCodec struct {
+	bigEndianCodec
+}
+
+func (c bigEndianNullCodec) Null() []byte {
+	return reverseFields(c.codec.(NullCodec).Null(), c.fields)
+}
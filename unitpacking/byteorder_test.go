@@ -0,0 +1,150 @@
+package unitpacking_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+// Widens a little endian code of up to 4 bytes into a word.
+func littleEndianWord(b []byte) uint32 {
+	padded := make([]byte, 4)
+	copy(padded, b)
+	return binary.LittleEndian.Uint32(padded)
+}
+
+func TestWordForms_MatchByteForms(t *testing.T) {
+	tests := map[string]struct {
+		pack     func(v vector.Vector3) []byte
+		packWord func(v vector.Vector3) uint32
+		unpack   func(w uint32) vector.Vector3
+	}{
+		"alg24":     {unitpacking.PackAlg24, unitpacking.PackAlg24Uint32, unitpacking.UnpackAlg24Uint32},
+		"coarse24":  {unitpacking.PackCoarse24, unitpacking.PackCoarse24Uint32, unitpacking.UnpackCoarse24Uint32},
+		"oct24":     {unitpacking.PackOct24, unitpacking.PackOct24Uint32, unitpacking.UnpackOct24Uint32},
+		"oct32":     {unitpacking.PackOct32, unitpacking.PackOct32Uint32, unitpacking.UnpackOct32Uint32},
+		"octquad24": {unitpacking.PackOctQuad24, unitpacking.PackOctQuad24Uint32, unitpacking.UnpackOctQuad24Uint32},
+		"octquad32": {unitpacking.PackOctQuad32, unitpacking.PackOctQuad32Uint32, unitpacking.UnpackOctQuad32Uint32},
+		"oct16": {
+			unitpacking.PackOct16,
+			func(v vector.Vector3) uint32 { return uint32(unitpacking.PackOct16Uint16(v)) },
+			func(w uint32) vector.Vector3 { return unitpacking.UnpackOct16Uint16(uint16(w)) },
+		},
+		"octquad16": {
+			unitpacking.PackOctQuad16,
+			func(v vector.Vector3) uint32 { return uint32(unitpacking.PackOctQuad16Uint16(v)) },
+			func(w uint32) vector.Vector3 { return unitpacking.UnpackOctQuad16Uint16(uint16(w)) },
+		},
+	}
+
+	for name, tc := range tests {
+		codec, ok := unitpacking.CodecByName(name)
+		assert.True(t, ok)
+
+		t.Run(name, func(t *testing.T) {
+			for _, in := range append(append([]vector.Vector3{}, testVectors...), edgeCaseVectors...) {
+				in = in.Normalized()
+				packed := tc.pack(in)
+				word := tc.packWord(in)
+				assert.Equal(t, littleEndianWord(packed), word)
+				assert.Equal(t, codec.Unpack(packed), tc.unpack(word))
+			}
+		})
+	}
+}
+
+func TestWordForms_IgnoreUnusedBits(t *testing.T) {
+	in := vector.NewVector3(0.2, -0.5, 0.7).Normalized()
+
+	assert.Equal(t, unitpacking.UnpackAlg24Uint32(unitpacking.PackAlg24Uint32(in)), unitpacking.UnpackAlg24Uint32(unitpacking.PackAlg24Uint32(in)|0xFF000000))
+	assert.Equal(t, unitpacking.UnpackCoarse24Uint32(unitpacking.PackCoarse24Uint32(in)), unitpacking.UnpackCoarse24Uint32(unitpacking.PackCoarse24Uint32(in)|0xFF000000))
+	assert.Equal(t, unitpacking.UnpackOct24Uint32(unitpacking.PackOct24Uint32(in)), unitpacking.UnpackOct24Uint32(unitpacking.PackOct24Uint32(in)|0xFF000000))
+	assert.Equal(t, unitpacking.UnpackOctQuad24Uint32(unitpacking.PackOctQuad24Uint32(in)), unitpacking.UnpackOctQuad24Uint32(unitpacking.PackOctQuad24Uint32(in)|0xFF000000))
+}
+
+func TestWithByteOrder_LittleEndianUnchanged(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		littleEndian := unitpacking.WithByteOrder(codec, binary.LittleEndian)
+		assert.Equal(t, codec.Name(), littleEndian.Name())
+		for _, tc := range testVectors {
+			assert.Equal(t, codec.Pack(tc.Normalized()), littleEndian.Pack(tc.Normalized()))
+		}
+	}
+}
+
+func TestWithByteOrder_BigEndian(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		bigEndian := unitpacking.WithByteOrder(codec, binary.BigEndian)
+
+		t.Run(bigEndian.Name(), func(t *testing.T) {
+			assert.Equal(t, codec.Name()+"-be", bigEndian.Name())
+			assert.Equal(t, codec.Size(), bigEndian.Size())
+
+			for _, tc := range testVectors {
+				in := tc.Normalized()
+				packed := codec.Pack(in)
+				packedBE := bigEndian.Pack(in)
+
+				assert.Len(t, packedBE, codec.Size())
+				for i := range packed {
+					assert.Equal(t, packed[i], packedBE[len(packedBE)-1-i])
+				}
+				assert.Equal(t, codec.Unpack(packed), bigEndian.Unpack(packedBE))
+			}
+
+			nullCodec, ok := bigEndian.(unitpacking.NullCodec)
+			if assert.True(t, ok) {
+				null := codec.(unitpacking.NullCodec).Null()
+				for i := range null {
+					assert.Equal(t, null[i], nullCodec.Null()[len(null)-1-i])
+				}
+				assert.True(t, unitpacking.IsNull(nullCodec, nullCodec.Null()))
+			}
+		})
+	}
+}
+
+func TestWithByteOrder_Oct32Layout(t *testing.T) {
+	in := vector.NewVector3(0.3, 0.4, -0.5).Normalized()
+	word := unitpacking.PackOct32Uint32(in)
+
+	bigEndian := make([]byte, 4)
+	binary.BigEndian.PutUint32(bigEndian, word)
+	assert.Equal(t, bigEndian, unitpacking.WithByteOrder(unitpacking.Oct32, binary.BigEndian).Pack(in))
+}
+
+func TestWithByteOrder_KeepsCodecWithoutNull(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct16, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+
+	bigEndian := unitpacking.WithByteOrder(codec, binary.BigEndian)
+	_, ok := bigEndian.(unitpacking.NullCodec)
+	assert.False(t, ok)
+
+	in := vector.NewVector3(0, 0.5, 0)
+	assert.Equal(t, codec.Unpack(codec.Pack(in)), bigEndian.Unpack(bigEndian.Pack(in)))
+}
+
+func TestWithByteOrder_DirectionMagnitudeFields(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, unitpacking.LinearMagnitude, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, codec.Fields())
+
+	bigEndian := unitpacking.WithByteOrder(codec, binary.BigEndian)
+	assert.Equal(t, []int{3, 2}, bigEndian.(unitpacking.FieldCodec).Fields())
+
+	for _, tc := range testVectors {
+		in := tc.Normalized().MultByConstant(3)
+		packed := codec.Pack(in)
+		packedBE := bigEndian.Pack(in)
+
+		// The direction comes first, as a big endian oct24 code, followed
+		// by the big endian magnitude
+		assert.Equal(t, unitpacking.WithByteOrder(unitpacking.Oct24, binary.BigEndian).Pack(tc.Normalized()), packedBE[:3])
+		assert.Equal(t, binary.LittleEndian.Uint16(packed[3:]), binary.BigEndian.Uint16(packedBE[3:]))
+		assert.Equal(t, codec.Unpack(packed), bigEndian.Unpack(packedBE))
+	}
+}
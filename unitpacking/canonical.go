@@ -0,0 +1,16 @@
+package unitpacking
+
+// Canonicalize maps packed bytes to the one code their vector is always
+// packed as. Several codes can unpack to the same direction, like the points
+// on either side of the octahedral seams, or codes the packing methods never
+// produce themselves, and these all canonicalize to the same code. A codec's
+// null code is left as is.
+//
+// Every code returned by a codec's Pack is already canonical, so for any
+// packed vector b, Pack(Unpack(b)) returns exactly b.
+func Canonicalize(codec Codec, b []byte) []byte {
+	if nullCodec, ok := codec.(NullCodec); ok && IsNull(nullCodec, b) {
+		return nullCodec.Null()
+	}
+	return codec.Pack(codec.Unpack(b))
+}
@@ -0,0 +1,113 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+// Walks every possible code of the codec, checking that canonicalizing a
+// code a second time never changes it.
+func assertCanonicalizeStable(t *testing.T, codec unitpacking.Codec) {
+	b := make([]byte, codec.Size())
+	for i := 0; i < 1<<(codec.Size()*8); i++ {
+		for j := range b {
+			b[j] = byte(i >> (j * 8))
+		}
+
+		canonical := unitpacking.Canonicalize(codec, b)
+		again := unitpacking.Canonicalize(codec, canonical)
+		if !bytes.Equal(canonical, again) {
+			t.Fatalf("%x canonicalized to %x and then %x", b, canonical, again)
+		}
+	}
+}
+
+func TestCanonicalize_Exhaustive16(t *testing.T) {
+	for _, codec := range []unitpacking.Codec{unitpacking.Oct16, unitpacking.OctQuad16, unitpacking.HemiOct16} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			assertCanonicalizeStable(t, codec)
+		})
+	}
+}
+
+func TestCanonicalize_Exhaustive24(t *testing.T) {
+	if testing.Short() {
+		t.Skip("walks all 2^24 codes of each codec")
+	}
+
+	for _, codec := range []unitpacking.Codec{unitpacking.Alg24, unitpacking.Coarse24, unitpacking.Oct24, unitpacking.OctQuad24, unitpacking.HemiOct24} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			assertCanonicalizeStable(t, codec)
+		})
+	}
+}
+
+func TestCanonicalize_SameDirectionSameCode(t *testing.T) {
+	// Every code that unpacks to the same vector must canonicalize to the
+	// same code.
+	for _, codec := range []unitpacking.Codec{unitpacking.Oct16, unitpacking.OctQuad16, unitpacking.HemiOct16} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			canonicalByVector := make(map[vector.Vector3][]byte)
+			for i := 0; i < 1<<16; i++ {
+				b := []byte{byte(i), byte(i >> 8)}
+				if unitpacking.IsNull(codec.(unitpacking.NullCodec), b) {
+					continue
+				}
+
+				unpacked := codec.Unpack(b)
+				canonical := unitpacking.Canonicalize(codec, b)
+				if existing, ok := canonicalByVector[unpacked]; ok {
+					assert.Equal(t, existing, canonical)
+				}
+				canonicalByVector[unpacked] = canonical
+			}
+		})
+	}
+}
+
+func TestCanonicalize_OctSeam(t *testing.T) {
+	// (1, y) and (1, -y) are the same point on the fold of the octahedron
+	for y := 1; y < 128; y++ {
+		a := []byte{byte(128 + y), 255}
+		b := []byte{byte(128 - y), 255}
+		assert.Equal(t, unitpacking.UnpackOct16(a), unitpacking.UnpackOct16(b))
+		assert.Equal(t, unitpacking.Canonicalize(unitpacking.Oct16, a), unitpacking.Canonicalize(unitpacking.Oct16, b))
+	}
+}
+
+func TestCanonicalize_NullUntouched(t *testing.T) {
+	for _, codec := range unitpacking.AllCodecs() {
+		null := codec.(unitpacking.NullCodec).Null()
+		assert.Equal(t, null, unitpacking.Canonicalize(codec, null))
+	}
+}
+
+func TestRepack_Idempotent(t *testing.T) {
+	r := rand.New(rand.NewSource(31))
+	inputs := make([]vector.Vector3, 0)
+	for _, tc := range testVectors {
+		inputs = append(inputs, tc.Normalized())
+	}
+	for i := 0; i < 20000; i++ {
+		inputs = append(inputs, vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64()).Normalized())
+	}
+
+	for _, codec := range unitpacking.AllCodecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, in := range inputs {
+				in = coveredBy(codec, in)
+				packed := codec.Pack(in)
+				repacked := codec.Pack(codec.Unpack(packed))
+				if !bytes.Equal(packed, repacked) {
+					t.Fatalf("%v packed to %x but repacked to %x", in, packed, repacked)
+				}
+				assert.Equal(t, packed, unitpacking.Canonicalize(codec, packed))
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+//go:build cgo
+
+package cgotest
+
+/*
+#cgo CFLAGS: -std=c99
+#cgo LDFLAGS: -lm
+
+#define UNITPACKING_IMPLEMENTATION
+#define UNITPACKING_STATIC
+#include "unitpacking.h"
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/EliCDavis/vector"
+)
+
+func toC(v vector.Vector3) C.unitpacking_vec3 {
+	return C.unitpacking_vec3{x: C.double(v.X()), y: C.double(v.Y()), z: C.double(v.Z())}
+}
+
+func fromC(v C.unitpacking_vec3) vector.Vector3 {
+	return vector.NewVector3(float64(v.x), float64(v.y), float64(v.z))
+}
+
+// Pack packs the vector with the C implementation of the codec with the
+// given name.
+func Pack(codec string, v vector.Vector3) []byte {
+	out := make([]byte, 4)
+	ptr := (*C.uint8_t)(unsafe.Pointer(&out[0]))
+
+	switch codec {
+	case "alg24":
+		C.unitpacking_pack_alg24(toC(v), ptr)
+		return out[:3]
+	case "coarse24":
+		C.unitpacking_pack_coarse24(toC(v), ptr)
+		return out[:3]
+	case "oct16":
+		C.unitpacking_pack_oct16(toC(v), ptr)
+		return out[:2]
+	case "oct24":
+		C.unitpacking_pack_oct24(toC(v), ptr)
+		return out[:3]
+	case "oct32":
+		C.unitpacking_pack_oct32(toC(v), ptr)
+		return out[:4]
+	case "octquad16":
+		C.unitpacking_pack_octquad16(toC(v), ptr)
+		return out[:2]
+	case "octquad24":
+		C.unitpacking_pack_octquad24(toC(v), ptr)
+		return out[:3]
+	case "octquad32":
+		C.unitpacking_pack_octquad32(toC(v), ptr)
+		return out[:4]
+	}
+	panic(fmt.Sprintf("unknown codec: %s", codec))
+}
+
+// Unpack unpacks the bytes with the C implementation of the codec with the
+// given name.
+func Unpack(codec string, b []byte) vector.Vector3 {
+	ptr := (*C.uint8_t)(unsafe.Pointer(&b[0]))
+
+	switch codec {
+	case "alg24":
+		return fromC(C.unitpacking_unpack_alg24(ptr))
+	case "coarse24":
+		return fromC(C.unitpacking_unpack_coarse24(ptr))
+	case "oct16":
+		return fromC(C.unitpacking_unpack_oct16(ptr))
+	case "oct24":
+		return fromC(C.unitpacking_unpack_oct24(ptr))
+	case "oct32":
+		return fromC(C.unitpacking_unpack_oct32(ptr))
+	case "octquad16":
+		return fromC(C.unitpacking_unpack_octquad16(ptr))
+	case "octquad24":
+		return fromC(C.unitpacking_unpack_octquad24(ptr))
+	case "octquad32":
+		return fromC(C.unitpacking_unpack_octquad32(ptr))
+	}
+	panic(fmt.Sprintf("unknown codec: %s", codec))
+}
+
+// PackWord packs the vector with the C word form of the codec with the given
+// name. 16 bit codes are widened to 32 bits.
+func PackWord(codec string, v vector.Vector3) uint32 {
+	switch codec {
+	case "alg24":
+		return uint32(C.unitpacking_pack_alg24_u32(toC(v)))
+	case "coarse24":
+		return uint32(C.unitpacking_pack_coarse24_u32(toC(v)))
+	case "oct16":
+		return uint32(C.unitpacking_pack_oct16_u16(toC(v)))
+	case "oct24":
+		return uint32(C.unitpacking_pack_oct24_u32(toC(v)))
+	case "oct32":
+		return uint32(C.unitpacking_pack_oct32_u32(toC(v)))
+	case "octquad16":
+		return uint32(C.unitpacking_pack_octquad16_u16(toC(v)))
+	case "octquad24":
+		return uint32(C.unitpacking_pack_octquad24_u32(toC(v)))
+	case "octquad32":
+		return uint32(C.unitpacking_pack_octquad32_u32(toC(v)))
+	}
+	panic(fmt.Sprintf("unknown codec: %s", codec))
+}
+
+// UnpackWord unpacks the code with the C word form of the codec with the
+// given name.
+func UnpackWord(codec string, code uint32) vector.Vector3 {
+	switch codec {
+	case "alg24":
+		return fromC(C.unitpacking_unpack_alg24_u32(C.uint32_t(code)))
+	case "coarse24":
+		return fromC(C.unitpacking_unpack_coarse24_u32(C.uint32_t(code)))
+	case "oct16":
+		return fromC(C.unitpacking_unpack_oct16_u16(C.uint16_t(code)))
+	case "oct24":
+		return fromC(C.unitpacking_unpack_oct24_u32(C.uint32_t(code)))
+	case "oct32":
+		return fromC(C.unitpacking_unpack_oct32_u32(C.uint32_t(code)))
+	case "octquad16":
+		return fromC(C.unitpacking_unpack_octquad16_u16(C.uint16_t(code)))
+	case "octquad24":
+		return fromC(C.unitpacking_unpack_octquad24_u32(C.uint32_t(code)))
+	case "octquad32":
+		return fromC(C.unitpacking_unpack_octquad32_u32(C.uint32_t(code)))
+	}
+	panic(fmt.Sprintf("unknown codec: %s", codec))
+}
@@ -0,0 +1,209 @@
+//go:build cgo
+
+package cgotest_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"math"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/recolude/unitpacking/unitpacking/cgotest"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite unitpacking.h")
+
+func sameBits(a, b vector.Vector3) bool {
+	return math.Float64bits(a.X()) == math.Float64bits(b.X()) &&
+		math.Float64bits(a.Y()) == math.Float64bits(b.Y()) &&
+		math.Float64bits(a.Z()) == math.Float64bits(b.Z())
+}
+
+func TestHeader_Golden(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCHeader(&buf, unitpacking.Codecs()...))
+
+	if *update {
+		assert.NoError(t, os.WriteFile("unitpacking.h", buf.Bytes(), 0644))
+	}
+
+	expected, err := os.ReadFile("unitpacking.h")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+// Compiles the header on its own with every warning turned into an error, as
+// both C99 and C++.
+func TestHeader_CompilesCleanly(t *testing.T) {
+	cc, err := exec.LookPath("cc")
+	if err != nil {
+		t.Skip("cc not found")
+	}
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "impl.c")
+	assert.NoError(t, os.WriteFile(src, []byte("#define UNITPACKING_IMPLEMENTATION\n#include \"unitpacking.h\"\n"), 0644))
+
+	for _, args := range [][]string{
+		{"-std=c99", "-pedantic", "-Wall", "-Wextra", "-Werror"},
+		{"-std=c99", "-pedantic", "-Wall", "-Wextra", "-Werror", "-DUNITPACKING_STATIC"},
+		{"-x", "c++", "-Wall", "-Wextra", "-Werror"},
+	} {
+		args = append(args, "-I", ".", "-c", src, "-o", filepath.Join(dir, "impl.o"))
+		out, err := exec.Command(cc, args...).CombinedOutput()
+		assert.NoError(t, err, "%v\n%s", args, out)
+	}
+}
+
+func checkCode(t *testing.T, codec unitpacking.Codec, b []byte) {
+	t.Helper()
+
+	expected := codec.Unpack(b)
+	if got := cgotest.Unpack(codec.Name(), b); !sameBits(expected, got) {
+		t.Fatalf("%x unpacked to %v in Go but %v in C", b, expected, got)
+	}
+
+	word := uint32(0)
+	for i, v := range b {
+		word |= uint32(v) << (i * 8)
+	}
+	if got := cgotest.UnpackWord(codec.Name(), word); !sameBits(expected, got) {
+		t.Fatalf("%x unpacked to %v in Go but %v from the C word form", b, expected, got)
+	}
+}
+
+func checkVector(t *testing.T, codec unitpacking.Codec, v vector.Vector3) {
+	t.Helper()
+
+	expected := codec.Pack(v)
+	if got := cgotest.Pack(codec.Name(), v); !bytes.Equal(expected, got) {
+		t.Fatalf("%v packed to %x in Go but %x in C", v, expected, got)
+	}
+
+	word := uint32(0)
+	for i, b := range expected {
+		word |= uint32(b) << (i * 8)
+	}
+	if got := cgotest.PackWord(codec.Name(), v); word != got {
+		t.Fatalf("%v packed to %x in Go but %x from the C word form", v, word, got)
+	}
+}
+
+func TestUnpack_Exhaustive(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		if codec.Size() > 3 || (codec.Size() == 3 && testing.Short()) {
+			continue
+		}
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			b := make([]byte, codec.Size())
+			for i := 0; i < 1<<(codec.Size()*8); i++ {
+				for j := range b {
+					b[j] = byte(i >> (j * 8))
+				}
+				checkCode(t, codec, b)
+			}
+		})
+	}
+}
+
+func TestUnpack_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	samples := 1 << 20
+	if testing.Short() {
+		samples = 1 << 14
+	}
+
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			b := make([]byte, codec.Size())
+			for i := 0; i < samples; i++ {
+				r.Read(b)
+				checkCode(t, codec, b)
+			}
+		})
+	}
+}
+
+func TestPack_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(36))
+	samples := 1 << 20
+	if testing.Short() {
+		samples = 1 << 14
+	}
+
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for i := 0; i < samples; i++ {
+				v := vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64())
+				checkVector(t, codec, v.Normalized())
+
+				// Not unit length, and components just past +/- 1
+				if i%16 == 0 {
+					checkVector(t, codec, v)
+				}
+			}
+		})
+	}
+}
+
+func TestPack_LatticePoints(t *testing.T) {
+	// Every direction a 16 bit code decodes to, where candidates on the
+	// lattice tie and seams fold
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for i := 0; i < 1<<16; i++ {
+				checkVector(t, codec, unitpacking.Oct16.Unpack([]byte{byte(i), byte(i >> 8)}))
+				checkVector(t, codec, unitpacking.OctQuad16.Unpack([]byte{byte(i), byte(i >> 8)}))
+			}
+		})
+	}
+}
+
+// Checks the C implementation against the same test vectors the Go and C#
+// implementations are held to.
+func TestTestVectors(t *testing.T) {
+	f, err := os.Open(filepath.Join("..", "testdata", "vectors.txt"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	bits := func(s string) float64 {
+		v, err := strconv.ParseUint(s, 16, 64)
+		assert.NoError(t, err)
+		return math.Float64frombits(v)
+	}
+
+	verified := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		in := vector.NewVector3(bits(fields[1]), bits(fields[2]), bits(fields[3]))
+		packed, err := hex.DecodeString(fields[4])
+		assert.NoError(t, err)
+		out := vector.NewVector3(bits(fields[5]), bits(fields[6]), bits(fields[7]))
+
+		assert.Equal(t, packed, cgotest.Pack(fields[0], in), line)
+		assert.True(t, sameBits(out, cgotest.Unpack(fields[0], packed)), line)
+		verified++
+	}
+	assert.NoError(t, scanner.Err())
+	assert.NotZero(t, verified)
+}
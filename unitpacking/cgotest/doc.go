@@ -0,0 +1,4 @@
+// Package cgotest compiles the C header written by unitpacking.WriteCHeader
+// and exposes it to Go, so its tests can cross check every codec against the
+// Go implementation. It requires cgo.
+package cgotest
@@ -0,0 +1,479 @@
+/* Code generated by unitpacking. DO NOT EDIT.
+ *
+ * A dependency free C99 single header library that packs and unpacks unit
+ * vectors exactly like the Go unitpacking library. Codes are written little
+ * endian, and the _u16/_u32 word forms match the Go Pack*Uint16/Pack*Uint32
+ * functions.
+ *
+ * Define UNITPACKING_IMPLEMENTATION in exactly one source file before
+ * including this header to compile the implementation. Define
+ * UNITPACKING_STATIC as well to keep every function private to that file.
+ *
+ * Results are bit exact with Go on targets with IEEE 754 doubles, as long as
+ * the compiler doesn't fuse multiplies and adds or keep excess precision. On
+ * targets with FMA instructions pass -ffp-contract=off, which GCC already
+ * defaults to in its ISO C modes, and on 32 bit x86 build with SSE2 rather
+ * than x87 floating point.
+ */
+#ifndef UNITPACKING_H
+#define UNITPACKING_H
+
+#include <stdint.h>
+
+#ifndef UNITPACKING_DEF
+#ifdef UNITPACKING_STATIC
+#define UNITPACKING_DEF static inline
+#else
+#define UNITPACKING_DEF extern
+#endif
+#endif
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+typedef struct unitpacking_vec3 {
+    double x;
+    double y;
+    double z;
+} unitpacking_vec3;
+
+UNITPACKING_DEF void unitpacking_pack_alg24(unitpacking_vec3 v, uint8_t out[3]);
+UNITPACKING_DEF uint32_t unitpacking_pack_alg24_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_alg24(const uint8_t in[3]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_alg24_u32(uint32_t code);
+
+UNITPACKING_DEF void unitpacking_pack_coarse24(unitpacking_vec3 v, uint8_t out[3]);
+UNITPACKING_DEF uint32_t unitpacking_pack_coarse24_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_coarse24(const uint8_t in[3]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_coarse24_u32(uint32_t code);
+
+UNITPACKING_DEF void unitpacking_pack_oct16(unitpacking_vec3 v, uint8_t out[2]);
+UNITPACKING_DEF uint16_t unitpacking_pack_oct16_u16(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct16(const uint8_t in[2]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct16_u16(uint16_t code);
+
+UNITPACKING_DEF void unitpacking_pack_oct24(unitpacking_vec3 v, uint8_t out[3]);
+UNITPACKING_DEF uint32_t unitpacking_pack_oct24_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct24(const uint8_t in[3]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct24_u32(uint32_t code);
+
+UNITPACKING_DEF void unitpacking_pack_oct32(unitpacking_vec3 v, uint8_t out[4]);
+UNITPACKING_DEF uint32_t unitpacking_pack_oct32_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct32(const uint8_t in[4]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct32_u32(uint32_t code);
+
+UNITPACKING_DEF void unitpacking_pack_octquad16(unitpacking_vec3 v, uint8_t out[2]);
+UNITPACKING_DEF uint16_t unitpacking_pack_octquad16_u16(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad16(const uint8_t in[2]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad16_u16(uint16_t code);
+
+UNITPACKING_DEF void unitpacking_pack_octquad24(unitpacking_vec3 v, uint8_t out[3]);
+UNITPACKING_DEF uint32_t unitpacking_pack_octquad24_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad24(const uint8_t in[3]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad24_u32(uint32_t code);
+
+UNITPACKING_DEF void unitpacking_pack_octquad32(unitpacking_vec3 v, uint8_t out[4]);
+UNITPACKING_DEF uint32_t unitpacking_pack_octquad32_u32(unitpacking_vec3 v);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad32(const uint8_t in[4]);
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad32_u32(uint32_t code);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* UNITPACKING_H */
+
+#ifdef UNITPACKING_IMPLEMENTATION
+
+#include <math.h>
+
+static double unitpacking__clamp(double num, double min, double max) {
+    if (num < min) {
+        return min;
+    }
+    if (num > max) {
+        return max;
+    }
+    return num;
+}
+
+static uint32_t unitpacking__read_word(const uint8_t *in, int size) {
+    uint32_t word = 0;
+    int i;
+    for (i = 0; i < size; i++) {
+        word |= (uint32_t)in[i] << (i * 8);
+    }
+    return word;
+}
+
+static void unitpacking__write_word(uint32_t word, uint8_t *out, int size) {
+    int i;
+    for (i = 0; i < size; i++) {
+        out[i] = (uint8_t)(word >> (i * 8));
+    }
+}
+
+static unitpacking_vec3 unitpacking__vec3(double x, double y, double z) {
+    unitpacking_vec3 v;
+    v.x = x;
+    v.y = y;
+    v.z = z;
+    return v;
+}
+
+static double unitpacking__clamp_unit(double num) {
+    return isnan(num) ? 0 : unitpacking__clamp(num, -1.0, 1.0);
+}
+
+static double unitpacking__sign_not_zero(double v) {
+    return v < 0.0 ? -1.0 : 1.0;
+}
+
+static void unitpacking__map_to_oct_uv(unitpacking_vec3 v, double *u, double *w) {
+    double inv = 1.0 / (fabs(v.x) + fabs(v.y) + fabs(v.z));
+    double px = v.x * inv;
+    double py = v.y * inv;
+    if (v.z > 0) {
+        *u = px;
+        *w = py;
+        return;
+    }
+
+    *u = unitpacking__sign_not_zero(px) * (1.0 - fabs(py));
+    *w = unitpacking__sign_not_zero(py) * (1.0 - fabs(px));
+}
+
+static unitpacking_vec3 unitpacking__from_oct_uv(double u, double w) {
+    double x = u;
+    double y = w;
+    double z = 1.0 - fabs(u) - fabs(w);
+    double inv;
+    if (z < 0) {
+        x = (1.0 - fabs(w)) * unitpacking__sign_not_zero(u);
+        y = (1.0 - fabs(u)) * unitpacking__sign_not_zero(w);
+    }
+
+    inv = 1.0 / sqrt((x * x) + (y * y) + (z * z));
+    return unitpacking__vec3(x * inv, y * inv, z * inv);
+}
+
+static double unitpacking__dot(unitpacking_vec3 a, unitpacking_vec3 b) {
+    return (a.x * b.x) + (a.y * b.y) + (a.z * b.z);
+}
+
+static void unitpacking__map_to_oct_uv_precise(unitpacking_vec3 v, int n, double *u, double *w) {
+    double s, t, m, base_x, base_y, highest_cosine, i, j;
+    unitpacking__map_to_oct_uv(v, &s, &t);
+    if (isnan(s) || isnan(t)) {
+        *u = 0;
+        *w = 0;
+        return;
+    }
+
+    m = (double)(1 << ((n / 2) - 1)) - 1.0;
+    base_x = floor(unitpacking__clamp(s, -1.0, 1.0) * m);
+    base_y = floor(unitpacking__clamp(t, -1.0, 1.0) * m);
+
+    *u = base_x / m;
+    *w = base_y / m;
+    highest_cosine = unitpacking__dot(unitpacking__from_oct_uv(*u, *w), v);
+
+    for (i = 0.0; i <= 1; i++) {
+        for (j = 0.0; j <= 1; j++) {
+            double candidate_u, candidate_w, cosine;
+            if (i == 0 && j == 0) {
+                continue;
+            }
+            if (base_x + i > m || base_y + j > m) {
+                continue;
+            }
+
+            candidate_u = (base_x + i) / m;
+            candidate_w = (base_y + j) / m;
+            cosine = unitpacking__dot(unitpacking__from_oct_uv(candidate_u, candidate_w), v);
+            if (cosine > highest_cosine) {
+                *u = candidate_u;
+                *w = candidate_w;
+                highest_cosine = cosine;
+            }
+        }
+    }
+
+    /* Directions on the seams of the lower hemisphere always get the same
+     * code. */
+    if (fabs(*u) == 1) {
+        *w = fabs(*w);
+    }
+    if (fabs(*w) == 1) {
+        *u = fabs(*u);
+    }
+}
+
+static uint32_t unitpacking__quad_code(double u, double w, int levels) {
+    double min_x = -1.0;
+    double min_y = -1.0;
+    double max_x = 1.0;
+    double max_y = 1.0;
+    uint32_t code = 0;
+    int i;
+
+    for (i = 0; i < levels; i++) {
+        double mid_x = (max_x + min_x) / 2;
+        double mid_y = (max_y + min_y) / 2;
+        uint32_t dir;
+
+        if (u < mid_x) {
+            max_x = mid_x;
+            if (w < mid_y) {
+                max_y = mid_y;
+                dir = 2;
+            } else {
+                min_y = mid_y;
+                dir = 0;
+            }
+        } else {
+            min_x = mid_x;
+            if (w < mid_y) {
+                max_y = mid_y;
+                dir = 3;
+            } else {
+                min_y = mid_y;
+                dir = 1;
+            }
+        }
+
+        code = (code << 2) | dir;
+    }
+    return code;
+}
+
+static void unitpacking__quad_decode(uint32_t code, int levels, double *u, double *w) {
+    double multiplyer = 0.5;
+    int i;
+    *u = 0.0;
+    *w = 0.0;
+    for (i = levels - 1; i >= 0; i--) {
+        uint32_t dir = (code >> (i * 2)) & 3;
+        *u += (dir & 1) == 1 ? multiplyer : -multiplyer;
+        *w += dir < 2 ? multiplyer : -multiplyer;
+        multiplyer /= 2.0;
+    }
+}
+
+/* Every level of the quad tree going to the bottom left is the null code,
+ * and is moved one cell to the right. */
+static uint32_t unitpacking__avoid_oct_quad_null(uint32_t code, int levels) {
+    uint32_t null_code = 0;
+    int i;
+    for (i = 0; i < levels; i++) {
+        null_code = (null_code << 2) | 2;
+    }
+    return code == null_code ? code | 3 : code;
+}
+
+static double unitpacking__alg_z(double x, double y) {
+    double z = sqrt(1.0 - (x * x) - (y * y));
+    return isnan(z) ? 0 : z;
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_alg24_u32(unitpacking_vec3 v) {
+    uint32_t x = (uint32_t)(round(unitpacking__clamp_unit(v.x) * 2047) + 2048);
+    uint32_t y = (uint32_t)(round(unitpacking__clamp_unit(v.y) * 1023) + 1024);
+    uint32_t z_positive = 0;
+    if (v.z >= 0.0 || unitpacking__alg_z((x - 2048.0) / 2047.0, (y - 1024.0) / 1023.0) == 0) {
+        z_positive = 1;
+    }
+    return (x << 12) | (y << 1) | z_positive;
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_alg24_u32(uint32_t code) {
+    double x = (((code >> 12) & 0xFFF) - 2048.0) / 2047.0;
+    double y = (((code >> 1) & 0x7FF) - 1024.0) / 1023.0;
+    double z = unitpacking__alg_z(x, y);
+    if ((code & 1) != 1) {
+        z *= -1;
+    }
+    return unitpacking__vec3(x, y, z);
+}
+
+UNITPACKING_DEF void unitpacking_pack_alg24(unitpacking_vec3 v, uint8_t out[3]) {
+    unitpacking__write_word(unitpacking_pack_alg24_u32(v), out, 3);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_alg24(const uint8_t in[3]) {
+    return unitpacking_unpack_alg24_u32((uint32_t)unitpacking__read_word(in, 3));
+}
+
+static uint32_t unitpacking__normal_to_byte(double n) {
+    return (uint8_t)(round(unitpacking__clamp_unit(n) * 127.0) + 128);
+}
+
+static double unitpacking__byte_to_normal(uint32_t b) {
+    return ((double)(b & 0xFF) - 128.0) / 127.0;
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_coarse24_u32(unitpacking_vec3 v) {
+    return unitpacking__normal_to_byte(v.x) |
+        (unitpacking__normal_to_byte(v.y) << 8) |
+        (unitpacking__normal_to_byte(v.z) << 16);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_coarse24_u32(uint32_t code) {
+    return unitpacking__vec3(
+        unitpacking__byte_to_normal(code),
+        unitpacking__byte_to_normal(code >> 8),
+        unitpacking__byte_to_normal(code >> 16)
+    );
+}
+
+UNITPACKING_DEF void unitpacking_pack_coarse24(unitpacking_vec3 v, uint8_t out[3]) {
+    unitpacking__write_word(unitpacking_pack_coarse24_u32(v), out, 3);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_coarse24(const uint8_t in[3]) {
+    return unitpacking_unpack_coarse24_u32((uint32_t)unitpacking__read_word(in, 3));
+}
+
+UNITPACKING_DEF uint16_t unitpacking_pack_oct16_u16(unitpacking_vec3 v) {
+    double u, w;
+    uint32_t x, y;
+    unitpacking__map_to_oct_uv_precise(v, 16, &u, &w);
+
+    x = (uint32_t)(round(u * 127) + 128);
+    y = (uint32_t)(round(w * 127) + 128);
+    return (uint16_t)((x << 8) | y);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct16_u16(uint16_t code) {
+    uint32_t raw_x = ((uint32_t)code >> 8) & 0xFF;
+    uint32_t raw_y = (uint32_t)code & 0xFF;
+
+    double x = unitpacking__clamp((raw_x - 128.0) / 127.0, -1.0, 1.0);
+    double y = unitpacking__clamp((raw_y - 128.0) / 127.0, -1.0, 1.0);
+    return unitpacking__from_oct_uv(x, y);
+}
+
+UNITPACKING_DEF void unitpacking_pack_oct16(unitpacking_vec3 v, uint8_t out[2]) {
+    unitpacking__write_word(unitpacking_pack_oct16_u16(v), out, 2);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct16(const uint8_t in[2]) {
+    return unitpacking_unpack_oct16_u16((uint16_t)unitpacking__read_word(in, 2));
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_oct24_u32(unitpacking_vec3 v) {
+    double u, w;
+    uint32_t x, y;
+    unitpacking__map_to_oct_uv_precise(v, 24, &u, &w);
+
+    x = (uint32_t)(round(u * 2047) + 2048);
+    y = (uint32_t)(round(w * 2047) + 2048);
+    return (uint32_t)((x << 12) | y);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct24_u32(uint32_t code) {
+    uint32_t raw_x = ((uint32_t)code >> 12) & 0xFFF;
+    uint32_t raw_y = (uint32_t)code & 0xFFF;
+
+    double x = unitpacking__clamp((raw_x - 2048.0) / 2047.0, -1.0, 1.0);
+    double y = unitpacking__clamp((raw_y - 2048.0) / 2047.0, -1.0, 1.0);
+    return unitpacking__from_oct_uv(x, y);
+}
+
+UNITPACKING_DEF void unitpacking_pack_oct24(unitpacking_vec3 v, uint8_t out[3]) {
+    unitpacking__write_word(unitpacking_pack_oct24_u32(v), out, 3);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct24(const uint8_t in[3]) {
+    return unitpacking_unpack_oct24_u32((uint32_t)unitpacking__read_word(in, 3));
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_oct32_u32(unitpacking_vec3 v) {
+    double u, w;
+    uint32_t x, y;
+    unitpacking__map_to_oct_uv_precise(v, 32, &u, &w);
+
+    x = (uint32_t)(round(u * 32767) + 32768);
+    y = (uint32_t)(round(w * 32767) + 32768);
+    return (uint32_t)((x << 16) | y);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct32_u32(uint32_t code) {
+    uint32_t raw_x = ((uint32_t)code >> 16) & 0xFFFF;
+    uint32_t raw_y = (uint32_t)code & 0xFFFF;
+
+    double x = unitpacking__clamp((raw_x - 32768.0) / 32767.0, -1.0, 1.0);
+    double y = unitpacking__clamp((raw_y - 32768.0) / 32767.0, -1.0, 1.0);
+    return unitpacking__from_oct_uv(x, y);
+}
+
+UNITPACKING_DEF void unitpacking_pack_oct32(unitpacking_vec3 v, uint8_t out[4]) {
+    unitpacking__write_word(unitpacking_pack_oct32_u32(v), out, 4);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_oct32(const uint8_t in[4]) {
+    return unitpacking_unpack_oct32_u32((uint32_t)unitpacking__read_word(in, 4));
+}
+
+UNITPACKING_DEF uint16_t unitpacking_pack_octquad16_u16(unitpacking_vec3 v) {
+    double u, w;
+    unitpacking__map_to_oct_uv(v, &u, &w);
+    return (uint16_t)unitpacking__avoid_oct_quad_null(unitpacking__quad_code(u, w, 8), 8);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad16_u16(uint16_t code) {
+    double u, w;
+    unitpacking__quad_decode((uint32_t)code & 0xFFFF, 8, &u, &w);
+    return unitpacking__from_oct_uv(u, w);
+}
+
+UNITPACKING_DEF void unitpacking_pack_octquad16(unitpacking_vec3 v, uint8_t out[2]) {
+    unitpacking__write_word(unitpacking_pack_octquad16_u16(v), out, 2);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad16(const uint8_t in[2]) {
+    return unitpacking_unpack_octquad16_u16((uint16_t)unitpacking__read_word(in, 2));
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_octquad24_u32(unitpacking_vec3 v) {
+    double u, w;
+    unitpacking__map_to_oct_uv(v, &u, &w);
+    return (uint32_t)unitpacking__avoid_oct_quad_null(unitpacking__quad_code(u, w, 12), 12);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad24_u32(uint32_t code) {
+    double u, w;
+    unitpacking__quad_decode((uint32_t)code & 0xFFFFFF, 12, &u, &w);
+    return unitpacking__from_oct_uv(u, w);
+}
+
+UNITPACKING_DEF void unitpacking_pack_octquad24(unitpacking_vec3 v, uint8_t out[3]) {
+    unitpacking__write_word(unitpacking_pack_octquad24_u32(v), out, 3);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad24(const uint8_t in[3]) {
+    return unitpacking_unpack_octquad24_u32((uint32_t)unitpacking__read_word(in, 3));
+}
+
+UNITPACKING_DEF uint32_t unitpacking_pack_octquad32_u32(unitpacking_vec3 v) {
+    double u, w;
+    unitpacking__map_to_oct_uv(v, &u, &w);
+    return (uint32_t)unitpacking__avoid_oct_quad_null(unitpacking__quad_code(u, w, 16), 16);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad32_u32(uint32_t code) {
+    double u, w;
+    unitpacking__quad_decode((uint32_t)code & 0xFFFFFFFF, 16, &u, &w);
+    return unitpacking__from_oct_uv(u, w);
+}
+
+UNITPACKING_DEF void unitpacking_pack_octquad32(unitpacking_vec3 v, uint8_t out[4]) {
+    unitpacking__write_word(unitpacking_pack_octquad32_u32(v), out, 4);
+}
+
+UNITPACKING_DEF unitpacking_vec3 unitpacking_unpack_octquad32(const uint8_t in[4]) {
+    return unitpacking_unpack_octquad32_u32((uint32_t)unitpacking__read_word(in, 4));
+}
+
+#endif /* UNITPACKING_IMPLEMENTATION */
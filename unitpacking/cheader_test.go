@@ -0,0 +1,26 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCHeader_OnlyRequestedCodecs(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCHeader(&buf, unitpacking.Oct16))
+	assert.Contains(t, buf.String(), "uint16_t unitpacking_pack_oct16_u16(unitpacking_vec3 v)")
+	assert.NotContains(t, buf.String(), "oct24")
+	assert.NotContains(t, buf.String(), "unitpacking__quad_code")
+}
+
+func TestWriteCHeader_Unsupported(t *testing.T) {
+	magnitude, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+
+	err = unitpacking.WriteCHeader(&bytes.Buffer{}, unitpacking.Oct24, magnitude)
+	assert.True(t, errors.Is(err, unitpacking.ErrUnsupportedCodec))
+}
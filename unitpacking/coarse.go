@@ -6,14 +6,10 @@ import (
 	"github.com/EliCDavis/vector"
 )
 
+// normalToByte rounds to a byte between 1 and 255. 0 is never written, as it
+// would unpack to just under -1. NaN is written as 0.
 func normalToByte(normal float64) byte {
-	if normal <= -1.0 {
-		return 0
-	}
-	if normal >= 1.0 {
-		return 255
-	}
-	return byte(math.Floor(normal*127.0)) + 128
+	return byte(math.Round(clampUnit(normal)*127.0) + 128)
 }
 
 func byteToNormal(b byte) float64 {
@@ -22,7 +18,8 @@ func byteToNormal(b byte) float64 {
 
 // PackCoarse24 will convert each component of the vector into a single byte,
 // and will return those bytes in an array where x is at index 0, and z is at
-// index 2
+// index 2. Components are never packed as 0, leaving all zero bytes free as
+// the null code.
 func PackCoarse24(v vector.Vector3) []byte {
 	return []byte{
 		normalToByte(v.X()),
@@ -40,3 +37,22 @@ func UnpackCoarse24(in []byte) vector.Vector3 {
 		byteToNormal(in[2]),
 	)
 }
+
+// PackCoarse24Uint32 is PackCoarse24 with the bytes combined into a single
+// word, x in the lowest 8 bits and z in bits 16 through 23. The upper 8 bits
+// are unused.
+func PackCoarse24Uint32(v vector.Vector3) uint32 {
+	return uint32(normalToByte(v.X())) |
+		(uint32(normalToByte(v.Y())) << 8) |
+		(uint32(normalToByte(v.Z())) << 16)
+}
+
+// UnpackCoarse24Uint32 is UnpackCoarse24 for a code previously packed with
+// PackCoarse24Uint32. The upper 8 bits are ignored.
+func UnpackCoarse24Uint32(everything uint32) vector.Vector3 {
+	return vector.NewVector3(
+		byteToNormal(byte(everything)),
+		byteToNormal(byte(everything>>8)),
+		byteToNormal(byte(everything>>16)),
+	)
+}
@@ -0,0 +1,149 @@
+package unitpacking
+
+import "github.com/EliCDavis/vector"
+
+// Codec is a packing method that converts a vector into a fixed number of
+// bytes and back again.
+type Codec interface {
+	// Name is the short identifier of the packing method, e.g. "oct24".
+	Name() string
+
+	// Size is the number of bytes every packed vector occupies.
+	Size() int
+
+	// Pack converts the vector into Size() bytes.
+	Pack(v vector.Vector3) []byte
+
+	// Unpack converts Size() bytes back into a vector.
+	Unpack(b []byte) vector.Vector3
+}
+
+// NullCodec is a Codec that reserves one of its codes to mean "no vector".
+// Pack never produces the null code, not even for vectors with no direction.
+type NullCodec interface {
+	Codec
+
+	// Null returns the reserved code.
+	Null() []byte
+}
+
+// FieldCodec is a Codec whose code is several little endian words written one
+// after the other, rather than a single word.
+type FieldCodec interface {
+	Codec
+
+	// Fields returns the size in bytes of each word, in the order they are
+	// written. The sizes add up to Size().
+	Fields() []int
+}
+
+// codecFields returns the size of each word in the codec's code.
+func codecFields(c Codec) []int {
+	if fieldCodec, ok := c.(FieldCodec); ok {
+		return fieldCodec.Fields()
+	}
+	return []int{c.Size()}
+}
+
+type codec struct {
+	name   string
+	size   int
+	pack   func(v vector.Vector3) []byte
+	unpack func(b []byte) vector.Vector3
+	null   []byte
+}
+
+func (c codec) Name() string                   { return c.name }
+func (c codec) Size() int                      { return c.size }
+func (c codec) Pack(v vector.Vector3) []byte   { return c.pack(v) }
+func (c codec) Unpack(b []byte) vector.Vector3 { return c.unpack(b) }
+func (c codec) Null() []byte                   { return append([]byte(nil), c.null...) }
+
+var (
+	// Alg24 wraps PackAlg24/UnpackAlg24, with all zero bytes as the null code
+	Alg24 NullCodec = codec{"alg24", 3, PackAlg24, UnpackAlg24, []byte{0, 0, 0}}
+
+	// Coarse24 wraps PackCoarse24/UnpackCoarse24, with all zero bytes as the
+	// null code
+	Coarse24 NullCodec = codec{"coarse24", 3, PackCoarse24, UnpackCoarse24, []byte{0, 0, 0}}
+
+	// Oct16 wraps PackOct16/UnpackOct16. Coordinates are never written as 0,
+	// so all zero bytes are the null code
+	Oct16 NullCodec = codec{"oct16", 2, PackOct16, UnpackOct16, []byte{0, 0}}
+
+	// Oct24 wraps PackOct24/UnpackOct24, with all zero bytes as the null code
+	Oct24 NullCodec = codec{"oct24", 3, PackOct24, UnpackOct24, []byte{0, 0, 0}}
+
+	// Oct32 wraps PackOct32/UnpackOct32, with all zero bytes as the null code
+	Oct32 NullCodec = codec{"oct32", 4, PackOct32, UnpackOct32, []byte{0, 0, 0, 0}}
+
+	// OctQuad16 wraps PackOctQuad16/UnpackOctQuad16, with the bottom left
+	// corner cell as the null code
+	OctQuad16 NullCodec = codec{"octquad16", 2, PackOctQuad16, UnpackOctQuad16, octQuadNull(2)}
+
+	// OctQuad24 wraps PackOctQuad24/UnpackOctQuad24, with the bottom left
+	// corner cell as the null code
+	OctQuad24 NullCodec = codec{"octquad24", 3, PackOctQuad24, UnpackOctQuad24, octQuadNull(3)}
+
+	// OctQuad32 wraps PackOctQuad32/UnpackOctQuad32, with the bottom left
+	// corner cell as the null code
+	OctQuad32 NullCodec = codec{"octquad32", 4, PackOctQuad32, UnpackOctQuad32, octQuadNull(4)}
+)
+
+// The hemi-octahedral methods only cover the upper hemisphere, so they are
+// left out of Codecs.
+var (
+	// HemiOct16 wraps PackHemiOct16/UnpackHemiOct16, with all zero bytes as
+	// the null code
+	HemiOct16 NullCodec = codec{"hemioct16", 2, PackHemiOct16, UnpackHemiOct16, []byte{0, 0}}
+
+	// HemiOct24 wraps PackHemiOct24/UnpackHemiOct24, with all zero bytes as
+	// the null code
+	HemiOct24 NullCodec = codec{"hemioct24", 3, PackHemiOct24, UnpackHemiOct24, []byte{0, 0, 0}}
+
+	// HemiOct32 wraps PackHemiOct32/UnpackHemiOct32, with all zero bytes as
+	// the null code
+	HemiOct32 NullCodec = codec{"hemioct32", 4, PackHemiOct32, UnpackHemiOct32, []byte{0, 0, 0, 0}}
+)
+
+// Codecs returns every packing method the library implements.
+func Codecs() []Codec {
+	return []Codec{
+		Alg24,
+		Coarse24,
+		Oct16,
+		Oct24,
+		Oct32,
+		OctQuad16,
+		OctQuad24,
+		OctQuad32,
+	}
+}
+
+// HemiCodecs returns the hemi-octahedral packing methods, which only cover
+// the upper hemisphere.
+func HemiCodecs() []Codec {
+	return []Codec{
+		HemiOct16,
+		HemiOct24,
+		HemiOct32,
+	}
+}
+
+// AllCodecs returns every packing method in Codecs followed by every one in
+// HemiCodecs.
+func AllCodecs() []Codec {
+	return append(Codecs(), HemiCodecs()...)
+}
+
+// CodecByName looks up one of the library's packing methods by its name,
+// including the hemi-octahedral ones. Returns false if no method goes by that
+// name.
+func CodecByName(name string) (Codec, bool) {
+	for _, c := range AllCodecs() {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return nil, false
+}
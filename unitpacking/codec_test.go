@@ -0,0 +1,29 @@
+package unitpacking_test
+
+import (
+	"testing"
+
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecs_SizeMatchesPackedLength(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, tc := range testVectors {
+				assert.Len(t, codec.Pack(tc.Normalized()), codec.Size())
+			}
+		})
+	}
+}
+
+func TestCodecByName(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		found, ok := unitpacking.CodecByName(codec.Name())
+		assert.True(t, ok)
+		assert.Equal(t, codec.Name(), found.Name())
+	}
+
+	_, ok := unitpacking.CodecByName("oct12")
+	assert.False(t, ok)
+}
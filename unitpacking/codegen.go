@@ -0,0 +1,55 @@
+package unitpacking
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedCodec is returned when code generation is requested for a
+// codec that isn't one of the library's packing methods.
+var ErrUnsupportedCodec = errors.New("code generation is only supported for the library's codecs")
+
+type codegenKind int
+
+const (
+	algKind codegenKind = iota
+	coarseKind
+	octKind
+	octQuadKind
+)
+
+// codegenSpec describes one of the library's codecs to the code generators.
+type codegenSpec struct {
+	// name matches the Go function names, e.g. "OctQuad24"
+	name string
+	kind codegenKind
+	size int
+}
+
+// bits is the number of bits each oct coordinate is written with, which is
+// also the number of levels of an octquad tree.
+func (s codegenSpec) bits() int {
+	return s.size * 4
+}
+
+var codegenSpecs = map[string]codegenSpec{
+	"alg24":     {"Alg24", algKind, 3},
+	"coarse24":  {"Coarse24", coarseKind, 3},
+	"oct16":     {"Oct16", octKind, 2},
+	"oct24":     {"Oct24", octKind, 3},
+	"oct32":     {"Oct32", octKind, 4},
+	"octquad16": {"OctQuad16", octQuadKind, 2},
+	"octquad24": {"OctQuad24", octQuadKind, 3},
+	"octquad32": {"OctQuad32", octQuadKind, 4},
+}
+
+// specFor returns how code generators should write out the codec, or
+// ErrUnsupportedCodec if it isn't one of the library's packing methods.
+func specFor(c Codec) (codegenSpec, error) {
+	_, builtin := c.(codec)
+	spec, ok := codegenSpecs[c.Name()]
+	if !builtin || !ok {
+		return codegenSpec{}, fmt.Errorf("%w: %s", ErrUnsupportedCodec, c.Name())
+	}
+	return spec, nil
+}
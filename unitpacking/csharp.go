@@ -0,0 +1,647 @@
+package unitpacking
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const csharpHeader = `// Code generated by unitpacking. DO NOT EDIT.
+
+using System;
+
+namespace %s
+{
+    /// <summary>
+    /// A vector of doubles, the precision every codec packs from and unpacks
+    /// to.
+    /// </summary>
+    public struct Vector3d
+    {
+        public double X;
+        public double Y;
+        public double Z;
+
+        public Vector3d(double x, double y, double z)
+        {
+            X = x;
+            Y = y;
+            Z = z;
+        }
+
+#if UNITY_5_3_OR_NEWER
+        public static implicit operator Vector3d(UnityEngine.Vector3 v)
+        {
+            return new Vector3d(v.x, v.y, v.z);
+        }
+
+        public static explicit operator UnityEngine.Vector3(Vector3d v)
+        {
+            return new UnityEngine.Vector3((float)v.X, (float)v.Y, (float)v.Z);
+        }
+#endif
+
+        public override string ToString()
+        {
+            return "(" + X.ToString("R") + ", " + Y.ToString("R") + ", " + Z.ToString("R") + ")";
+        }
+    }
+
+    /// <summary>
+    /// Packs and unpacks unit vectors exactly like the Go unitpacking library.
+    /// Codes are written little endian, and the word forms match the Go
+    /// Pack*Uint32/Pack*Uint16 functions.
+    /// </summary>
+    public static class UnitPacking
+    {
+        // Rounds half away from zero like Go's math.Round. Math.Round defaults
+        // to rounding half to even, and MidpointRounding.AwayFromZero isn't
+        // exact on every runtime.
+        private static double Round(double x)
+        {
+            double t = Math.Truncate(x);
+            if (Math.Abs(x - t) >= 0.5)
+            {
+                t += Math.Sign(x);
+            }
+            return t;
+        }
+
+        private static double Clamp(double num, double min, double max)
+        {
+            if (num < min)
+            {
+                return min;
+            }
+            if (num > max)
+            {
+                return max;
+            }
+            return num;
+        }
+
+        private static double ClampUnit(double num)
+        {
+            return double.IsNaN(num) ? 0 : Clamp(num, -1.0, 1.0);
+        }
+
+        private static uint ReadWord(byte[] b, int offset, int size)
+        {
+            uint word = 0;
+            for (int i = 0; i < size; i++)
+            {
+                word |= (uint)b[offset + i] << (i * 8);
+            }
+            return word;
+        }
+
+        private static byte[] WriteWord(uint word, int size)
+        {
+            byte[] b = new byte[size];
+            for (int i = 0; i < size; i++)
+            {
+                b[i] = (byte)(word >> (i * 8));
+            }
+            return b;
+        }
+`
+
+const csharpOctHelpers = `
+        private static double SignNotZero(double v)
+        {
+            return v < 0.0 ? -1.0 : 1.0;
+        }
+
+        private static void MapToOctUV(Vector3d v, out double u, out double w)
+        {
+            double inv = 1.0 / (Math.Abs(v.X) + Math.Abs(v.Y) + Math.Abs(v.Z));
+            double px = v.X * inv;
+            double py = v.Y * inv;
+            if (v.Z > 0)
+            {
+                u = px;
+                w = py;
+                return;
+            }
+
+            u = SignNotZero(px) * (1.0 - Math.Abs(py));
+            w = SignNotZero(py) * (1.0 - Math.Abs(px));
+        }
+
+        private static Vector3d FromOctUV(double u, double w)
+        {
+            double x = u;
+            double y = w;
+            double z = 1.0 - Math.Abs(u) - Math.Abs(w);
+            if (z < 0)
+            {
+                x = (1.0 - Math.Abs(w)) * SignNotZero(u);
+                y = (1.0 - Math.Abs(u)) * SignNotZero(w);
+            }
+
+            double inv = 1.0 / Math.Sqrt((x * x) + (y * y) + (z * z));
+            return new Vector3d(x * inv, y * inv, z * inv);
+        }
+
+        private static double Dot(Vector3d a, Vector3d b)
+        {
+            return (a.X * b.X) + (a.Y * b.Y) + (a.Z * b.Z);
+        }
+`
+
+const csharpOctPreciseHelper = `
+        private static void MapToOctUVPrecise(Vector3d v, int n, out double u, out double w)
+        {
+            double s;
+            double t;
+            MapToOctUV(v, out s, out t);
+            if (double.IsNaN(s) || double.IsNaN(t))
+            {
+                u = 0;
+                w = 0;
+                return;
+            }
+
+            double m = (double)(1 << ((n / 2) - 1)) - 1.0;
+            double baseX = Math.Floor(Clamp(s, -1.0, 1.0) * m);
+            double baseY = Math.Floor(Clamp(t, -1.0, 1.0) * m);
+
+            u = baseX / m;
+            w = baseY / m;
+            double highestCosine = Dot(FromOctUV(u, w), v);
+
+            for (double i = 0.0; i <= 1; i++)
+            {
+                for (double j = 0.0; j <= 1; j++)
+                {
+                    if (i == 0 && j == 0)
+                    {
+                        continue;
+                    }
+                    if (baseX + i > m || baseY + j > m)
+                    {
+                        continue;
+                    }
+
+                    double candidateU = (baseX + i) / m;
+                    double candidateW = (baseY + j) / m;
+                    double cosine = Dot(FromOctUV(candidateU, candidateW), v);
+                    if (cosine > highestCosine)
+                    {
+                        u = candidateU;
+                        w = candidateW;
+                        highestCosine = cosine;
+                    }
+                }
+            }
+
+            // Directions on the seams of the lower hemisphere always get the
+            // same code.
+            if (Math.Abs(u) == 1)
+            {
+                w = Math.Abs(w);
+            }
+            if (Math.Abs(w) == 1)
+            {
+                u = Math.Abs(u);
+            }
+        }
+`
+
+const csharpQuadHelpers = `
+        private static uint QuadCode(double u, double w, int levels)
+        {
+            double minX = -1.0;
+            double minY = -1.0;
+            double maxX = 1.0;
+            double maxY = 1.0;
+
+            uint code = 0;
+            for (int i = 0; i < levels; i++)
+            {
+                double midX = (maxX + minX) / 2;
+                double midY = (maxY + minY) / 2;
+
+                uint dir;
+                if (u < midX)
+                {
+                    maxX = midX;
+                    if (w < midY)
+                    {
+                        maxY = midY;
+                        dir = 2;
+                    }
+                    else
+                    {
+                        minY = midY;
+                        dir = 0;
+                    }
+                }
+                else
+                {
+                    minX = midX;
+                    if (w < midY)
+                    {
+                        maxY = midY;
+                        dir = 3;
+                    }
+                    else
+                    {
+                        minY = midY;
+                        dir = 1;
+                    }
+                }
+
+                code = (code << 2) | dir;
+            }
+            return code;
+        }
+
+        private static void QuadDecode(uint code, int levels, out double u, out double w)
+        {
+            double multiplyer = 0.5;
+            u = 0.0;
+            w = 0.0;
+            for (int i = levels - 1; i >= 0; i--)
+            {
+                uint dir = (code >> (i * 2)) & 3;
+                u += (dir & 1) == 1 ? multiplyer : -multiplyer;
+                w += dir < 2 ? multiplyer : -multiplyer;
+                multiplyer /= 2.0;
+            }
+        }
+
+        // Every level of the quad tree going to the bottom left is the null
+        // code, and is moved one cell to the right.
+        private static uint AvoidOctQuadNull(uint code, int levels)
+        {
+            uint nullCode = 0;
+            for (int i = 0; i < levels; i++)
+            {
+                nullCode = (nullCode << 2) | 2;
+            }
+            return code == nullCode ? code | 3 : code;
+        }
+`
+
+const csharpAlg24 = `
+        public static byte[] PackAlg24(Vector3d v)
+        {
+            return WriteWord(PackAlg24Uint32(v), 3);
+        }
+
+        public static uint PackAlg24Uint32(Vector3d v)
+        {
+            uint x = (uint)(Round(ClampUnit(v.X) * 2047) + 2048);
+            uint y = (uint)(Round(ClampUnit(v.Y) * 1023) + 1024);
+
+            uint zPositive = 0;
+            if (v.Z >= 0.0 || AlgZ((x - 2048.0) / 2047.0, (y - 1024.0) / 1023.0) == 0)
+            {
+                zPositive = 1;
+            }
+
+            return (x << 12) | (y << 1) | zPositive;
+        }
+
+        public static Vector3d UnpackAlg24(byte[] b, int offset = 0)
+        {
+            return UnpackAlg24Uint32(ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackAlg24Uint32(uint code)
+        {
+            double x = (((code >> 12) & 0xFFF) - 2048.0) / 2047.0;
+            double y = (((code >> 1) & 0x7FF) - 1024.0) / 1023.0;
+            double z = AlgZ(x, y);
+            if ((code & 1) != 1)
+            {
+                z *= -1;
+            }
+            return new Vector3d(x, y, z);
+        }
+
+        private static double AlgZ(double x, double y)
+        {
+            double z = Math.Sqrt(1.0 - (x * x) - (y * y));
+            return double.IsNaN(z) ? 0 : z;
+        }
+`
+
+const csharpCoarse24 = `
+        public static byte[] PackCoarse24(Vector3d v)
+        {
+            return WriteWord(PackCoarse24Uint32(v), 3);
+        }
+
+        public static uint PackCoarse24Uint32(Vector3d v)
+        {
+            return (uint)NormalToByte(v.X) | ((uint)NormalToByte(v.Y) << 8) | ((uint)NormalToByte(v.Z) << 16);
+        }
+
+        public static Vector3d UnpackCoarse24(byte[] b, int offset = 0)
+        {
+            return UnpackCoarse24Uint32(ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackCoarse24Uint32(uint code)
+        {
+            return new Vector3d(
+                ByteToNormal((byte)code),
+                ByteToNormal((byte)(code >> 8)),
+                ByteToNormal((byte)(code >> 16))
+            );
+        }
+
+        private static byte NormalToByte(double n)
+        {
+            return (byte)(Round(ClampUnit(n) * 127.0) + 128);
+        }
+
+        private static double ByteToNormal(byte b)
+        {
+            return (b - 128.0) / 127.0;
+        }
+`
+
+// Arguments are the name, word type, size in bytes, bits per coordinate, the
+// coordinate mask, the coordinate offset and its max value.
+const csharpOct = `
+        public static byte[] Pack%[1]s(Vector3d v)
+        {
+            return WriteWord(Pack%[1]s%[2]s(v), %[3]d);
+        }
+
+        public static %[8]s Pack%[1]s%[2]s(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUVPrecise(v, %[9]d, out u, out w);
+
+            uint x = (uint)(Round(u * %[7]d) + %[6]d);
+            uint y = (uint)(Round(w * %[7]d) + %[6]d);
+            return (%[8]s)((x << %[4]d) | y);
+        }
+
+        public static Vector3d Unpack%[1]s(byte[] b, int offset = 0)
+        {
+            return Unpack%[1]s%[2]s((%[8]s)ReadWord(b, offset, %[3]d));
+        }
+
+        public static Vector3d Unpack%[1]s%[2]s(%[8]s code)
+        {
+            uint rawX = ((uint)code >> %[4]d) & 0x%[5]X;
+            uint rawY = (uint)code & 0x%[5]X;
+
+            double x = Clamp((rawX - %[6]d.0) / %[7]d.0, -1.0, 1.0);
+            double y = Clamp((rawY - %[6]d.0) / %[7]d.0, -1.0, 1.0);
+            return FromOctUV(x, y);
+        }
+`
+
+// Arguments are the name, word type suffix, size in bytes, quad tree levels,
+// the word mask and the word type.
+const csharpOctQuad = `
+        public static byte[] Pack%[1]s(Vector3d v)
+        {
+            return WriteWord(Pack%[1]s%[2]s(v), %[3]d);
+        }
+
+        public static %[6]s Pack%[1]s%[2]s(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUV(v, out u, out w);
+            return (%[6]s)AvoidOctQuadNull(QuadCode(u, w, %[4]d), %[4]d);
+        }
+
+        public static Vector3d Unpack%[1]s(byte[] b, int offset = 0)
+        {
+            return Unpack%[1]s%[2]s((%[6]s)ReadWord(b, offset, %[3]d));
+        }
+
+        public static Vector3d Unpack%[1]s%[2]s(%[6]s code)
+        {
+            double u;
+            double w;
+            QuadDecode((uint)code & 0x%[5]X, %[4]d, out u, out w);
+            return FromOctUV(u, w);
+        }
+`
+
+func csharpWordType(size int) (suffix, typ string) {
+	if size == 2 {
+		return "Uint16", "ushort"
+	}
+	return "Uint32", "uint"
+}
+
+func csharpCodec(spec codegenSpec) string {
+	suffix, typ := csharpWordType(spec.size)
+	switch spec.kind {
+	case algKind:
+		return csharpAlg24
+
+	case coarseKind:
+		return csharpCoarse24
+
+	case octKind:
+		bits := spec.bits()
+		half := 1 << (bits - 1)
+		return fmt.Sprintf(csharpOct, spec.name, suffix, spec.size, bits, (1<<bits)-1, half, half-1, typ, spec.size*8)
+	}
+
+	levels := spec.bits()
+	return fmt.Sprintf(csharpOctQuad, spec.name, suffix, spec.size, levels, (uint64(1)<<(levels*2))-1, typ)
+}
+
+// WriteCSharp writes a self contained C# file with Pack and Unpack methods for
+// each of the codecs given, inside of a static UnitPacking class in the
+// namespace provided. The C# implementation is a line for line port of the Go
+// one, producing the exact same bytes and vectors on any runtime with IEEE
+// 754 doubles, including Unity's.
+func WriteCSharp(w io.Writer, namespace string, codecs ...Codec) error {
+	if namespace == "" {
+		return fmt.Errorf("a namespace is required")
+	}
+
+	bodies := make([]string, 0, len(codecs))
+	oct := false
+	octPrecise := false
+	quad := false
+	for _, c := range codecs {
+		spec, err := specFor(c)
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, csharpCodec(spec))
+
+		oct = oct || spec.kind == octKind || spec.kind == octQuadKind
+		octPrecise = octPrecise || spec.kind == octKind
+		quad = quad || spec.kind == octQuadKind
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, csharpHeader, namespace)
+	if oct {
+		sb.WriteString(csharpOctHelpers)
+	}
+	if octPrecise {
+		sb.WriteString(csharpOctPreciseHelper)
+	}
+	if quad {
+		sb.WriteString(csharpQuadHelpers)
+	}
+	for _, body := range bodies {
+		sb.WriteString(body)
+	}
+	sb.WriteString("    }\n}\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+const csharpTestsHeader = `// Code generated by unitpacking. DO NOT EDIT.
+
+using System;
+using System.Collections.Generic;
+using System.Globalization;
+using System.IO;
+
+namespace %[1]s
+{
+    /// <summary>
+    /// Checks the generated UnitPacking class against the test vectors shared
+    /// with the Go library. Each line of the file holds a codec, the input
+    /// vector, the packed bytes, and the vector they unpack to. Vectors are
+    /// written as the hex bits of each double, and must match exactly.
+    /// </summary>
+    public static class UnitPackingTestVectors
+    {
+        public const string DefaultPath = %[2]q;
+
+        private static double Bits(string hex)
+        {
+            return BitConverter.Int64BitsToDouble(unchecked((long)ulong.Parse(hex, NumberStyles.HexNumber, CultureInfo.InvariantCulture)));
+        }
+
+        private static string Hex(byte[] b)
+        {
+            return BitConverter.ToString(b).Replace("-", "").ToLowerInvariant();
+        }
+
+        private static byte[] FromHex(string hex)
+        {
+            byte[] b = new byte[hex.Length / 2];
+            for (int i = 0; i < b.Length; i++)
+            {
+                b[i] = byte.Parse(hex.Substring(i * 2, 2), NumberStyles.HexNumber, CultureInfo.InvariantCulture);
+            }
+            return b;
+        }
+
+        private static bool SameBits(Vector3d a, Vector3d b)
+        {
+            return BitConverter.DoubleToInt64Bits(a.X) == BitConverter.DoubleToInt64Bits(b.X) &&
+                BitConverter.DoubleToInt64Bits(a.Y) == BitConverter.DoubleToInt64Bits(b.Y) &&
+                BitConverter.DoubleToInt64Bits(a.Z) == BitConverter.DoubleToInt64Bits(b.Z);
+        }
+
+        /// <summary>
+        /// Returns a description of every test vector the generated codecs
+        /// don't reproduce exactly. Lines for codecs that weren't generated
+        /// are skipped.
+        /// </summary>
+        public static List<string> Verify(string path)
+        {
+            List<string> failures = new List<string>();
+            int verified = 0;
+
+            foreach (string raw in File.ReadAllLines(path))
+            {
+                string line = raw.Trim();
+                if (line.Length == 0 || line[0] == '#')
+                {
+                    continue;
+                }
+
+                string[] fields = line.Split(' ');
+                Vector3d input = new Vector3d(Bits(fields[1]), Bits(fields[2]), Bits(fields[3]));
+                byte[] expectedPacked = FromHex(fields[4]);
+                Vector3d expectedUnpacked = new Vector3d(Bits(fields[5]), Bits(fields[6]), Bits(fields[7]));
+
+                byte[] packed;
+                Vector3d unpacked;
+                switch (fields[0])
+                {
+`
+
+const csharpTestsCase = `                    case %[1]q:
+                        packed = UnitPacking.Pack%[2]s(input);
+                        unpacked = UnitPacking.Unpack%[2]s(expectedPacked);
+                        break;
+`
+
+const csharpTestsFooter = `                    default:
+                        continue;
+                }
+
+                verified++;
+                if (Hex(packed) != fields[4])
+                {
+                    failures.Add(fields[0] + " packed " + input + " to " + Hex(packed) + ", expected " + fields[4]);
+                }
+                if (!SameBits(unpacked, expectedUnpacked))
+                {
+                    failures.Add(fields[0] + " unpacked " + fields[4] + " to " + unpacked + ", expected " + expectedUnpacked);
+                }
+            }
+
+            if (verified == 0)
+            {
+                failures.Add("no test vectors found for the generated codecs in " + path);
+            }
+            return failures;
+        }
+    }
+
+#if !UNITPACKING_NO_NUNIT
+    [NUnit.Framework.TestFixture]
+    public class UnitPackingTests
+    {
+        [NUnit.Framework.Test]
+        public void MatchesTestVectors()
+        {
+            List<string> failures = UnitPackingTestVectors.Verify(UnitPackingTestVectors.DefaultPath);
+            NUnit.Framework.Assert.IsEmpty(failures, string.Join("\n", failures.ToArray()));
+        }
+    }
+#endif
+}
+`
+
+// WriteCSharpTests writes NUnit tests, as used by Unity's test runner, that
+// check a class written by WriteCSharp with the same codecs against the test
+// vectors at testVectorsPath. The vectors live in the library's
+// testdata/vectors.txt, the same file the Go tests check against. Define
+// UNITPACKING_NO_NUNIT to leave out the NUnit fixture and call
+// UnitPackingTestVectors.Verify directly.
+func WriteCSharpTests(w io.Writer, namespace, testVectorsPath string, codecs ...Codec) error {
+	if namespace == "" {
+		return fmt.Errorf("a namespace is required")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, csharpTestsHeader, namespace, testVectorsPath)
+	for _, c := range codecs {
+		spec, err := specFor(c)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, csharpTestsCase, c.Name(), spec.name)
+	}
+	sb.WriteString(csharpTestsFooter)
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
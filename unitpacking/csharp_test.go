@@ -0,0 +1,109 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCSharp_Golden(t *testing.T) {
+	var code bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCSharp(&code, "Recolude", unitpacking.Codecs()...))
+
+	var tests bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCSharpTests(&tests, "Recolude", "vectors.txt", unitpacking.Codecs()...))
+
+	for file, generated := range map[string][]byte{"UnitPacking.cs": code.Bytes(), "UnitPackingTests.cs": tests.Bytes()} {
+		golden := filepath.Join("testdata", "csharp", file)
+		if *update {
+			assert.NoError(t, os.WriteFile(golden, generated, 0644))
+		}
+
+		expected, err := os.ReadFile(golden)
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(generated))
+	}
+}
+
+func TestWriteCSharp_Unsupported(t *testing.T) {
+	magnitude, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+
+	err = unitpacking.WriteCSharp(&bytes.Buffer{}, "Recolude", magnitude)
+	assert.True(t, errors.Is(err, unitpacking.ErrUnsupportedCodec))
+
+	err = unitpacking.WriteCSharpTests(&bytes.Buffer{}, "Recolude", "vectors.txt", unitpacking.Oct24, magnitude)
+	assert.True(t, errors.Is(err, unitpacking.ErrUnsupportedCodec))
+
+	assert.Error(t, unitpacking.WriteCSharp(&bytes.Buffer{}, "", unitpacking.Oct24))
+}
+
+const dotnetProject = `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <OutputType>Exe</OutputType>
+    <TargetFramework>net8.0</TargetFramework>
+    <DefineConstants>$(DefineConstants);UNITPACKING_NO_NUNIT</DefineConstants>
+  </PropertyGroup>
+</Project>
+`
+
+const dotnetProgram = `using System;
+
+public static class Program
+{
+    public static int Main(string[] args)
+    {
+        var failures = Recolude.UnitPackingTestVectors.Verify(args[0]);
+        foreach (var failure in failures)
+        {
+            Console.WriteLine(failure);
+        }
+        return failures.Count == 0 ? 0 : 1;
+    }
+}
+`
+
+// Compiles the generated C# and runs it against the shared test vectors.
+// Skipped when the dotnet SDK isn't installed.
+func TestWriteCSharp_Dotnet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a dotnet project")
+	}
+
+	dotnet, err := exec.LookPath("dotnet")
+	if err != nil {
+		t.Skip("dotnet not found")
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"check.csproj": []byte(dotnetProject),
+		"Program.cs":   []byte(dotnetProgram),
+	}
+
+	var code bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCSharp(&code, "Recolude", unitpacking.Codecs()...))
+	files["UnitPacking.cs"] = code.Bytes()
+
+	var tests bytes.Buffer
+	assert.NoError(t, unitpacking.WriteCSharpTests(&tests, "Recolude", "vectors.txt", unitpacking.Codecs()...))
+	files["UnitPackingTests.cs"] = tests.Bytes()
+
+	for name, contents := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), contents, 0644))
+	}
+
+	vectors, err := filepath.Abs(testVectorsPath)
+	assert.NoError(t, err)
+
+	cmd := exec.Command(dotnet, "run", "--project", dir, "--", vectors)
+	cmd.Env = append(os.Environ(), "DOTNET_CLI_TELEMETRY_OPTOUT=1", "DOTNET_NOLOGO=1")
+	out, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
@@ -1,6 +1,10 @@
 package unitpacking_test
 
-import "github.com/EliCDavis/vector"
+import (
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
 
 var testVectors []vector.Vector3 = []vector.Vector3{
 	vector.NewVector3(0, 1, 2),
@@ -32,3 +36,32 @@ var quadTestVectors []vector.Vector2 = []vector.Vector2{
 	vector.NewVector2(0, -0.25),
 	vector.NewVector2(0.12, -.94),
 }
+
+// edgeCaseVectors sit on the seams and poles of the octahedron, and include
+// signed zeros and components too small or too large to square.
+var edgeCaseVectors []vector.Vector3 = []vector.Vector3{
+	vector.NewVector3(0, 0, 1),
+	vector.NewVector3(0, 0, -1),
+	vector.NewVector3(math.Copysign(0, -1), math.Copysign(0, -1), -1),
+	vector.NewVector3(math.Copysign(0, -1), 0, 1),
+	vector.NewVector3(0.5, 0.5, 0),
+	vector.NewVector3(-0.5, 0.5, 0),
+	vector.NewVector3(0.5, -0.5, math.Copysign(0, -1)),
+	vector.NewVector3(1, 0, math.Copysign(0, -1)),
+	vector.NewVector3(0, -1, math.Copysign(0, -1)),
+	vector.NewVector3(0.3, 0, -0.7),
+	vector.NewVector3(0.3, math.Copysign(0, -1), -0.7),
+	vector.NewVector3(0, -0.6, -0.4),
+	vector.NewVector3(math.Copysign(0, -1), -0.6, -0.4),
+	vector.NewVector3(-0.0001, -0.0001, -1),
+	vector.NewVector3(-0.004, -0.004, -1),
+	vector.NewVector3(0.0001, -0.0001, -1),
+	vector.NewVector3(1, 1, math.Nextafter(0, -1)),
+	vector.NewVector3(5e-324, 0, 0),
+	vector.NewVector3(0, -5e-324, 5e-324),
+	vector.NewVector3(math.SmallestNonzeroFloat64, math.SmallestNonzeroFloat64, -math.SmallestNonzeroFloat64),
+	vector.NewVector3(2.2250738585072014e-308, 0, -2.2250738585072014e-308),
+	vector.NewVector3(1e-200, 1e-200, 1e-200),
+	vector.NewVector3(1e300, -1e300, 1e300),
+	vector.NewVector3(math.MaxFloat64, 0, 0),
+}
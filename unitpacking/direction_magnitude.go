@@ -0,0 +1,277 @@
+package unitpacking
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+// MagnitudeQuantization is the method used to store the length of a vector
+// inside of 16 bits.
+type MagnitudeQuantization int
+
+const (
+	// LinearMagnitude spreads the 16bit codes evenly across the magnitude
+	// range.
+	LinearMagnitude MagnitudeQuantization = iota
+
+	// LogarithmicMagnitude spreads the 16bit codes evenly across the log of
+	// the magnitude range, giving small magnitudes the same relative
+	// precision as large ones. Requires a minimum magnitude above 0.
+	LogarithmicMagnitude
+
+	// Float16Magnitude writes the magnitude as an IEEE 754 half precision
+	// float, ignoring the magnitude range other than clamping to it.
+	Float16Magnitude
+)
+
+func (q MagnitudeQuantization) String() string {
+	switch q {
+	case LinearMagnitude:
+		return "linear"
+	case LogarithmicMagnitude:
+		return "log"
+	case Float16Magnitude:
+		return "float16"
+	}
+	return fmt.Sprintf("MagnitudeQuantization(%d)", int(q))
+}
+
+// maxMagnitudeCode is the largest magnitude code in use, with code 0 reserved for zero length
+// vectors.
+const maxMagnitudeCode = 0xFFFF
+
+// DirectionMagnitude packs vectors of any length by splitting them into a
+// direction, packed with a unit vector codec, and a magnitude, quantized into
+// 16 bits. The magnitude is written after the direction.
+//
+// Zero length and non-finite vectors are written as a direction of all zero
+// bytes and a magnitude code of 0, and always unpack to the zero vector.
+type DirectionMagnitude struct {
+	direction    Codec
+	quantization MagnitudeQuantization
+	min          float64
+	max          float64
+}
+
+// NewDirectionMagnitude builds a codec that packs directions with the given
+// unit vector codec and magnitudes between min and max with the given
+// quantization. Magnitudes outside of the range are clamped to it.
+func NewDirectionMagnitude(direction Codec, quantization MagnitudeQuantization, min, max float64) (DirectionMagnitude, error) {
+	if direction == nil {
+		return DirectionMagnitude{}, fmt.Errorf("direction codec required")
+	}
+
+	if math.IsNaN(min) || math.IsNaN(max) || min < 0 || max <= min || math.IsInf(max, 0) {
+		return DirectionMagnitude{}, fmt.Errorf("invalid magnitude range [%g, %g]", min, max)
+	}
+
+	switch quantization {
+	case LinearMagnitude, Float16Magnitude:
+	case LogarithmicMagnitude:
+		if min <= 0 {
+			return DirectionMagnitude{}, fmt.Errorf("logarithmic magnitude requires a minimum above 0, got %g", min)
+		}
+	default:
+		return DirectionMagnitude{}, fmt.Errorf("unknown magnitude quantization: %s", quantization)
+	}
+
+	return DirectionMagnitude{
+		direction:    direction,
+		quantization: quantization,
+		min:          min,
+		max:          max,
+	}, nil
+}
+
+// Name is the direction codec's name followed by the magnitude quantization,
+// e.g. "oct24+log16".
+func (dm DirectionMagnitude) Name() string {
+	return fmt.Sprintf("%s+%s16", dm.direction.Name(), dm.quantization)
+}
+
+// Size is the size of the direction codec plus 2 bytes for the magnitude.
+func (dm DirectionMagnitude) Size() int {
+	return dm.direction.Size() + 2
+}
+
+// Fields is the direction codec's words followed by the 2 byte magnitude.
+func (dm DirectionMagnitude) Fields() []int {
+	return append(codecFields(dm.direction), 2)
+}
+
+// Pack converts the vector into its packed direction followed by its
+// magnitude.
+func (dm DirectionMagnitude) Pack(v vector.Vector3) []byte {
+	out := make([]byte, dm.Size())
+
+	length := v.Length()
+	if length == 0 || math.IsNaN(length) || math.IsInf(length, 0) {
+		return out
+	}
+
+	code := dm.magnitudeToCode(length)
+	if code == 0 {
+		return out
+	}
+
+	copy(out, dm.direction.Pack(v.DivByConstant(length)))
+	out[len(out)-2] = byte(code & 0xFF)
+	out[len(out)-1] = byte(code >> 8)
+	return out
+}
+
+// Unpack converts the packed direction and magnitude back into a vector.
+func (dm DirectionMagnitude) Unpack(b []byte) vector.Vector3 {
+	code := uint16(b[dm.direction.Size()]) | (uint16(b[dm.direction.Size()+1]) << 8)
+	if code == 0 {
+		return vector.Vector3Zero()
+	}
+	return dm.direction.Unpack(b[:dm.direction.Size()]).MultByConstant(dm.codeToMagnitude(code))
+}
+
+func (dm DirectionMagnitude) magnitudeToCode(m float64) uint16 {
+	m = Clamp(m, dm.min, dm.max)
+
+	switch dm.quantization {
+	case LogarithmicMagnitude:
+		t := (math.Log(m) - math.Log(dm.min)) / (math.Log(dm.max) - math.Log(dm.min))
+		return uint16(math.Round(t*(maxMagnitudeCode-1))) + 1
+
+	case Float16Magnitude:
+		return float64ToFloat16(m)
+	}
+
+	t := (m - dm.min) / (dm.max - dm.min)
+	return uint16(math.Round(t*(maxMagnitudeCode-1))) + 1
+}
+
+func (dm DirectionMagnitude) codeToMagnitude(code uint16) float64 {
+	switch dm.quantization {
+	case LogarithmicMagnitude:
+		t := float64(code-1) / (maxMagnitudeCode - 1)
+		return math.Exp(math.Log(dm.min) + (t * (math.Log(dm.max) - math.Log(dm.min))))
+
+	case Float16Magnitude:
+		// Codes Pack never writes, like infinities, NaN and negative
+		// floats, are kept inside the range too
+		m := float16ToFloat64(code)
+		if math.IsNaN(m) {
+			return dm.min
+		}
+		return Clamp(m, dm.min, dm.max)
+	}
+
+	t := float64(code-1) / (maxMagnitudeCode - 1)
+	return dm.min + (t * (dm.max - dm.min))
+}
+
+// Error measures how far the decoded vector strayed from the original in
+// both direction and length.
+func (dm DirectionMagnitude) Error(original, decoded vector.Vector3) VectorError {
+	return MeasureVectorError(original, decoded)
+}
+
+// VectorError describes the error introduced by packing a vector that isn't
+// necessarily unit length.
+type VectorError struct {
+	// Angular is the angle in radians between the original and decoded
+	// vector. Zero whenever either vector has no direction.
+	Angular float64
+
+	// Magnitude is the difference in length relative to the original
+	// length. If the original was zero length, this is the decoded length.
+	Magnitude float64
+}
+
+// Combined folds the angular and magnitude error into a single value, which
+// treats one radian of angular error the same as a 100% length error.
+func (ve VectorError) Combined() float64 {
+	return math.Hypot(ve.Angular, ve.Magnitude)
+}
+
+// MeasureVectorError calculates the angular and relative magnitude error
+// between an original vector and its decoded counterpart.
+func MeasureVectorError(original, decoded vector.Vector3) VectorError {
+	originalLength := original.Length()
+	decodedLength := decoded.Length()
+
+	if originalLength == 0 {
+		return VectorError{Magnitude: decodedLength}
+	}
+
+	magnitudeErr := math.Abs(decodedLength-originalLength) / originalLength
+	if decodedLength == 0 {
+		return VectorError{Magnitude: magnitudeErr}
+	}
+
+	return VectorError{
+		Angular:   AngularError(original, decoded),
+		Magnitude: magnitudeErr,
+	}
+}
+
+// float64ToFloat16 rounds a float to the nearest half precision float,
+// saturating at the largest finite half.
+func float64ToFloat16(f float64) uint16 {
+	sign := uint16(0)
+	if math.Signbit(f) {
+		sign = 0x8000
+		f = -f
+	}
+
+	if math.IsNaN(f) {
+		return 0x7E00
+	}
+
+	if f == 0 {
+		return sign
+	}
+
+	// Largest finite half is 65504
+	if f >= 65504 {
+		return sign | 0x7BFF
+	}
+
+	frac, exp := math.Frexp(f)
+	e := exp - 1
+
+	// Subnormal, counted in steps of 2^-24
+	if e < -14 {
+		return sign | uint16(math.RoundToEven(math.Ldexp(f, 24)))
+	}
+
+	m := uint16(math.RoundToEven(((frac * 2) - 1) * 1024))
+	if m == 1024 {
+		m = 0
+		e++
+	}
+	if e > 15 {
+		return sign | 0x7BFF
+	}
+
+	return sign | (uint16(e+15) << 10) | m
+}
+
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+
+	e := int((h >> 10) & 0x1F)
+	m := float64(h & 0x3FF)
+
+	switch e {
+	case 0:
+		return sign * math.Ldexp(m, -24)
+	case 0x1F:
+		if m != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+
+	return sign * math.Ldexp(1+(m/1024), e-15)
+}
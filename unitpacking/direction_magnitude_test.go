@@ -0,0 +1,154 @@
+package unitpacking_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDirectionMagnitude_InvalidConfig(t *testing.T) {
+	tests := map[string]struct {
+		direction    unitpacking.Codec
+		quantization unitpacking.MagnitudeQuantization
+		min          float64
+		max          float64
+	}{
+		"no direction":      {direction: nil, quantization: unitpacking.LinearMagnitude, min: 0, max: 1},
+		"negative min":      {direction: unitpacking.Oct24, quantization: unitpacking.LinearMagnitude, min: -1, max: 1},
+		"max below min":     {direction: unitpacking.Oct24, quantization: unitpacking.LinearMagnitude, min: 2, max: 1},
+		"infinite max":      {direction: unitpacking.Oct24, quantization: unitpacking.LinearMagnitude, min: 0, max: math.Inf(1)},
+		"log with zero min": {direction: unitpacking.Oct24, quantization: unitpacking.LogarithmicMagnitude, min: 0, max: 1},
+		"unknown method":    {direction: unitpacking.Oct24, quantization: unitpacking.MagnitudeQuantization(12), min: 0, max: 1},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := unitpacking.NewDirectionMagnitude(tc.direction, tc.quantization, tc.min, tc.max)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDirectionMagnitude_RoundTrip(t *testing.T) {
+	quantizations := []unitpacking.MagnitudeQuantization{
+		unitpacking.LinearMagnitude,
+		unitpacking.LogarithmicMagnitude,
+		unitpacking.Float16Magnitude,
+	}
+
+	magnitudes := []float64{0.01, 0.5, 1, 3.3, 47, 99.9}
+
+	for _, quantization := range quantizations {
+		codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, quantization, 0.01, 100)
+		assert.NoError(t, err)
+
+		for _, m := range magnitudes {
+			for _, tc := range testVectors {
+				original := tc.Normalized().MultByConstant(m)
+				name := fmt.Sprintf("%s %.2f,%.2f,%.2f", codec.Name(), original.X(), original.Y(), original.Z())
+
+				t.Run(name, func(t *testing.T) {
+					packed := codec.Pack(original)
+					assert.Len(t, packed, 5)
+
+					errs := codec.Error(original, codec.Unpack(packed))
+					assert.Less(t, errs.Angular, 0.001)
+					assert.Less(t, errs.Magnitude, 0.001)
+				})
+			}
+		}
+	}
+}
+
+func TestDirectionMagnitude_Name(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.OctQuad16, unitpacking.LogarithmicMagnitude, 0.1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "octquad16+log16", codec.Name())
+	assert.Equal(t, 4, codec.Size())
+}
+
+func TestDirectionMagnitude_ZeroLength(t *testing.T) {
+	for _, min := range []float64{0, 0.5} {
+		codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct16, unitpacking.LinearMagnitude, min, 10)
+		assert.NoError(t, err)
+
+		for _, in := range []vector.Vector3{vector.Vector3Zero(), vector.NewVector3(math.NaN(), 0, 1)} {
+			packed := codec.Pack(in)
+			assert.Equal(t, []byte{0, 0, 0, 0}, packed)
+			assert.Equal(t, vector.Vector3Zero(), codec.Unpack(packed))
+		}
+	}
+}
+
+func TestDirectionMagnitude_ClampsToRange(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct32, unitpacking.LinearMagnitude, 1, 2)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 2, codec.Unpack(codec.Pack(vector.NewVector3(0, 0, 5))).Length(), 0.0001)
+	assert.InDelta(t, 1, codec.Unpack(codec.Pack(vector.NewVector3(0, 0.1, 0))).Length(), 0.0001)
+}
+
+func TestDirectionMagnitude_Float16IsExact(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct32, unitpacking.Float16Magnitude, 0, 65504)
+	assert.NoError(t, err)
+
+	// All of these are exactly representable as half precision floats
+	for _, m := range []float64{math.Ldexp(1, -24), math.Ldexp(1, -14), 0.5, 1, 1.5, 2048, 65504} {
+		unpacked := codec.Unpack(codec.Pack(vector.NewVector3(0, 0, m)))
+		assert.InDelta(t, m, unpacked.Length(), m*0.00001)
+	}
+}
+
+func TestDirectionMagnitude_CorruptFloat16(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct16, unitpacking.Float16Magnitude, 0.5, 100)
+	assert.NoError(t, err)
+	direction := unitpacking.PackOct16(vector.NewVector3(0, 0, 1))
+
+	for code, expected := range map[uint16]float64{
+		0x7C00: 100, // +Inf
+		0xFC00: 0.5, // -Inf
+		0x7E00: 0.5, // NaN
+		0xFE00: 0.5, // -NaN
+		0xBC00: 0.5, // -1
+		0x8001: 0.5, // smallest negative subnormal
+		0x7BFF: 100, // 65504
+		0x0001: 0.5, // smallest positive subnormal
+	} {
+		unpacked := codec.Unpack(append(direction, byte(code), byte(code>>8)))
+		assert.Equal(t, vector.NewVector3(0, 0, expected), unpacked, "code %#04x", code)
+	}
+}
+
+func TestDirectionMagnitude_EveryMagnitudeCodeInRange(t *testing.T) {
+	direction := unitpacking.PackOct16(vector.NewVector3(0.6, 0, 0.8))
+	for _, quantization := range []unitpacking.MagnitudeQuantization{unitpacking.LinearMagnitude, unitpacking.LogarithmicMagnitude, unitpacking.Float16Magnitude} {
+		codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct16, quantization, 0.25, 8)
+		assert.NoError(t, err)
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			for code := 1; code <= 0xFFFF; code++ {
+				length := codec.Unpack(append(direction, byte(code), byte(code>>8))).Length()
+				if math.IsNaN(length) || length < 0.25-1e-12 || length > 8+1e-12 {
+					t.Fatalf("magnitude code %#04x unpacked to a length of %g", code, length)
+				}
+			}
+		})
+	}
+}
+
+func TestMeasureVectorError(t *testing.T) {
+	errs := unitpacking.MeasureVectorError(vector.NewVector3(2, 0, 0), vector.NewVector3(0, 3, 0))
+	assert.InDelta(t, math.Pi/2, errs.Angular, 0.0000001)
+	assert.InDelta(t, 0.5, errs.Magnitude, 0.0000001)
+	assert.InDelta(t, math.Hypot(math.Pi/2, 0.5), errs.Combined(), 0.0000001)
+
+	errs = unitpacking.MeasureVectorError(vector.Vector3Zero(), vector.NewVector3(0, 3, 0))
+	assert.Equal(t, unitpacking.VectorError{Magnitude: 3}, errs)
+
+	errs = unitpacking.MeasureVectorError(vector.NewVector3(0, 4, 0), vector.Vector3Zero())
+	assert.Equal(t, unitpacking.VectorError{Magnitude: 1}, errs)
+}
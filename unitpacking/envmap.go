@@ -0,0 +1,225 @@
+package unitpacking
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+// Environment maps are laid out with +Z as up. In an equirectangular image
+// the top row is +Z, the bottom row is -Z, and longitude runs from -pi on the
+// left edge to pi on the right, with the center column facing +X. In an
+// octahedral image the center is +Z and the four corners are -Z, with the
+// top left corner at the octahedral UV (-1, -1) and the bottom right at
+// (1, 1).
+
+// pixel is a color with premultiplied channels between 0 and 0xFFFF.
+type pixel [4]float64
+
+func pixelAt(img image.Image, x, y int) pixel {
+	r, g, b, a := img.At(x, y).RGBA()
+	return pixel{float64(r), float64(g), float64(b), float64(a)}
+}
+
+func (p pixel) rgba64() color.RGBA64 {
+	channel := func(v float64) uint16 {
+		return uint16(math.Round(Clamp(v, 0, 0xFFFF)))
+	}
+	return color.RGBA64{R: channel(p[0]), G: channel(p[1]), B: channel(p[2]), A: channel(p[3])}
+}
+
+// bilinear interpolates between the four texels nearest to the continuous
+// texel coordinate (x, y), where texel centers sit at whole numbers.
+func bilinear(fetch func(x, y int) pixel, x, y float64) pixel {
+	x0, y0 := math.Floor(x), math.Floor(y)
+	fx, fy := x-x0, y-y0
+	ix, iy := int(x0), int(y0)
+
+	a, b := fetch(ix, iy), fetch(ix+1, iy)
+	c, d := fetch(ix, iy+1), fetch(ix+1, iy+1)
+
+	var out pixel
+	for i := range out {
+		top := a[i] + (b[i]-a[i])*fx
+		bottom := c[i] + (d[i]-c[i])*fx
+		out[i] = top + (bottom-top)*fy
+	}
+	return out
+}
+
+// EquirectDirection returns the direction the point (u, v) of an
+// equirectangular image faces, where u and v run from 0 to 1 across the
+// image's width and height.
+func EquirectDirection(u, v float64) vector.Vector3 {
+	lon := (u*2 - 1) * math.Pi
+	lat := (0.5 - v) * math.Pi
+	return vector.NewVector3(
+		math.Cos(lat)*math.Cos(lon),
+		math.Cos(lat)*math.Sin(lon),
+		math.Sin(lat),
+	)
+}
+
+// EquirectUV is the inverse of EquirectDirection. Directions don't need to be
+// unit length.
+func EquirectUV(dir vector.Vector3) (u, v float64) {
+	lon := math.Atan2(dir.Y(), dir.X())
+	lat := math.Atan2(dir.Z(), math.Hypot(dir.X(), dir.Y()))
+	return (lon/math.Pi + 1) / 2, 0.5 - lat/math.Pi
+}
+
+// SampleEquirect bilinearly samples an equirectangular image in the given
+// direction, wrapping around horizontally.
+func SampleEquirect(img image.Image, dir vector.Vector3) color.RGBA64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	fetch := func(x, y int) pixel {
+		x = ((x % width) + width) % width
+		if y < 0 {
+			y = 0
+		} else if y >= height {
+			y = height - 1
+		}
+		return pixelAt(img, bounds.Min.X+x, bounds.Min.Y+y)
+	}
+
+	u, v := EquirectUV(dir)
+	return bilinear(fetch, u*float64(width)-0.5, v*float64(height)-0.5).rgba64()
+}
+
+// foldOctTexel maps a texel outside of a size x size octahedral image to the
+// texel it borders across the nearest edge. The octahedron folds back on
+// itself along every edge of the square, so the neighbors of an edge texel
+// are found by mirroring along the edge and flipping the other axis. Texels
+// must be less than size away from the image.
+func foldOctTexel(x, y, size int) (int, int) {
+	if x < 0 {
+		x, y = -1-x, size-1-y
+	} else if x >= size {
+		x, y = 2*size-1-x, size-1-y
+	}
+
+	if y < 0 {
+		x, y = size-1-x, -1-y
+	} else if y >= size {
+		x, y = size-1-x, 2*size-1-y
+	}
+	return x, y
+}
+
+func octEnvMapSize(bounds image.Rectangle, padding int) (int, error) {
+	size := bounds.Dx() - (2 * padding)
+	if bounds.Dx() != bounds.Dy() {
+		return 0, fmt.Errorf("octahedral images are square, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if padding < 0 || size < 1 || padding > size {
+		return 0, fmt.Errorf("invalid padding of %d texels for a %dx%d octahedral image", padding, bounds.Dx(), bounds.Dy())
+	}
+	return size, nil
+}
+
+// SampleOct bilinearly samples an octahedral image in the given direction.
+// The image has padding texels of border on each side, which are skipped over.
+// Texels are blended across the edges of the octahedron as if it were folded
+// back into a sphere, so the padding doesn't need to be filled in.
+func SampleOct(img image.Image, padding int, dir vector.Vector3) (color.RGBA64, error) {
+	bounds := img.Bounds()
+	size, err := octEnvMapSize(bounds, padding)
+	if err != nil {
+		return color.RGBA64{}, err
+	}
+
+	fetch := func(x, y int) pixel {
+		x, y = foldOctTexel(x, y, size)
+		return pixelAt(img, bounds.Min.X+padding+x, bounds.Min.Y+padding+y)
+	}
+
+	uv := MapToOctUV(dir)
+	x := (uv.X()+1)/2*float64(size) - 0.5
+	y := (uv.Y()+1)/2*float64(size) - 0.5
+	return bilinear(fetch, x, y).rgba64(), nil
+}
+
+// EquirectToOct resamples an equirectangular environment map into a size x
+// size octahedral one, sampling it bilinearly at the direction of each texel's
+// center. Padding adds that many texels of border to each side, filled with
+// the texels across the octahedron's edges so the GPU can filter across them
+// without any special handling.
+func EquirectToOct(src image.Image, size, padding int) (*image.RGBA64, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("invalid octahedral image size %d", size)
+	}
+	if padding < 0 || padding > size {
+		return nil, fmt.Errorf("invalid padding of %d texels for a %dx%d octahedral image", padding, size, size)
+	}
+
+	full := size + (2 * padding)
+	dst := image.NewRGBA64(image.Rect(0, 0, full, full))
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			dir := FromOctUV(vector.NewVector2(
+				((float64(x)+0.5)/float64(size))*2-1,
+				((float64(y)+0.5)/float64(size))*2-1,
+			))
+			dst.SetRGBA64(x+padding, y+padding, SampleEquirect(src, dir))
+		}
+	}
+
+	if err := PadOct(dst, padding); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// PadOct fills the border of an octahedral image, padding texels wide on each
+// side, with the texels across the octahedron's edges.
+func PadOct(img *image.RGBA64, padding int) error {
+	bounds := img.Bounds()
+	size, err := octEnvMapSize(bounds, padding)
+	if err != nil {
+		return err
+	}
+
+	for y := -padding; y < size+padding; y++ {
+		for x := -padding; x < size+padding; x++ {
+			if x >= 0 && x < size && y >= 0 && y < size {
+				continue
+			}
+			fx, fy := foldOctTexel(x, y, size)
+			img.SetRGBA64(
+				bounds.Min.X+padding+x,
+				bounds.Min.Y+padding+y,
+				img.RGBA64At(bounds.Min.X+padding+fx, bounds.Min.Y+padding+fy),
+			)
+		}
+	}
+	return nil
+}
+
+// OctToEquirect resamples an octahedral environment map, with padding texels
+// of border on each side, into a width x height equirectangular one.
+func OctToEquirect(src image.Image, padding, width, height int) (*image.RGBA64, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid equirectangular image size %dx%d", width, height)
+	}
+	if _, err := octEnvMapSize(src.Bounds(), padding); err != nil {
+		return nil, err
+	}
+
+	dst := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dir := EquirectDirection(
+				(float64(x)+0.5)/float64(width),
+				(float64(y)+0.5)/float64(height),
+			)
+			c, _ := SampleOct(src, padding, dir)
+			dst.SetRGBA64(x, y, c)
+		}
+	}
+	return dst, nil
+}
@@ -0,0 +1,196 @@
+package unitpacking_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+// directionColor is a smooth function of direction, so resampling it should
+// only introduce small errors anywhere on the sphere.
+func directionColor(dir vector.Vector3) color.RGBA64 {
+	dir = dir.Normalized()
+	channel := func(v float64) uint16 {
+		return uint16(math.Round((v + 1) / 2 * 0xFFFF))
+	}
+	return color.RGBA64{R: channel(dir.X()), G: channel(dir.Y()), B: channel(dir.Z()), A: 0xFFFF}
+}
+
+func directionEquirect(width, height int) *image.RGBA64 {
+	img := image.NewRGBA64(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dir := unitpacking.EquirectDirection((float64(x)+0.5)/float64(width), (float64(y)+0.5)/float64(height))
+			img.SetRGBA64(x, y, directionColor(dir))
+		}
+	}
+	return img
+}
+
+func assertColorNear(t *testing.T, expected, actual color.RGBA64, delta float64) bool {
+	t.Helper()
+	return assert.InDelta(t, float64(expected.R), float64(actual.R), delta) &&
+		assert.InDelta(t, float64(expected.G), float64(actual.G), delta) &&
+		assert.InDelta(t, float64(expected.B), float64(actual.B), delta) &&
+		assert.InDelta(t, float64(expected.A), float64(actual.A), delta)
+}
+
+func TestEquirectDirection(t *testing.T) {
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(1, 0, 0), unitpacking.EquirectDirection(0.5, 0.5)), 1e-12)
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(0, 1, 0), unitpacking.EquirectDirection(0.75, 0.5)), 1e-12)
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(0, 0, 1), unitpacking.EquirectDirection(0.3, 0)), 1e-12)
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(0, 0, -1), unitpacking.EquirectDirection(0.3, 1)), 1e-12)
+
+	for _, tc := range testVectors {
+		u, v := unitpacking.EquirectUV(tc)
+		assert.InDelta(t, 0, unitpacking.AngularError(tc, unitpacking.EquirectDirection(u, v)), 1e-12)
+	}
+}
+
+func TestSampleEquirect(t *testing.T) {
+	img := image.NewRGBA64(image.Rect(0, 0, 4, 2))
+	for x := 0; x < 4; x++ {
+		img.SetRGBA64(x, 0, color.RGBA64{R: uint16(x * 1000), A: 0xFFFF})
+		img.SetRGBA64(x, 1, color.RGBA64{R: uint16(x * 1000), G: 0xFFFF, A: 0xFFFF})
+	}
+
+	// Texel centers come back exactly
+	assert.Equal(t, img.RGBA64At(2, 1), unitpacking.SampleEquirect(img, unitpacking.EquirectDirection(2.5/4, 1.5/2)))
+
+	// Halfway between the last column and the first one, across the seam
+	assert.Equal(
+		t,
+		color.RGBA64{R: 1500, G: 0xFFFF, A: 0xFFFF},
+		unitpacking.SampleEquirect(img, unitpacking.EquirectDirection(0.9999999999, 1.5/2)),
+	)
+}
+
+func TestEquirectToOct_Constant(t *testing.T) {
+	src := image.NewUniform(color.RGBA64{R: 0x1234, G: 0x5678, B: 0x9ABC, A: 0xFFFF})
+	equirect := image.NewRGBA64(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			equirect.Set(x, y, src.C)
+		}
+	}
+
+	oct, err := unitpacking.EquirectToOct(equirect, 6, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, image.Rect(0, 0, 10, 10), oct.Bounds())
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			assert.Equal(t, src.C, oct.RGBA64At(x, y))
+		}
+	}
+}
+
+func TestEquirectToOct_Padding(t *testing.T) {
+	oct, err := unitpacking.EquirectToOct(directionEquirect(64, 32), 8, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Inner texels sit at an offset of 2. Across the right edge, rows flip
+	assert.Equal(t, oct.RGBA64At(2+7, 2+0), oct.RGBA64At(2+8, 2+7))
+	assert.Equal(t, oct.RGBA64At(2+6, 2+2), oct.RGBA64At(2+9, 2+5))
+
+	// Across the top edge, columns flip
+	assert.Equal(t, oct.RGBA64At(2+1, 2+0), oct.RGBA64At(2+6, 2-1))
+
+	// Every corner of the square is straight down, so the texel diagonally
+	// past one corner is the one in the opposite corner
+	assert.Equal(t, oct.RGBA64At(2+7, 2+7), oct.RGBA64At(2-1, 2-1))
+	assert.Equal(t, oct.RGBA64At(2+0, 2+0), oct.RGBA64At(2+8, 2+8))
+}
+
+func TestSampleOct_AcrossEdges(t *testing.T) {
+	oct, err := unitpacking.EquirectToOct(directionEquirect(512, 256), 64, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// Points right on the edges blend the texels on either side of the fold
+	for v := -0.95; v < 1; v += 0.05 {
+		for _, uv := range []vector.Vector2{
+			vector.NewVector2(1, v),
+			vector.NewVector2(-1, v),
+			vector.NewVector2(v, 1),
+			vector.NewVector2(v, -1),
+		} {
+			dir := unitpacking.FromOctUV(uv)
+			sampled, err := unitpacking.SampleOct(oct, 1, dir)
+			if !assert.NoError(t, err) || !assertColorNear(t, directionColor(dir), sampled, 0.01*0xFFFF) {
+				return
+			}
+		}
+	}
+
+	r := rand.New(rand.NewSource(40))
+	for i := 0; i < 2000; i++ {
+		dir := vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64())
+		if i%2 == 0 {
+			// Half the samples hug the equator and the lower hemisphere,
+			// where texels blend across the octahedron's edges
+			dir = vector.NewVector3(dir.X(), dir.Y(), -math.Abs(dir.Z())*0.2)
+		}
+
+		sampled, err := unitpacking.SampleOct(oct, 1, dir)
+		if !assert.NoError(t, err) || !assertColorNear(t, directionColor(dir), sampled, 0.01*0xFFFF) {
+			return
+		}
+	}
+}
+
+func TestOctToEquirect_RoundTrip(t *testing.T) {
+	original := directionEquirect(256, 128)
+	oct, err := unitpacking.EquirectToOct(original, 128, 1)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	back, err := unitpacking.OctToEquirect(oct, 1, 256, 128)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, original.Bounds(), back.Bounds())
+
+	for y := 0; y < 128; y++ {
+		for x := 0; x < 256; x++ {
+			if !assertColorNear(t, original.RGBA64At(x, y), back.RGBA64At(x, y), 0.01*0xFFFF) {
+				return
+			}
+		}
+	}
+}
+
+func TestEnvMap_InvalidSizes(t *testing.T) {
+	equirect := directionEquirect(8, 4)
+
+	_, err := unitpacking.EquirectToOct(equirect, 0, 0)
+	assert.Error(t, err)
+
+	_, err = unitpacking.EquirectToOct(equirect, 4, 5)
+	assert.Error(t, err)
+
+	_, err = unitpacking.OctToEquirect(image.NewRGBA64(image.Rect(0, 0, 4, 5)), 0, 8, 4)
+	assert.Error(t, err)
+
+	_, err = unitpacking.OctToEquirect(image.NewRGBA64(image.Rect(0, 0, 4, 4)), 2, 8, 4)
+	assert.Error(t, err)
+
+	_, err = unitpacking.OctToEquirect(image.NewRGBA64(image.Rect(0, 0, 4, 4)), 0, 0, 4)
+	assert.Error(t, err)
+
+	_, err = unitpacking.SampleOct(image.NewRGBA64(image.Rect(0, 0, 4, 4)), -1, vector.NewVector3(0, 0, 1))
+	assert.Error(t, err)
+
+	assert.Error(t, unitpacking.PadOct(image.NewRGBA64(image.Rect(0, 0, 4, 3)), 1))
+}
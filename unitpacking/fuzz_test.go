@@ -0,0 +1,88 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+)
+
+func isNonFinite(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+func FuzzPack(f *testing.F) {
+	for _, v := range append(append([]vector.Vector3{}, testVectors...), edgeCaseVectors...) {
+		f.Add(v.X(), v.Y(), v.Z())
+	}
+	f.Add(math.NaN(), 0.0, 1.0)
+	f.Add(math.Inf(-1), 0.0, 0.0)
+	f.Add(0.0, 0.0, 0.0)
+
+	dirMag, err := unitpacking.NewDirectionMagnitude(unitpacking.OctQuad24, unitpacking.LogarithmicMagnitude, 0.001, 1000)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, x, y, z float64) {
+		v := vector.NewVector3(x, y, z)
+
+		// Anything goes in, as long as nothing panics
+		for _, codec := range append(unitpacking.AllCodecs(), dirMag) {
+			if packed := codec.Pack(v); len(packed) != codec.Size() {
+				t.Fatalf("%s packed %v into %d bytes", codec.Name(), v, len(packed))
+			}
+		}
+
+		for _, codec := range unitpacking.AllCodecs() {
+			unit, err := normalizer.Validate(coveredBy(codec, v))
+			if err != nil {
+				return
+			}
+
+			if math.Abs(unit.Length()-1) > 1e-12 {
+				t.Fatalf("%v normalized to %v", v, unit)
+			}
+
+			if problem, ok := checkPacking(codec, unit); !ok {
+				t.Fatalf("%s %v: %s", codec.Name(), v, problem)
+			}
+		}
+	})
+}
+
+func FuzzUnpack(f *testing.F) {
+	for _, codec := range unitpacking.AllCodecs() {
+		f.Add(codec.(unitpacking.NullCodec).Null())
+		for _, v := range testVectors {
+			f.Add(codec.Pack(coveredBy(codec, v.Normalized())))
+		}
+	}
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF})
+	f.Add([]byte{0x00, 0x80, 0x00, 0x80})
+	f.Add([]byte{0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, codec := range unitpacking.AllCodecs() {
+			for start := 0; start+codec.Size() <= len(data); start += codec.Size() {
+				code := data[start : start+codec.Size()]
+
+				unpacked := codec.Unpack(code)
+				if isNonFinite(unpacked.X()) || isNonFinite(unpacked.Y()) || isNonFinite(unpacked.Z()) {
+					t.Fatalf("%s unpacked %x to %v", codec.Name(), code, unpacked)
+				}
+
+				if bounds[codec.Name()].alwaysUnit && math.Abs(unpacked.Length()-1) > 1e-12 {
+					t.Fatalf("%s unpacked %x to %v", codec.Name(), code, unpacked)
+				}
+
+				canonical := unitpacking.Canonicalize(codec, code)
+				if !bytes.Equal(canonical, unitpacking.Canonicalize(codec, canonical)) {
+					t.Fatalf("%s canonical form of %x isn't stable", codec.Name(), code)
+				}
+			}
+		}
+	})
+}
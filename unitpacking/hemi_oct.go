@@ -0,0 +1,149 @@
+package unitpacking
+
+import (
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+// MapToHemiOctUV maps a vector on the upper hemisphere (Z >= 0) to a 2D UV of
+// a hemi-octahedron. The pyramid is rotated 45 degrees so the upper
+// hemisphere fills the entire square, giving twice the precision of MapToOctUV
+// for vectors that never point down, like tangent space normals. The sign of Z
+// is ignored, so vectors on the lower hemisphere come back mirrored onto the
+// upper one.
+func MapToHemiOctUV(v vector.Vector3) vector.Vector2 {
+	// Project the hemisphere onto the pyramid, and then onto the xy plane
+	p := vector.
+		NewVector2(v.X(), v.Y()).
+		MultByConstant(1.0 / (math.Abs(v.X()) + math.Abs(v.Y()) + math.Abs(v.Z())))
+
+	// Rotate and scale the diamond into the unit square
+	return vector.NewVector2(p.X()+p.Y(), p.X()-p.Y())
+}
+
+// FromHemiOctUV converts a 2D hemi-octahedron UV coordinate to a point on the
+// upper hemisphere of a 3D sphere.
+func FromHemiOctUV(e vector.Vector2) vector.Vector3 {
+	x := (e.X() + e.Y()) / 2
+	y := (e.X() - e.Y()) / 2
+	return vector.NewVector3(x, y, 1.0-math.Abs(x)-math.Abs(y)).Normalized()
+}
+
+// packUV rounds each coordinate of the UV to the nearest of 2^bits evenly
+// spaced values, and writes X in the upper bits and Y in the lower bits like
+// the Oct methods do. 0 is never written, leaving it free as a null code. A UV
+// with a NaN coordinate, from a vector with no direction, is written as the
+// center.
+func packUV(uv vector.Vector2, bits int) uint32 {
+	if math.IsNaN(uv.X()) || math.IsNaN(uv.Y()) {
+		uv = vector.NewVector2(0, 0)
+	}
+	half := float64(int(1) << (bits - 1))
+	x := uint32(math.Round(Clamp(uv.X(), -1, 1)*(half-1)) + half)
+	y := uint32(math.Round(Clamp(uv.Y(), -1, 1)*(half-1)) + half)
+	return (x << bits) | y
+}
+
+// unpackUV reverses packUV.
+func unpackUV(everything uint32, bits int) vector.Vector2 {
+	mask := uint32(1)<<bits - 1
+	half := float64(int(1) << (bits - 1))
+	rawX := (everything >> bits) & mask
+	rawY := everything & mask
+	return vector.NewVector2(
+		Clamp((float64(rawX)-half)/(half-1), -1.0, 1.0),
+		Clamp((float64(rawY)-half)/(half-1), -1.0, 1.0),
+	)
+}
+
+// PackHemiOct16 maps a vector on the upper hemisphere to a 2D UV of a
+// hemi-octahedron, and then writes the 2D coordinates to 2 bytes, 8bits per
+// coordinate.
+func PackHemiOct16(v vector.Vector3) []byte {
+	everything := PackHemiOct16Uint16(v)
+	return []byte{
+		(byte)(everything & 0xFF),
+		(byte)((everything >> 8) & 0xFF),
+	}
+}
+
+// PackHemiOct16Uint16 is PackHemiOct16 without splitting the result into
+// bytes. X occupies the upper 8 bits and Y the lower 8.
+func PackHemiOct16Uint16(v vector.Vector3) uint16 {
+	return uint16(packUV(MapToHemiOctUV(v), 8))
+}
+
+// UnpackHemiOct16 reads in two 8bit numbers and converts from 2D
+// hemi-octahedron UV to 3D unit sphere coordinates.
+func UnpackHemiOct16(b []byte) vector.Vector3 {
+	return UnpackHemiOct16Uint16(uint16(b[0]) | (uint16(b[1]) << 8))
+}
+
+// UnpackHemiOct16Uint16 is UnpackHemiOct16 for a code previously packed with
+// PackHemiOct16Uint16.
+func UnpackHemiOct16Uint16(everything uint16) vector.Vector3 {
+	return FromHemiOctUV(unpackUV(uint32(everything), 8))
+}
+
+// PackHemiOct24 maps a vector on the upper hemisphere to a 2D UV of a
+// hemi-octahedron, and then writes the 2D coordinates to 3 bytes, 12bits per
+// coordinate.
+func PackHemiOct24(v vector.Vector3) []byte {
+	everything := PackHemiOct24Uint32(v)
+	return []byte{
+		(byte)(everything & 0xFF),
+		(byte)((everything >> 8) & 0xFF),
+		(byte)((everything >> 16) & 0xFF),
+	}
+}
+
+// PackHemiOct24Uint32 is PackHemiOct24 without splitting the result into
+// bytes. X occupies bits 12 through 23 and Y the lowest 12 bits, leaving the
+// upper 8 bits unused.
+func PackHemiOct24Uint32(v vector.Vector3) uint32 {
+	return packUV(MapToHemiOctUV(v), 12)
+}
+
+// UnpackHemiOct24 reads in two 12bit numbers and converts from 2D
+// hemi-octahedron UV to 3D unit sphere coordinates.
+func UnpackHemiOct24(b []byte) vector.Vector3 {
+	return UnpackHemiOct24Uint32(uint32(b[0]) | (uint32(b[1]) << 8) | (uint32(b[2]) << 16))
+}
+
+// UnpackHemiOct24Uint32 is UnpackHemiOct24 for a code previously packed with
+// PackHemiOct24Uint32. The upper 8 bits are ignored.
+func UnpackHemiOct24Uint32(everything uint32) vector.Vector3 {
+	return FromHemiOctUV(unpackUV(everything, 12))
+}
+
+// PackHemiOct32 maps a vector on the upper hemisphere to a 2D UV of a
+// hemi-octahedron, and then writes the 2D coordinates to 4 bytes, 2 bytes per
+// coordinate.
+func PackHemiOct32(v vector.Vector3) []byte {
+	everything := PackHemiOct32Uint32(v)
+	return []byte{
+		(byte)(everything & 0xFF),
+		(byte)((everything >> 8) & 0xFF),
+		(byte)((everything >> 16) & 0xFF),
+		(byte)((everything >> 24) & 0xFF),
+	}
+}
+
+// PackHemiOct32Uint32 is PackHemiOct32 without splitting the result into
+// bytes. X occupies the upper 16 bits and Y the lower 16.
+func PackHemiOct32Uint32(v vector.Vector3) uint32 {
+	return packUV(MapToHemiOctUV(v), 16)
+}
+
+// UnpackHemiOct32 reads in two 16bit numbers and converts from 2D
+// hemi-octahedron UV to 3D unit sphere coordinates.
+func UnpackHemiOct32(b []byte) vector.Vector3 {
+	return UnpackHemiOct32Uint32(uint32(b[0]) | (uint32(b[1]) << 8) | (uint32(b[2]) << 16) | (uint32(b[3]) << 24))
+}
+
+// UnpackHemiOct32Uint32 is UnpackHemiOct32 for a code previously packed with
+// PackHemiOct32Uint32.
+func UnpackHemiOct32Uint32(everything uint32) vector.Vector3 {
+	return FromHemiOctUV(unpackUV(everything, 16))
+}
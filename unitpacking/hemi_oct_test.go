@@ -0,0 +1,105 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func upperHemisphere(r *rand.Rand) vector.Vector3 {
+	v := vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64())
+	return vector.NewVector3(v.X(), v.Y(), math.Abs(v.Z())).Normalized()
+}
+
+func TestHemiOctUV_Corners(t *testing.T) {
+	tests := map[string]struct {
+		uv   vector.Vector2
+		want vector.Vector3
+	}{
+		"center": {uv: vector.NewVector2(0, 0), want: vector.NewVector3(0, 0, 1)},
+		"+x":     {uv: vector.NewVector2(1, 1), want: vector.NewVector3(1, 0, 0)},
+		"-x":     {uv: vector.NewVector2(-1, -1), want: vector.NewVector3(-1, 0, 0)},
+		"+y":     {uv: vector.NewVector2(1, -1), want: vector.NewVector3(0, 1, 0)},
+		"-y":     {uv: vector.NewVector2(-1, 1), want: vector.NewVector3(0, -1, 0)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.want, unitpacking.FromHemiOctUV(tc.uv))
+			assert.Equal(t, tc.uv, unitpacking.MapToHemiOctUV(tc.want))
+		})
+	}
+}
+
+func TestHemiOct_MirrorsLowerHemisphere(t *testing.T) {
+	up := vector.NewVector3(0.3, -0.2, 0.6).Normalized()
+	down := vector.NewVector3(0.3, -0.2, -0.6).Normalized()
+	assert.Equal(t, unitpacking.HemiOct24.Pack(up), unitpacking.HemiOct24.Pack(down))
+}
+
+func TestHemiOct_Precision(t *testing.T) {
+	// Max angular error in degrees over the upper hemisphere
+	tests := []struct {
+		codec    unitpacking.NullCodec
+		maxAngle float64
+	}{
+		{unitpacking.HemiOct16, 0.6},
+		{unitpacking.HemiOct24, 0.036},
+		{unitpacking.HemiOct32, 0.0023},
+	}
+
+	r := rand.New(rand.NewSource(37))
+	for _, tc := range tests {
+		t.Run(tc.codec.Name(), func(t *testing.T) {
+			null := tc.codec.Null()
+			for i := 0; i < 50000; i++ {
+				in := upperHemisphere(r)
+				packed := tc.codec.Pack(in)
+				out := tc.codec.Unpack(packed)
+
+				assert.Len(t, packed, tc.codec.Size())
+				assert.NotEqual(t, null, packed)
+				assert.InDelta(t, 1, out.Length(), 1e-12)
+				if angle := unitpacking.AngularError(in, out) * 180 / math.Pi; angle > tc.maxAngle {
+					t.Fatalf("%v unpacked to %v, %f degrees off", in, out, angle)
+				}
+
+				// Repacking never drifts
+				if repacked := tc.codec.Pack(out); !bytes.Equal(packed, repacked) {
+					t.Fatalf("%v packed to %x but repacked to %x", in, packed, repacked)
+				}
+			}
+		})
+	}
+}
+
+func TestHemiOct_WordForms(t *testing.T) {
+	r := rand.New(rand.NewSource(37))
+	for i := 0; i < 1000; i++ {
+		in := upperHemisphere(r)
+
+		assert.Equal(t, littleEndianWord(unitpacking.PackHemiOct16(in)), uint32(unitpacking.PackHemiOct16Uint16(in)))
+		assert.Equal(t, littleEndianWord(unitpacking.PackHemiOct24(in)), unitpacking.PackHemiOct24Uint32(in))
+		assert.Equal(t, littleEndianWord(unitpacking.PackHemiOct32(in)), unitpacking.PackHemiOct32Uint32(in))
+
+		assert.Equal(t, unitpacking.UnpackHemiOct16(unitpacking.PackHemiOct16(in)), unitpacking.UnpackHemiOct16Uint16(unitpacking.PackHemiOct16Uint16(in)))
+		assert.Equal(t, unitpacking.UnpackHemiOct24(unitpacking.PackHemiOct24(in)), unitpacking.UnpackHemiOct24Uint32(unitpacking.PackHemiOct24Uint32(in)))
+		assert.Equal(t, unitpacking.UnpackHemiOct32(unitpacking.PackHemiOct32(in)), unitpacking.UnpackHemiOct32Uint32(unitpacking.PackHemiOct32Uint32(in)))
+	}
+}
+
+func TestHemiOct_ByName(t *testing.T) {
+	for _, codec := range unitpacking.HemiCodecs() {
+		found, ok := unitpacking.CodecByName(codec.Name())
+		assert.True(t, ok)
+		assert.Equal(t, codec.Name(), found.Name())
+		for _, c := range unitpacking.Codecs() {
+			assert.NotEqual(t, codec.Name(), c.Name())
+		}
+	}
+}
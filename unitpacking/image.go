@@ -0,0 +1,242 @@
+package unitpacking
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+// PackedNormal is a color holding a normal packed with a codec. Code is the
+// packed bytes read as a little endian word. Its RGBA value is the normal in
+// the conventional normal map encoding, where each component is remapped
+// from [-1, 1] to [0, 0xFFFF].
+type PackedNormal struct {
+	Codec Codec
+	Code  uint32
+}
+
+// Normal unpacks the color's normal.
+func (c PackedNormal) Normal() vector.Vector3 {
+	return c.Codec.Unpack(codeBytes(c.Code, c.Codec.Size()))
+}
+
+// RGBA returns the conventional normal map encoding of the color's normal,
+// always fully opaque. A PackedNormal without a codec is transparent black.
+func (c PackedNormal) RGBA() (r, g, b, a uint32) {
+	if c.Codec == nil {
+		return 0, 0, 0, 0
+	}
+	n := c.Normal()
+	return normalChannel(n.X()), normalChannel(n.Y()), normalChannel(n.Z()), 0xFFFF
+}
+
+func normalChannel(v float64) uint32 {
+	return uint32(math.Round((Clamp(v, -1, 1) + 1) / 2 * 0xFFFF))
+}
+
+func codeBytes(code uint32, size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(code >> (i * 8))
+	}
+	return b
+}
+
+func codeWord(b []byte) uint32 {
+	code := uint32(0)
+	for i, v := range b {
+		code |= uint32(v) << (i * 8)
+	}
+	return code
+}
+
+// NormalFromColor reads a color in the conventional normal map encoding,
+// where each channel is remapped from [0, 0xFFFF] to [-1, 1]. Alpha is
+// ignored. PackedNormals are unpacked directly.
+func NormalFromColor(c color.Color) vector.Vector3 {
+	if packed, ok := c.(PackedNormal); ok && packed.Codec != nil {
+		return packed.Normal()
+	}
+
+	// 0xFFFF is odd, so no channel maps to exactly 0 and the vector always
+	// has a length to normalize
+	nc := color.NRGBA64Model.Convert(c).(color.NRGBA64)
+	return vector.NewVector3(
+		(float64(nc.R)/0xFFFF)*2-1,
+		(float64(nc.G)/0xFFFF)*2-1,
+		(float64(nc.B)/0xFFFF)*2-1,
+	).Normalized()
+}
+
+// NormalModel returns a color model that packs colors with the given codec.
+// Colors are read with NormalFromColor, and PackedNormals from the same codec
+// are kept as is.
+func NormalModel(codec Codec) color.Model {
+	return color.ModelFunc(func(c color.Color) color.Color {
+		if packed, ok := c.(PackedNormal); ok && packed.Codec != nil && packed.Codec.Name() == codec.Name() {
+			return packed
+		}
+		return PackedNormal{Codec: codec, Code: codeWord(codec.Pack(NormalFromColor(c)))}
+	})
+}
+
+// OctNormalImage is an in-memory image of normals stored as packed codes,
+// taking Codec.Size() bytes per pixel. It reads and writes colors in the
+// conventional normal map encoding, so it works with image/draw and the
+// standard image encoders, and can be built from any standard normal map with
+// draw.Draw.
+type OctNormalImage struct {
+	// Pix holds the image's codes, Codec.Size() bytes per pixel. The code at
+	// (x, y) starts at Pix[(y-Rect.Min.Y)*Stride + (x-Rect.Min.X)*Codec.Size()].
+	Pix []uint8
+
+	// Stride is the Pix stride (in bytes) between vertically adjacent pixels.
+	Stride int
+
+	// Rect is the image's bounds.
+	Rect image.Rectangle
+
+	// Codec packs the image's normals.
+	Codec Codec
+}
+
+// NewOctNormalImage returns a new OctNormalImage with the given bounds, with
+// every pixel packed as the flat normal (0, 0, 1). Codes must fit into a
+// 32 bit word, so the codec can be at most 4 bytes.
+func NewOctNormalImage(r image.Rectangle, codec Codec) (*OctNormalImage, error) {
+	if codec == nil {
+		return nil, fmt.Errorf("codec required")
+	}
+
+	size := codec.Size()
+	if size < 1 || size > 4 {
+		return nil, fmt.Errorf("%s codes are %d bytes, images only support codecs of 1 to 4 bytes", codec.Name(), size)
+	}
+
+	r = r.Canon()
+	img := &OctNormalImage{
+		Pix:    make([]uint8, r.Dx()*r.Dy()*size),
+		Stride: r.Dx() * size,
+		Rect:   r,
+		Codec:  codec,
+	}
+
+	flat := codec.Pack(vector.NewVector3(0, 0, 1))
+	for i := 0; i < len(img.Pix); i += size {
+		copy(img.Pix[i:], flat)
+	}
+	return img, nil
+}
+
+// NewOctNormalImageFromCodes reads an image whose channels hold packed codes,
+// like those written by CodeImage, back into an OctNormalImage.
+func NewOctNormalImageFromCodes(codes image.Image, codec Codec) (*OctNormalImage, error) {
+	img, err := NewOctNormalImage(codes.Bounds(), codec)
+	if err != nil {
+		return nil, err
+	}
+
+	size := codec.Size()
+	for y := img.Rect.Min.Y; y < img.Rect.Max.Y; y++ {
+		for x := img.Rect.Min.X; x < img.Rect.Max.X; x++ {
+			var channels []byte
+			if size == 4 {
+				c := color.NRGBA64Model.Convert(codes.At(x, y)).(color.NRGBA64)
+				channels = []byte{byte(c.R), byte(c.R >> 8), byte(c.G), byte(c.G >> 8)}
+			} else {
+				c := color.NRGBAModel.Convert(codes.At(x, y)).(color.NRGBA)
+				channels = []byte{c.R, c.G, c.B}
+			}
+			copy(img.Pix[img.PixOffset(x, y):], channels[:size])
+		}
+	}
+	return img, nil
+}
+
+// ColorModel returns NormalModel for the image's codec.
+func (p *OctNormalImage) ColorModel() color.Model {
+	return NormalModel(p.Codec)
+}
+
+// Bounds returns the image's bounds.
+func (p *OctNormalImage) Bounds() image.Rectangle {
+	return p.Rect
+}
+
+// At returns the pixel at (x, y) as a PackedNormal.
+func (p *OctNormalImage) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return PackedNormal{}
+	}
+	return PackedNormal{Codec: p.Codec, Code: codeWord(p.code(x, y))}
+}
+
+// Set packs the color into the pixel at (x, y), reading it with
+// NormalFromColor.
+func (p *OctNormalImage) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	packed := p.ColorModel().Convert(c).(PackedNormal)
+	copy(p.code(x, y), codeBytes(packed.Code, p.Codec.Size()))
+}
+
+// NormalAt unpacks the normal at (x, y). Pixels outside of the image are the
+// flat normal (0, 0, 1).
+func (p *OctNormalImage) NormalAt(x, y int) vector.Vector3 {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return vector.NewVector3(0, 0, 1)
+	}
+	return p.Codec.Unpack(p.code(x, y))
+}
+
+// SetNormal packs the normal into the pixel at (x, y).
+func (p *OctNormalImage) SetNormal(x, y int, v vector.Vector3) {
+	if !(image.Point{x, y}.In(p.Rect)) {
+		return
+	}
+	copy(p.code(x, y), p.Codec.Pack(v))
+}
+
+// PixOffset returns the index of the first element of Pix that corresponds to
+// the pixel at (x, y).
+func (p *OctNormalImage) PixOffset(x, y int) int {
+	return (y-p.Rect.Min.Y)*p.Stride + (x-p.Rect.Min.X)*p.Codec.Size()
+}
+
+func (p *OctNormalImage) code(x, y int) []byte {
+	i := p.PixOffset(x, y)
+	return p.Pix[i : i+p.Codec.Size()]
+}
+
+// CodeImage copies the image's codes into the color channels of an opaque
+// image. Codes of up to 3 bytes go into the R, G and B channels of an NRGBA
+// image, one byte each, and 4 byte codes go into the R and G channels of an
+// NRGBA64 image, as the code's low and high 16 bits. Channels past the end of
+// the code are 0. The result can be saved losslessly as a PNG and read back
+// with NewOctNormalImageFromCodes.
+func (p *OctNormalImage) CodeImage() image.Image {
+	if p.Codec.Size() == 4 {
+		codes := image.NewNRGBA64(p.Rect)
+		for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+			for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+				code := p.code(x, y)
+				copy(codes.Pix[codes.PixOffset(x, y):], []byte{code[1], code[0], code[3], code[2], 0, 0, 0xFF, 0xFF})
+			}
+		}
+		return codes
+	}
+
+	codes := image.NewNRGBA(p.Rect)
+	for y := p.Rect.Min.Y; y < p.Rect.Max.Y; y++ {
+		for x := p.Rect.Min.X; x < p.Rect.Max.X; x++ {
+			channels := []byte{0, 0, 0, 0xFF}
+			copy(channels, p.code(x, y))
+			copy(codes.Pix[codes.PixOffset(x, y):], channels)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,249 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+var imageCodecs = []unitpacking.Codec{
+	unitpacking.Oct16,
+	unitpacking.Oct24,
+	unitpacking.OctQuad24,
+	unitpacking.Oct32,
+}
+
+func fillTestNormals(img *unitpacking.OctNormalImage) {
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			i := (x - bounds.Min.X) + (y-bounds.Min.Y)*bounds.Dx()
+			img.SetNormal(x, y, testVectors[i%len(testVectors)].Normalized())
+		}
+	}
+}
+
+func TestNewOctNormalImage_StartsFlat(t *testing.T) {
+	for _, codec := range imageCodecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(image.Rect(-2, 3, 5, 7), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, image.Rect(-2, 3, 5, 7), img.Bounds())
+			assert.Len(t, img.Pix, 7*4*codec.Size())
+
+			flat := codec.Unpack(codec.Pack(vector.NewVector3(0, 0, 1)))
+			assert.Equal(t, flat, img.NormalAt(-2, 3))
+			assert.Equal(t, flat, img.NormalAt(4, 6))
+		})
+	}
+}
+
+func TestNewOctNormalImage_RejectsCodecs(t *testing.T) {
+	_, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 1, 1), nil)
+	assert.Error(t, err)
+
+	tooLarge, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct32, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+	_, err = unitpacking.NewOctNormalImage(image.Rect(0, 0, 1, 1), tooLarge)
+	assert.Error(t, err)
+}
+
+func TestOctNormalImage_SetNormal(t *testing.T) {
+	for _, codec := range imageCodecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(image.Rect(1, 1, 6, 5), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+			fillTestNormals(img)
+
+			for y := 1; y < 5; y++ {
+				for x := 1; x < 6; x++ {
+					v := testVectors[((x-1)+(y-1)*5)%len(testVectors)].Normalized()
+					packed := codec.Pack(v)
+					assert.Equal(t, codec.Unpack(packed), img.NormalAt(x, y))
+
+					offset := img.PixOffset(x, y)
+					assert.Equal(t, packed, img.Pix[offset:offset+codec.Size()])
+				}
+			}
+		})
+	}
+}
+
+func TestOctNormalImage_OutOfBounds(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 2, 2), unitpacking.Oct24)
+	if !assert.NoError(t, err) {
+		return
+	}
+	before := append([]byte(nil), img.Pix...)
+
+	img.SetNormal(2, 0, vector.NewVector3(1, 0, 0))
+	img.Set(0, -1, color.White)
+	assert.Equal(t, before, img.Pix)
+
+	assert.Equal(t, vector.NewVector3(0, 0, 1), img.NormalAt(-1, 0))
+	_, _, _, a := img.At(5, 5).RGBA()
+	assert.Equal(t, uint32(0), a)
+}
+
+func TestOctNormalImage_ColorsUseNormalMapEncoding(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 3, 1), unitpacking.Oct32)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	img.SetNormal(0, 0, vector.NewVector3(1, 0, 0))
+	img.SetNormal(1, 0, vector.NewVector3(0, -1, 0))
+	img.Set(2, 0, color.NRGBA{R: 128, G: 128, B: 255, A: 255})
+
+	r, g, b, a := img.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{0xFFFF, 0x8000, 0x8000, 0xFFFF}, []uint32{r, g, b, a})
+
+	r, g, b, a = img.At(1, 0).RGBA()
+	assert.Equal(t, []uint32{0x8000, 0, 0x8000, 0xFFFF}, []uint32{r, g, b, a})
+
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(0, 0, 1), img.NormalAt(2, 0)), 0.01)
+}
+
+func TestNormalFromColor(t *testing.T) {
+	flat := unitpacking.NormalFromColor(color.NRGBA64{R: 0x8000, G: 0x8000, B: 0xFFFF, A: 0xFFFF})
+	assert.InDelta(t, 0, unitpacking.AngularError(vector.NewVector3(0, 0, 1), flat), 1e-4)
+
+	// Alpha is ignored
+	n := unitpacking.NormalFromColor(color.NRGBA{R: 255, G: 128, B: 128, A: 10})
+	assert.InDelta(t, 1, n.X(), 1e-4)
+	assert.InDelta(t, 1, n.Length(), 1e-9)
+
+	// Even the center of the cube has a direction
+	assert.InDelta(t, 1, unitpacking.NormalFromColor(color.Gray16{Y: 0x7FFF}).Length(), 1e-9)
+
+	packed := unitpacking.PackedNormal{Codec: unitpacking.Oct16, Code: 0x1234}
+	assert.Equal(t, packed.Normal(), unitpacking.NormalFromColor(packed))
+}
+
+func TestNormalModel_RepacksOtherCodecs(t *testing.T) {
+	model := unitpacking.NormalModel(unitpacking.Oct24)
+
+	same := model.Convert(unitpacking.PackedNormal{Codec: unitpacking.Oct24, Code: 0x123456}).(unitpacking.PackedNormal)
+	assert.Equal(t, unitpacking.Oct24.Name(), same.Codec.Name())
+	assert.Equal(t, uint32(0x123456), same.Code)
+
+	v := testVectors[3].Normalized()
+	other := unitpacking.PackedNormal{
+		Codec: unitpacking.Oct32,
+		Code:  unitpacking.PackOct32Uint32(v),
+	}
+	converted := model.Convert(other).(unitpacking.PackedNormal)
+	assert.Equal(t, unitpacking.Oct24.Name(), converted.Codec.Name())
+	assert.Equal(t, unitpacking.PackOct24Uint32(unitpacking.UnpackOct32Uint32(other.Code)), converted.Code)
+}
+
+func TestOctNormalImage_Draw(t *testing.T) {
+	normalMap := image.NewRGBA64(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			v := vector.NewVector3(float64(x-4)/8, float64(y-4)/8, 1).Normalized()
+			normalMap.SetRGBA64(x, y, color.RGBA64{
+				R: uint16(math.Round((v.X() + 1) / 2 * 0xFFFF)),
+				G: uint16(math.Round((v.Y() + 1) / 2 * 0xFFFF)),
+				B: uint16(math.Round((v.Z() + 1) / 2 * 0xFFFF)),
+				A: 0xFFFF,
+			})
+		}
+	}
+
+	for _, codec := range imageCodecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(normalMap.Bounds(), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+			draw.Draw(img, img.Bounds(), normalMap, image.Point{}, draw.Src)
+
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					expected := unitpacking.NormalFromColor(normalMap.At(x, y))
+					assert.Equal(t, codec.Unpack(codec.Pack(expected)), img.NormalAt(x, y))
+				}
+			}
+		})
+	}
+}
+
+func TestOctNormalImage_EncodesAsNormalMap(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 4, 4), unitpacking.Oct32)
+	if !assert.NoError(t, err) {
+		return
+	}
+	fillTestNormals(img)
+
+	buf := bytes.Buffer{}
+	if !assert.NoError(t, png.Encode(&buf, img)) {
+		return
+	}
+
+	decoded, err := png.Decode(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			// 8 bit channels are good to roughly half a degree
+			err := unitpacking.AngularError(img.NormalAt(x, y), unitpacking.NormalFromColor(decoded.At(x, y)))
+			assert.Less(t, err*180/math.Pi, 0.5)
+		}
+	}
+}
+
+func TestOctNormalImage_CodeImageRoundTrip(t *testing.T) {
+	for _, codec := range imageCodecs {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 5, 3), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+			fillTestNormals(img)
+
+			codes := img.CodeImage()
+			assert.True(t, codes.(interface{ Opaque() bool }).Opaque())
+
+			if codec.Size() == 4 {
+				first := codes.(*image.NRGBA64).NRGBA64At(0, 0)
+				assert.Equal(t, binary.LittleEndian.Uint32(img.Pix), uint32(first.R)|uint32(first.G)<<16)
+				assert.Equal(t, uint16(0), first.B)
+			} else {
+				first := codes.(*image.NRGBA).NRGBAAt(0, 0)
+				channels := []byte{first.R, first.G, first.B}
+				assert.Equal(t, img.Pix[:codec.Size()], channels[:codec.Size()])
+			}
+
+			buf := bytes.Buffer{}
+			if !assert.NoError(t, png.Encode(&buf, codes)) {
+				return
+			}
+			decoded, err := png.Decode(&buf)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			back, err := unitpacking.NewOctNormalImageFromCodes(decoded, codec)
+			if assert.NoError(t, err) {
+				assert.Equal(t, img.Pix, back.Pix)
+			}
+		})
+	}
+}
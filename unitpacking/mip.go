@@ -0,0 +1,151 @@
+package unitpacking
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+// MipLevel is a single level of a mip chain.
+type MipLevel struct {
+	// Normals is the level's averaged and renormalized normals.
+	Normals *OctNormalImage
+
+	// Lengths holds the length of each texel's average normal before it was
+	// renormalized, scaled from [0, 1] to [0, 0xFFFF]. Nil unless the
+	// generator was asked for them.
+	Lengths *image.Gray16
+}
+
+// MipGenerator builds mip chains for packed normal images.
+//
+// Averaging packed codes directly produces garbage wherever neighboring codes
+// sit on opposite sides of a fold of the octahedron. Instead, each texel is
+// decoded and the unit vectors under its footprint are averaged, then the
+// average is renormalized and packed again with the image's codec.
+//
+// The length of the average shrinks the more the normals under a texel
+// disagree. Toksvig's method turns it into a roughness adjustment: a specular
+// power s becomes s * L / (L + s * (1 - L)).
+type MipGenerator struct {
+	// Levels is the most levels to generate after the base image. Zero
+	// generates every level down to 1x1.
+	Levels int
+
+	// Lengths also records the length of each texel's average normal.
+	Lengths bool
+}
+
+// mipTexel is the sum of the base texels a mip texel covers, so every level
+// averages the original normals rather than the quantized ones of the level
+// above it.
+type mipTexel struct {
+	sum   vector.Vector3
+	count int
+}
+
+// Generate builds the mip chain for the image, starting with the image itself
+// as level 0. Each level halves the width and height of the one above it,
+// rounding down and stopping at 1. Levels with an odd dimension give the
+// last texel of each row or column a 3 texel wide footprint so no texel is
+// skipped.
+//
+// Texels holding a NullCodec's null code are left out of the averages, and a
+// texel whose footprint only holds null codes is null itself. A texel whose
+// normals cancel out entirely is packed as the flat normal (0, 0, 1).
+func (g MipGenerator) Generate(base *OctNormalImage) []MipLevel {
+	nullCodec, _ := base.Codec.(NullCodec)
+
+	width, height := base.Rect.Dx(), base.Rect.Dy()
+	texels := make([]mipTexel, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			code := base.code(base.Rect.Min.X+x, base.Rect.Min.Y+y)
+			if nullCodec != nil && IsNull(nullCodec, code) {
+				continue
+			}
+			texels[x+(y*width)] = mipTexel{sum: base.Codec.Unpack(code), count: 1}
+		}
+	}
+
+	levels := []MipLevel{{Normals: base}}
+	if g.Lengths {
+		levels[0].Lengths = g.lengths(base.Rect, texels)
+	}
+
+	for (width > 1 || height > 1) && (g.Levels <= 0 || len(levels) <= g.Levels) {
+		nextWidth, nextHeight := halveMipSize(width), halveMipSize(height)
+		next := make([]mipTexel, nextWidth*nextHeight)
+		for y := 0; y < height; y++ {
+			ny := mipFootprint(y, height, nextHeight)
+			for x := 0; x < width; x++ {
+				nx := mipFootprint(x, width, nextWidth)
+				t := texels[x+(y*width)]
+				n := &next[nx+(ny*nextWidth)]
+				n.sum = n.sum.Add(t.sum)
+				n.count += t.count
+			}
+		}
+		width, height, texels = nextWidth, nextHeight, next
+
+		level := MipLevel{Normals: g.normals(base.Codec, nullCodec, width, height, texels)}
+		if g.Lengths {
+			level.Lengths = g.lengths(level.Normals.Rect, texels)
+		}
+		levels = append(levels, level)
+	}
+
+	return levels
+}
+
+func halveMipSize(size int) int {
+	if size <= 1 {
+		return 1
+	}
+	return size / 2
+}
+
+// mipFootprint returns which texel of the smaller level covers the given
+// texel, folding the odd texel at the end into the last footprint.
+func mipFootprint(i, size, nextSize int) int {
+	if size == 1 {
+		return 0
+	}
+	if i/2 >= nextSize {
+		return nextSize - 1
+	}
+	return i / 2
+}
+
+func (g MipGenerator) normals(codec Codec, nullCodec NullCodec, width, height int, texels []mipTexel) *OctNormalImage {
+	// The base image was already built with this codec, so it fits
+	img, _ := NewOctNormalImage(image.Rect(0, 0, width, height), codec)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			t := texels[x+(y*width)]
+			switch {
+			case t.count == 0 && nullCodec != nil:
+				copy(img.code(x, y), nullCodec.Null())
+			case t.sum.Length() == 0:
+				img.SetNormal(x, y, vector.NewVector3(0, 0, 1))
+			default:
+				img.SetNormal(x, y, t.sum.Normalized())
+			}
+		}
+	}
+	return img
+}
+
+func (g MipGenerator) lengths(r image.Rectangle, texels []mipTexel) *image.Gray16 {
+	img := image.NewGray16(r)
+	for i, t := range texels {
+		length := 0.0
+		if t.count > 0 {
+			length = Clamp(t.sum.Length()/float64(t.count), 0, 1)
+		}
+		img.SetGray16(r.Min.X+(i%r.Dx()), r.Min.Y+(i/r.Dx()), color.Gray16{Y: uint16(math.Round(length * 0xFFFF))})
+	}
+	return img
+}
@@ -0,0 +1,146 @@
+package unitpacking_test
+
+import (
+	"image"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func mipSizes(levels []unitpacking.MipLevel) []image.Point {
+	sizes := make([]image.Point, len(levels))
+	for i, level := range levels {
+		sizes[i] = level.Normals.Bounds().Size()
+	}
+	return sizes
+}
+
+func TestMipGenerator_Sizes(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 8, 4), unitpacking.Oct16)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	levels := unitpacking.MipGenerator{}.Generate(img)
+	assert.Same(t, img, levels[0].Normals)
+	assert.Equal(t, []image.Point{{8, 4}, {4, 2}, {2, 1}, {1, 1}}, mipSizes(levels))
+
+	levels = unitpacking.MipGenerator{Levels: 2}.Generate(img)
+	assert.Equal(t, []image.Point{{8, 4}, {4, 2}, {2, 1}}, mipSizes(levels))
+
+	odd, err := unitpacking.NewOctNormalImage(image.Rect(3, 3, 8, 6), unitpacking.Oct16)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []image.Point{{5, 3}, {2, 1}, {1, 1}}, mipSizes(unitpacking.MipGenerator{}.Generate(odd)))
+}
+
+func TestMipGenerator_AveragesAcrossFolds(t *testing.T) {
+	// Each of these lands in a different corner of the octahedral square, so
+	// averaging their codes would give nothing close to straight down
+	down := []vector.Vector3{
+		vector.NewVector3(0.2, 0.2, -1).Normalized(),
+		vector.NewVector3(-0.2, 0.2, -1).Normalized(),
+		vector.NewVector3(0.2, -0.2, -1).Normalized(),
+		vector.NewVector3(-0.2, -0.2, -1).Normalized(),
+	}
+
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 2, 2), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for i, v := range down {
+				img.SetNormal(i%2, i/2, v)
+			}
+
+			levels := unitpacking.MipGenerator{}.Generate(img)
+			if !assert.Len(t, levels, 2) {
+				return
+			}
+
+			expected := codec.Unpack(codec.Pack(vector.NewVector3(0, 0, -1)))
+			assert.InDelta(t, 0, unitpacking.AngularError(expected, levels[1].Normals.NormalAt(0, 0)), 0.02)
+		})
+	}
+}
+
+func TestMipGenerator_AveragesBaseTexels(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 5, 7), unitpacking.OctQuad24)
+	if !assert.NoError(t, err) {
+		return
+	}
+	fillTestNormals(img)
+
+	sum := vector.Vector3{}
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 5; x++ {
+			sum = sum.Add(img.NormalAt(x, y))
+		}
+	}
+
+	levels := unitpacking.MipGenerator{Lengths: true}.Generate(img)
+	last := levels[len(levels)-1]
+	assert.Equal(t, image.Pt(1, 1), last.Normals.Bounds().Size())
+
+	// Every level is averaged from the base texels, so the last one matches
+	// the average of the entire image to within the codec's precision
+	assert.Less(t, unitpacking.AngularError(sum, last.Normals.NormalAt(0, 0)), 0.001)
+	assert.InDelta(t, sum.Length()/35, float64(last.Lengths.Gray16At(0, 0).Y)/0xFFFF, 1e-4)
+}
+
+func TestMipGenerator_Lengths(t *testing.T) {
+	img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 4, 2), unitpacking.Oct32)
+	if !assert.NoError(t, err) {
+		return
+	}
+	img.SetNormal(0, 0, vector.NewVector3(1, 0, 1).Normalized())
+	img.SetNormal(1, 0, vector.NewVector3(-1, 0, 1).Normalized())
+	img.SetNormal(0, 1, vector.NewVector3(1, 0, 1).Normalized())
+	img.SetNormal(1, 1, vector.NewVector3(-1, 0, 1).Normalized())
+
+	assert.Nil(t, unitpacking.MipGenerator{}.Generate(img)[1].Lengths)
+
+	levels := unitpacking.MipGenerator{Lengths: true}.Generate(img)
+	assert.Equal(t, image.Rect(0, 0, 4, 2), levels[0].Lengths.Bounds())
+	assert.Equal(t, uint16(0xFFFF), levels[0].Lengths.Gray16At(3, 1).Y)
+
+	// The left texel is split between two normals 90 degrees apart, the right
+	// one is flat everywhere
+	assert.InDelta(t, math.Sqrt(0.5), float64(levels[1].Lengths.Gray16At(0, 0).Y)/0xFFFF, 1e-4)
+	assert.Equal(t, uint16(0xFFFF), levels[1].Lengths.Gray16At(1, 0).Y)
+	assert.Less(t, unitpacking.AngularError(vector.NewVector3(0, 0, 1), levels[1].Normals.NormalAt(0, 0)), 1e-4)
+}
+
+func TestMipGenerator_SkipsNullCodes(t *testing.T) {
+	for _, codec := range []unitpacking.NullCodec{unitpacking.Oct24, unitpacking.OctQuad16, unitpacking.Alg24} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			img, err := unitpacking.NewOctNormalImage(image.Rect(0, 0, 4, 2), codec)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			right := vector.NewVector3(1, 0, 0)
+			img.SetNormal(0, 0, right)
+			for _, p := range []image.Point{{1, 0}, {0, 1}, {1, 1}, {2, 0}, {3, 0}, {2, 1}, {3, 1}} {
+				copy(img.Pix[img.PixOffset(p.X, p.Y):], codec.Null())
+			}
+			if p := img.PixOffset(0, 0); !assert.False(t, unitpacking.IsNull(codec, img.Pix[p:p+codec.Size()])) {
+				return
+			}
+
+			levels := unitpacking.MipGenerator{Lengths: true}.Generate(img)
+			mip := levels[1].Normals
+
+			assert.Equal(t, codec.Pack(codec.Unpack(codec.Pack(right))), mip.Pix[mip.PixOffset(0, 0):mip.PixOffset(0, 0)+codec.Size()])
+			assert.Greater(t, levels[1].Lengths.Gray16At(0, 0).Y, uint16(0xFF00))
+
+			assert.Equal(t, codec.Null(), mip.Pix[mip.PixOffset(1, 0):mip.PixOffset(1, 0)+codec.Size()])
+			assert.Equal(t, uint16(0), levels[1].Lengths.Gray16At(1, 0).Y)
+		})
+	}
+}
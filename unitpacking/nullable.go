@@ -0,0 +1,32 @@
+package unitpacking
+
+import (
+	"bytes"
+
+	"github.com/EliCDavis/vector"
+)
+
+// PackNullable packs the vector with the given codec, or writes the codec's
+// null code if there is no vector.
+func PackNullable(codec NullCodec, v *vector.Vector3) []byte {
+	if v == nil {
+		return codec.Null()
+	}
+	return codec.Pack(*v)
+}
+
+// UnpackNullable unpacks a vector previously packed with PackNullable.
+// Returns false if the bytes hold the codec's null code.
+func UnpackNullable(codec NullCodec, b []byte) (vector.Vector3, bool) {
+	if IsNull(codec, b) {
+		return vector.Vector3Zero(), false
+	}
+	return codec.Unpack(b), true
+}
+
+// IsNull determines whether or not the bytes start with the codec's null
+// code. Like Unpack, only the first Size bytes are read, and bytes too short
+// to hold a code are never the null code.
+func IsNull(codec NullCodec, b []byte) bool {
+	return len(b) >= codec.Size() && bytes.Equal(b[:codec.Size()], codec.Null())
+}
@@ -0,0 +1,87 @@
+package unitpacking_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNullable_RoundTrip(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		nullCodec := codec.(unitpacking.NullCodec)
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			packed := unitpacking.PackNullable(nullCodec, nil)
+			assert.Len(t, packed, codec.Size())
+			assert.True(t, unitpacking.IsNull(nullCodec, packed))
+
+			_, ok := unitpacking.UnpackNullable(nullCodec, packed)
+			assert.False(t, ok)
+
+			for _, tc := range testVectors {
+				unit := tc.Normalized()
+				packed := unitpacking.PackNullable(nullCodec, &unit)
+				assert.Equal(t, codec.Pack(unit), packed)
+
+				unpacked, ok := unitpacking.UnpackNullable(nullCodec, packed)
+				assert.True(t, ok)
+				assert.Equal(t, codec.Unpack(packed), unpacked)
+			}
+		})
+	}
+}
+
+func TestNullable_OnlyOneNullCode(t *testing.T) {
+	for _, codec := range []unitpacking.NullCodec{unitpacking.Oct16, unitpacking.OctQuad16} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			nulls := 0
+			for i := 0; i < 1<<16; i++ {
+				if unitpacking.IsNull(codec, []byte{byte(i), byte(i >> 8)}) {
+					nulls++
+				}
+			}
+			assert.Equal(t, 1, nulls)
+		})
+	}
+}
+
+func TestIsNull_WrongLength(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		nullCodec := codec.(unitpacking.NullCodec)
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			null := nullCodec.Null()
+			assert.False(t, unitpacking.IsNull(nullCodec, nil))
+			assert.False(t, unitpacking.IsNull(nullCodec, null[:len(null)-1]))
+
+			// Longer buffers, like a slice into a stream, are read up to the
+			// end of their first code
+			longer := append(nullCodec.Null(), 0xFF, 0xFF)
+			assert.True(t, unitpacking.IsNull(nullCodec, longer))
+			_, ok := unitpacking.UnpackNullable(nullCodec, longer)
+			assert.False(t, ok)
+
+			packed := append(codec.Pack(vector.NewVector3(0, 0, 1)), null...)
+			assert.False(t, unitpacking.IsNull(nullCodec, packed))
+		})
+	}
+}
+
+// Codes written before the null code was reserved still decode the same
+// way, and the only vectors whose codes moved are the ones that landed in
+// the reserved octquad cell.
+func TestNullable_ExistingCodesUnchanged(t *testing.T) {
+	southWest := vector.NewVector3(-0.0001, -0.0001, -1).Normalized()
+
+	packed := unitpacking.PackOctQuad16(southWest)
+	assert.Equal(t, []byte{0b10101011, 0b10101010}, packed)
+	assert.InDelta(t, 0, unitpacking.AngularError(southWest, unitpacking.UnpackOctQuad16(packed)), 0.02)
+
+	diagonal := vector.NewVector3(1, 1, 1).Normalized()
+	assert.Equal(t, []byte{0xAA, 0xBA, 0xAA}, unitpacking.PackOct24(diagonal))
+	assert.Equal(t, []byte{0x66, 0x66, 0x66}, unitpacking.PackOctQuad24(diagonal))
+	assert.Equal(t, []byte{0xC9, 0xC9, 0xC9}, unitpacking.PackCoarse24(diagonal))
+	assert.Equal(t, []byte{0x01, 0xF8, 0xFF}, unitpacking.PackAlg24(vector.NewVector3(1, 0, 0)))
+}
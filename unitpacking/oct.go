@@ -31,13 +31,7 @@ func multVect(a, b vector.Vector2) vector.Vector2 {
 // PackOct32 maps a unit vector to a 2D UV of a octahedron, and then writes the
 // 2D coordinates to 4 bytes, 2 bytes per coordinate.
 func PackOct32(v vector.Vector3) []byte {
-	uvCords := MapToOctUVPrecise(v, 32)
-
-	// 2 ^ 16 = 65,536;
-	x := uint(math.Floor(uvCords.X()*32767) + 32768)
-	y := uint(math.Floor(uvCords.Y()*32767) + 32768)
-	everything := (x << 16) | y
-
+	everything := PackOct32Uint32(v)
 	return []byte{
 		(byte)(everything & 0xFF),
 		(byte)((everything >> 8) & 0xFF),
@@ -46,10 +40,28 @@ func PackOct32(v vector.Vector3) []byte {
 	}
 }
 
+// PackOct32Uint32 is PackOct32 without splitting the result into bytes. X
+// occupies the upper 16 bits and Y the lower 16.
+func PackOct32Uint32(v vector.Vector3) uint32 {
+	uvCords := MapToOctUVPrecise(v, 32)
+
+	// 2 ^ 16 = 65,536; The UV is already on the lattice, rounding just
+	// discards floating point error so repacking an unpacked code never
+	// drifts.
+	x := uint32(math.Round(uvCords.X()*32767) + 32768)
+	y := uint32(math.Round(uvCords.Y()*32767) + 32768)
+	return (x << 16) | y
+}
+
 // UnpackOct32 reads in two 16bit numbers and converts from 2D octahedron UV to
 // 3D unit sphere coordinates.
 func UnpackOct32(b []byte) vector.Vector3 {
-	everything := uint(b[0]) | (uint(b[1]) << 8) | (uint(b[2]) << 16) | (uint(b[3]) << 24)
+	return UnpackOct32Uint32(uint32(b[0]) | (uint32(b[1]) << 8) | (uint32(b[2]) << 16) | (uint32(b[3]) << 24))
+}
+
+// UnpackOct32Uint32 is UnpackOct32 for a code previously packed with
+// PackOct32Uint32.
+func UnpackOct32Uint32(everything uint32) vector.Vector3 {
 	rawY := (int)((everything) & 0xFFFF)
 	rawX := (int)(everything >> 16)
 
@@ -62,13 +74,7 @@ func UnpackOct32(b []byte) vector.Vector3 {
 // PackOct24 maps a unit vector to a 2D UV of a octahedron, and then writes the
 // 2D coordinates to 3 bytes, 12bits per coordinate.
 func PackOct24(v vector.Vector3) []byte {
-	uvCords := MapToOctUVPrecise(v, 24)
-
-	// 2 ^ 12 = 4,096;
-	x := uint(math.Floor(uvCords.X()*2047) + 2048)
-	y := uint(math.Floor(uvCords.Y()*2047) + 2048)
-	everything := (x << 12) | y
-
+	everything := PackOct24Uint32(v)
 	return []byte{
 		(byte)(everything & 0xFF),
 		(byte)((everything >> 8) & 0xFF),
@@ -76,12 +82,29 @@ func PackOct24(v vector.Vector3) []byte {
 	}
 }
 
+// PackOct24Uint32 is PackOct24 without splitting the result into bytes. X
+// occupies bits 12 through 23 and Y the lowest 12 bits, leaving the upper 8
+// bits unused.
+func PackOct24Uint32(v vector.Vector3) uint32 {
+	uvCords := MapToOctUVPrecise(v, 24)
+
+	// 2 ^ 12 = 4,096;
+	x := uint32(math.Round(uvCords.X()*2047) + 2048)
+	y := uint32(math.Round(uvCords.Y()*2047) + 2048)
+	return (x << 12) | y
+}
+
 // UnpackOct24 reads in two 12bit numbers and converts from 2D octahedron UV to
 // 3D unit sphere coordinates.
 func UnpackOct24(b []byte) vector.Vector3 {
-	everything := uint(b[0]) | (uint(b[1]) << 8) | (uint(b[2]) << 16)
+	return UnpackOct24Uint32(uint32(b[0]) | (uint32(b[1]) << 8) | (uint32(b[2]) << 16))
+}
+
+// UnpackOct24Uint32 is UnpackOct24 for a code previously packed with
+// PackOct24Uint32. The upper 8 bits are ignored.
+func UnpackOct24Uint32(everything uint32) vector.Vector3 {
 	rawY := (int)((everything) & 0b111111111111)
-	rawX := (int)(everything >> 12)
+	rawX := (int)((everything >> 12) & 0b111111111111)
 
 	cleanedX := Clamp((float64(rawX)-2048.0)/2047.0, -1.0, 1.0)
 	cleanedY := Clamp((float64(rawY)-2048.0)/2047.0, -1.0, 1.0)
@@ -92,23 +115,33 @@ func UnpackOct24(b []byte) vector.Vector3 {
 // PackOct16 maps a unit vector to a 2D UV of a octahedron, and then writes the
 // 2D coordinates to 2 bytes, 8bits per coordinate.
 func PackOct16(v vector.Vector3) []byte {
-	uvCords := MapToOctUVPrecise(v, 16)
-
-	// 2 ^ 8 = 256;
-	x := uint(math.Floor(uvCords.X()*127) + 128)
-	y := uint(math.Floor(uvCords.Y()*127) + 128)
-	everything := (x << 8) | y
-
+	everything := PackOct16Uint16(v)
 	return []byte{
 		(byte)(everything & 0xFF),
 		(byte)((everything >> 8) & 0xFF),
 	}
 }
 
+// PackOct16Uint16 is PackOct16 without splitting the result into bytes. X
+// occupies the upper 8 bits and Y the lower 8.
+func PackOct16Uint16(v vector.Vector3) uint16 {
+	uvCords := MapToOctUVPrecise(v, 16)
+
+	// 2 ^ 8 = 256;
+	x := uint16(math.Round(uvCords.X()*127) + 128)
+	y := uint16(math.Round(uvCords.Y()*127) + 128)
+	return (x << 8) | y
+}
+
 // UnpackOct16 reads in two 8bit numbers and converts from 2D octahedron UV to
 // 3D unit sphere coordinates.
 func UnpackOct16(b []byte) vector.Vector3 {
-	everything := uint(b[0]) | (uint(b[1]) << 8)
+	return UnpackOct16Uint16(uint16(b[0]) | (uint16(b[1]) << 8))
+}
+
+// UnpackOct16Uint16 is UnpackOct16 for a code previously packed with
+// PackOct16Uint16.
+func UnpackOct16Uint16(everything uint16) vector.Vector3 {
 	rawY := (int)((everything) & 0b11111111)
 	rawX := (int)(everything >> 8)
 
@@ -119,41 +152,67 @@ func UnpackOct16(b []byte) vector.Vector3 {
 }
 
 // MapToOctUVPrecise brute force finds an optimal UV coordinate that minimizes
-// rounding error.
+// rounding error. The UV returned always lies on the snorm(n/2) lattice, and
+// points along the edges of the square that fold onto the same direction are
+// always returned as the same point. Vectors with no direction, zero length
+// or with a NaN or infinite component, are returned as the center of the
+// square.
 func MapToOctUVPrecise(v vector.Vector3, n int) vector.Vector2 {
 	s := MapToOctUV(v) // Remap to the square
+	if math.IsNaN(s.X()) || math.IsNaN(s.Y()) {
+		return vector.NewVector2(0, 0)
+	}
 
 	// Each snorm’s max value interpreted as an integer,
 	// e.g., 127.0 for snorm8
 	M := float64(int(1)<<((n/2)-1)) - 1.0
 
 	// Remap components to snorm(n/2) precision...with floor instead
-	// of round (see equation 1)
-	s = floorVec2(clampVec2(s, -1.0, 1.0).MultByConstant(M)).MultByConstant(1.0 / M)
-	bestRepresentation := s
-	highestCosine := FromOctUV(s).Dot(v)
+	// of round (see equation 1). Kept as whole numbers so every candidate
+	// lands exactly on the lattice.
+	base := floorVec2(clampVec2(s, -1.0, 1.0).MultByConstant(M))
+	bestRepresentation := vector.NewVector2(base.X()/M, base.Y()/M)
+	highestCosine := FromOctUV(bestRepresentation).Dot(v)
 
 	// Test all combinations of floor and ceil and keep the best.
-	// Note that at +/- 1, this will exit the square... but that
-	// will be a worse encoding and never win.
-	for i := 0; i <= 1; i++ {
-		for j := 0; j <= 1; j++ {
-			// This branch will be evaluated at compile time
-			if (i != 0) || (j != 0) {
-				// Offset the bit pattern (which is stored in floating
-				// point!) to effectively change the rounding mode
-				// (when i or j is 0: floor, when it is one: ceiling)
-				candidate := vector.NewVector2(float64(i), float64(j)).MultByConstant(1 / M).Add(s)
-				cosine := FromOctUV(candidate).Dot(v)
-				if cosine > highestCosine {
-					bestRepresentation = candidate
-					highestCosine = cosine
-				}
+	for i := 0.0; i <= 1; i++ {
+		for j := 0.0; j <= 1; j++ {
+			if (i == 0) && (j == 0) {
+				continue
+			}
+
+			// Candidates past +/- 1 exit the square and can't be written
+			// out.
+			if base.X()+i > M || base.Y()+j > M {
+				continue
+			}
+
+			// (when i or j is 0: floor, when it is one: ceiling)
+			candidate := vector.NewVector2((base.X()+i)/M, (base.Y()+j)/M)
+			cosine := FromOctUV(candidate).Dot(v)
+			if cosine > highestCosine {
+				bestRepresentation = candidate
+				highestCosine = cosine
 			}
 		}
 	}
 
-	return bestRepresentation
+	return foldOctSeams(bestRepresentation)
+}
+
+// foldOctSeams picks a single UV for directions that sit on the seams of the
+// lower hemisphere, where (+/-1, y) is the same direction as (+/-1, -y), and
+// (x, +/-1) the same as (-x, +/-1). All four corners are the -Z pole.
+func foldOctSeams(uv vector.Vector2) vector.Vector2 {
+	x := uv.X()
+	y := uv.Y()
+	if math.Abs(x) == 1 {
+		y = math.Abs(y)
+	}
+	if math.Abs(y) == 1 {
+		x = math.Abs(x)
+	}
+	return vector.NewVector2(x, y)
 }
 
 // MapToOctUV converts a 3D sphere's coordinates to a 2D octahedron UV.
@@ -2,10 +2,56 @@ package unitpacking
 
 import "github.com/EliCDavis/vector"
 
+// octQuadNullWord is the code where every level of the quad tree goes to the
+// bottom left, the corner cell of the octahedron UV closest to (-1, -1).
+// This cell is reserved as the null code, and vectors that fall inside it are
+// instead written to the cell just to the right of it, which also lies next
+// to the -Z pole.
+func octQuadNullWord(levels int) uint32 {
+	null := uint32(0)
+	for i := 0; i < levels; i++ {
+		null = (null << 2) | uint32(BottomLeft)
+	}
+	return null
+}
+
+// octQuadNull is octQuadNullWord split into bytes, for a quad tree filling
+// every bit of the given number of bytes.
+func octQuadNull(size int) []byte {
+	word := octQuadNullWord(size * 4)
+	null := make([]byte, size)
+	for i := range null {
+		null[i] = byte(word >> (i * 8))
+	}
+	return null
+}
+
+// avoidOctQuadNull moves a code off of the null code of a quad tree with the
+// given number of levels.
+func avoidOctQuadNull(code uint32, levels int) uint32 {
+	if code != octQuadNullWord(levels) {
+		return code
+	}
+
+	// Lowest 2 bits hold the deepest level of the quad tree. Go right instead
+	// of left.
+	return code | uint32(BottomRight)
+}
+
 // PackOctQuad16 maps a unit vector to a 2D UV of a octahedron, and then
 // encodes the 2D coordinates inside a quad tree.
 func PackOctQuad16(v vector.Vector3) []byte {
-	return Vec2ToTwoByteQuad(MapToOctUV(v))
+	code := PackOctQuad16Uint16(v)
+	return []byte{
+		byte(code),
+		byte(code >> 8),
+	}
+}
+
+// PackOctQuad16Uint16 is PackOctQuad16 without splitting the result into
+// bytes. The deepest level of the quad tree is in the lowest 2 bits.
+func PackOctQuad16Uint16(v vector.Vector3) uint16 {
+	return uint16(avoidOctQuadNull(quadCode(MapToOctUV(v), 8), 8))
 }
 
 // UnpackOctQuad16 builds a 2D coordinate from the encoded quadtree and then
@@ -14,10 +60,28 @@ func UnpackOctQuad16(b []byte) vector.Vector3 {
 	return FromOctUV(TwoByteQuadToVec2(b))
 }
 
+// UnpackOctQuad16Uint16 is UnpackOctQuad16 for a code previously packed with
+// PackOctQuad16Uint16.
+func UnpackOctQuad16Uint16(code uint16) vector.Vector3 {
+	return FromOctUV(quadDecode(uint32(code), 8))
+}
+
 // PackOctQuad24 maps a unit vector to a 2D UV of a octahedron, and then
 // encodes the 2D coordinates inside a quad tree.
 func PackOctQuad24(v vector.Vector3) []byte {
-	return Vec2ToThreeByteQuad(MapToOctUV(v))
+	code := PackOctQuad24Uint32(v)
+	return []byte{
+		byte(code),
+		byte(code >> 8),
+		byte(code >> 16),
+	}
+}
+
+// PackOctQuad24Uint32 is PackOctQuad24 without splitting the result into
+// bytes. The deepest level of the quad tree is in the lowest 2 bits, and the
+// upper 8 bits are unused.
+func PackOctQuad24Uint32(v vector.Vector3) uint32 {
+	return avoidOctQuadNull(quadCode(MapToOctUV(v), 12), 12)
 }
 
 // UnpackOctQuad24 builds a 2D coordinate from the encoded quadtree and then
@@ -26,10 +90,28 @@ func UnpackOctQuad24(b []byte) vector.Vector3 {
 	return FromOctUV(ThreeByteQuadToVec2(b))
 }
 
+// UnpackOctQuad24Uint32 is UnpackOctQuad24 for a code previously packed with
+// PackOctQuad24Uint32. The upper 8 bits are ignored.
+func UnpackOctQuad24Uint32(code uint32) vector.Vector3 {
+	return FromOctUV(quadDecode(code&0xFFFFFF, 12))
+}
+
 // PackOctQuad32 maps a unit vector to a 2D UV of a octahedron, and then
 // encodes the 2D coordinates inside a quad tree.
 func PackOctQuad32(v vector.Vector3) []byte {
-	return Vec2ToFourByteQuad(MapToOctUV(v))
+	code := PackOctQuad32Uint32(v)
+	return []byte{
+		byte(code),
+		byte(code >> 8),
+		byte(code >> 16),
+		byte(code >> 24),
+	}
+}
+
+// PackOctQuad32Uint32 is PackOctQuad32 without splitting the result into
+// bytes. The deepest level of the quad tree is in the lowest 2 bits.
+func PackOctQuad32Uint32(v vector.Vector3) uint32 {
+	return avoidOctQuadNull(quadCode(MapToOctUV(v), 16), 16)
 }
 
 // UnpackOctQuad32 builds a 2D coordinate from the encoded quadtree and then
@@ -37,3 +119,9 @@ func PackOctQuad32(v vector.Vector3) []byte {
 func UnpackOctQuad32(b []byte) vector.Vector3 {
 	return FromOctUV(FourByteQuadToVec2(b))
 }
+
+// UnpackOctQuad32Uint32 is UnpackOctQuad32 for a code previously packed with
+// PackOctQuad32Uint32.
+func UnpackOctQuad32Uint32(code uint32) vector.Vector3 {
+	return FromOctUV(quadDecode(code, 16))
+}
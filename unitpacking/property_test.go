@@ -0,0 +1,126 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+type codecBounds struct {
+	// maxAngle is the most a packed vector's direction can stray in
+	// degrees.
+	maxAngle float64
+
+	// maxLengthErr is how far from 1 the length of an unpacked unit vector
+	// can be. alg24 and coarse24 don't normalize what they unpack.
+	maxLengthErr float64
+
+	// alwaysUnit is set when every possible code unpacks to a unit vector.
+	alwaysUnit bool
+}
+
+var bounds = map[string]codecBounds{
+	"alg24":     {maxAngle: 2.0, maxLengthErr: 0.001},
+	"coarse24":  {maxAngle: 0.45, maxLengthErr: 0.01},
+	"oct16":     {maxAngle: 0.7, maxLengthErr: 1e-12, alwaysUnit: true},
+	"oct24":     {maxAngle: 0.045, maxLengthErr: 1e-12, alwaysUnit: true},
+	"oct32":     {maxAngle: 0.003, maxLengthErr: 1e-12, alwaysUnit: true},
+	"octquad16": {maxAngle: 1.05, maxLengthErr: 1e-12, alwaysUnit: true},
+	"octquad24": {maxAngle: 0.065, maxLengthErr: 1e-12, alwaysUnit: true},
+	"octquad32": {maxAngle: 0.0042, maxLengthErr: 1e-12, alwaysUnit: true},
+	"hemioct16": {maxAngle: 0.6, maxLengthErr: 1e-12, alwaysUnit: true},
+	"hemioct24": {maxAngle: 0.036, maxLengthErr: 1e-12, alwaysUnit: true},
+	"hemioct32": {maxAngle: 0.0023, maxLengthErr: 1e-12, alwaysUnit: true},
+}
+
+// coveredBy mirrors vectors on the lower hemisphere onto the upper one for
+// the hemi-octahedral codecs, which only cover the upper hemisphere.
+func coveredBy(codec unitpacking.Codec, v vector.Vector3) vector.Vector3 {
+	for _, hemi := range unitpacking.HemiCodecs() {
+		if codec.Name() == hemi.Name() {
+			return vector.NewVector3(v.X(), v.Y(), math.Abs(v.Z()))
+		}
+	}
+	return v
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// checkPacking verifies everything that should hold for a unit vector packed
+// by the codec, returning a description of the first problem found.
+func checkPacking(codec unitpacking.Codec, unit vector.Vector3) (string, bool) {
+	b := bounds[codec.Name()]
+
+	packed := codec.Pack(unit)
+	if len(packed) != codec.Size() {
+		return "wrong size", false
+	}
+
+	unpacked := codec.Unpack(packed)
+	if math.Abs(unpacked.Length()-1) > b.maxLengthErr {
+		return "not unit length", false
+	}
+
+	if degrees(unitpacking.AngularError(unit, unpacked)) > b.maxAngle {
+		return "angular error out of bounds", false
+	}
+
+	if !bytes.Equal(packed, codec.Pack(unpacked)) {
+		return "repacking changed the code", false
+	}
+
+	return "", true
+}
+
+func TestProperty_UniformSphere(t *testing.T) {
+	// A little over four million directions, cut down to sixteen thousand
+	// under -short
+	samples := 1 << 22
+	if testing.Short() {
+		samples = 1 << 14
+	}
+
+	r := rand.New(rand.NewSource(1))
+	units := make([]vector.Vector3, samples)
+	for i := range units {
+		// Normally distributed components are uniformly distributed across
+		// the sphere once normalized.
+		units[i] = vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64()).Normalized()
+	}
+
+	for _, codec := range unitpacking.AllCodecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, unit := range units {
+				unit = coveredBy(codec, unit)
+				if problem, ok := checkPacking(codec, unit); !ok {
+					t.Fatalf("%v: %s", unit, problem)
+				}
+			}
+		})
+	}
+}
+
+// normalizer rescales anything that isn't exactly unit length, including
+// the subnormal and huge vectors that Normalized can't handle.
+var normalizer = unitpacking.Validator{Policy: unitpacking.PolicyNormalize, Tolerance: 1e-15}
+
+func TestProperty_EdgeCases(t *testing.T) {
+	for _, codec := range unitpacking.AllCodecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, v := range edgeCaseVectors {
+				unit, err := normalizer.Validate(coveredBy(codec, v))
+				assert.NoError(t, err)
+
+				problem, ok := checkPacking(codec, unit)
+				assert.True(t, ok, "%v: %s", v, problem)
+			}
+		})
+	}
+}
@@ -67,166 +67,132 @@ func QuadRecurse(in, min, max vector.Vector2, levels int) []Quadrant2D {
 	)
 }
 
+// quadCode walks the same path down the quad tree as QuadRecurse without
+// building up a slice, packing the deepest level into the lowest 2 bits.
+func quadCode(in vector.Vector2, levels int) uint32 {
+	minX, minY := -1.0, -1.0
+	maxX, maxY := 1.0, 1.0
+
+	code := uint32(0)
+	for i := 0; i < levels; i++ {
+		midX := ((maxX + minX) / 2)
+		midY := ((maxY + minY) / 2)
+
+		var dir Quadrant2D
+		if in.X() < midX {
+			maxX = midX
+			if in.Y() < midY {
+				maxY = midY
+				dir = BottomLeft
+			} else {
+				minY = midY
+				dir = TopLeft
+			}
+		} else {
+			minX = midX
+			if in.Y() < midY {
+				maxY = midY
+				dir = BottomRight
+			} else {
+				minY = midY
+				dir = TopRight
+			}
+		}
+
+		code = (code << 2) | uint32(dir)
+	}
+	return code
+}
+
+// quadDecode finds the center of the cell described by a code built with
+// quadCode.
+func quadDecode(code uint32, levels int) vector.Vector2 {
+	multiplyer := 0.5
+	x := 0.0
+	y := 0.0
+	for i := levels - 1; i >= 0; i-- {
+		switch Quadrant2D((code >> (i * 2)) & 0b11) {
+		case TopRight:
+			x += multiplyer
+			y += multiplyer
+
+		case TopLeft:
+			x -= multiplyer
+			y += multiplyer
+
+		case BottomLeft:
+			x -= multiplyer
+			y -= multiplyer
+
+		case BottomRight:
+			x += multiplyer
+			y -= multiplyer
+		}
+		multiplyer /= 2.0
+	}
+
+	return vector.NewVector2(x, y)
+}
+
 // Vec2ToByteQuad creates a quadtree of depth 4 and encodes itself into a
 // single byte
 func Vec2ToByteQuad(v vector.Vector2) byte {
-	results := QuadRecurse(
-		v,
-		vector.NewVector2(-1, -1),
-		vector.NewVector2(1, 1),
-		4,
-	)
-	return byte(results[0]) | (byte(results[1]) << 2) | (byte(results[2]) << 4) | (byte(results[3]) << 6)
+	return byte(quadCode(v, 4))
 }
 
 // ByteToVec2 calculates a Vector2 based on the encoded quadtree inside the
 // byte.
 func ByteQuadToVec2(b byte) vector.Vector2 {
-	directions := make([]Quadrant2D, 4)
-	directions[3] = Quadrant2D(b & 0b11)
-	directions[2] = Quadrant2D((b >> 2) & 0b11)
-	directions[1] = Quadrant2D((b >> 4) & 0b11)
-	directions[0] = Quadrant2D((b >> 6) & 0b11)
-	return recalc(directions)
+	return quadDecode(uint32(b), 4)
 }
 
 // Vec2ToTwoByteQuad creates a quadtree of depth 8 and encodes itself in two
 // bytes
 func Vec2ToTwoByteQuad(v vector.Vector2) []byte {
-	results := QuadRecurse(
-		v,
-		vector.NewVector2(-1, -1),
-		vector.NewVector2(1, 1),
-		8,
-	)
+	code := quadCode(v, 8)
 	return []byte{
-		byte(results[0]) | (byte(results[1]) << 2) | (byte(results[2]) << 4) | (byte(results[3]) << 6),
-		byte(results[4]) | (byte(results[5]) << 2) | (byte(results[6]) << 4) | (byte(results[7]) << 6),
+		byte(code),
+		byte(code >> 8),
 	}
 }
 
 // Vec2ToThreeByteQuad creates a quadtree of depth 12 and encodes itself in
 // three bytes
 func Vec2ToThreeByteQuad(v vector.Vector2) []byte {
-	results := QuadRecurse(
-		v,
-		vector.NewVector2(-1, -1),
-		vector.NewVector2(1, 1),
-		12,
-	)
+	code := quadCode(v, 12)
 	return []byte{
-		byte(results[0]) | (byte(results[1]) << 2) | (byte(results[2]) << 4) | (byte(results[3]) << 6),
-		byte(results[4]) | (byte(results[5]) << 2) | (byte(results[6]) << 4) | (byte(results[7]) << 6),
-		byte(results[8]) | (byte(results[9]) << 2) | (byte(results[10]) << 4) | (byte(results[11]) << 6),
+		byte(code),
+		byte(code >> 8),
+		byte(code >> 16),
 	}
 }
 
 // ThreeByteQuadToVec2 calculates a Vector2 based on the encoded quadtree inside
 // the 3 bytes
 func ThreeByteQuadToVec2(b []byte) vector.Vector2 {
-	directions := make([]Quadrant2D, 12)
-
-	directions[11] = Quadrant2D(b[0] & 0b11)
-	directions[10] = Quadrant2D((b[0] >> 2) & 0b11)
-	directions[9] = Quadrant2D((b[0] >> 4) & 0b11)
-	directions[8] = Quadrant2D((b[0] >> 6) & 0b11)
-	directions[7] = Quadrant2D(b[1] & 0b11)
-	directions[6] = Quadrant2D((b[1] >> 2) & 0b11)
-	directions[5] = Quadrant2D((b[1] >> 4) & 0b11)
-	directions[4] = Quadrant2D((b[1] >> 6) & 0b11)
-	directions[3] = Quadrant2D(b[2] & 0b11)
-	directions[2] = Quadrant2D((b[2] >> 2) & 0b11)
-	directions[1] = Quadrant2D((b[2] >> 4) & 0b11)
-	directions[0] = Quadrant2D((b[2] >> 6) & 0b11)
-
-	return recalc(directions)
+	return quadDecode(uint32(b[0])|(uint32(b[1])<<8)|(uint32(b[2])<<16), 12)
 }
 
 // TwoByteQuadToVec2 calculates a Vector2 based on the encoded quadtree inside
 // the 2 bytes
 func TwoByteQuadToVec2(b []byte) vector.Vector2 {
-	directions := make([]Quadrant2D, 8)
-
-	directions[7] = Quadrant2D(b[0] & 0b11)
-	directions[6] = Quadrant2D((b[0] >> 2) & 0b11)
-	directions[5] = Quadrant2D((b[0] >> 4) & 0b11)
-	directions[4] = Quadrant2D((b[0] >> 6) & 0b11)
-	directions[3] = Quadrant2D(b[1] & 0b11)
-	directions[2] = Quadrant2D((b[1] >> 2) & 0b11)
-	directions[1] = Quadrant2D((b[1] >> 4) & 0b11)
-	directions[0] = Quadrant2D((b[1] >> 6) & 0b11)
-
-	return recalc(directions)
+	return quadDecode(uint32(b[0])|(uint32(b[1])<<8), 8)
 }
 
 // Vec2ToFourByteQuad creates a quadtree of depth 16 and encodes itself in
 // 4 bytes
 func Vec2ToFourByteQuad(v vector.Vector2) []byte {
-	results := QuadRecurse(
-		v,
-		vector.NewVector2(-1, -1),
-		vector.NewVector2(1, 1),
-		16,
-	)
+	code := quadCode(v, 16)
 	return []byte{
-		byte(results[0]) | (byte(results[1]) << 2) | (byte(results[2]) << 4) | (byte(results[3]) << 6),
-		byte(results[4]) | (byte(results[5]) << 2) | (byte(results[6]) << 4) | (byte(results[7]) << 6),
-		byte(results[8]) | (byte(results[9]) << 2) | (byte(results[10]) << 4) | (byte(results[11]) << 6),
-		byte(results[12]) | (byte(results[13]) << 2) | (byte(results[14]) << 4) | (byte(results[15]) << 6),
+		byte(code),
+		byte(code >> 8),
+		byte(code >> 16),
+		byte(code >> 24),
 	}
 }
 
 // FourByteQuadToVec2 calculates a Vector2 based on the encoded quadtree inside
 // the 4 bytes
 func FourByteQuadToVec2(b []byte) vector.Vector2 {
-	directions := make([]Quadrant2D, 16)
-
-	directions[15] = Quadrant2D(b[0] & 0b11)
-	directions[14] = Quadrant2D((b[0] >> 2) & 0b11)
-	directions[13] = Quadrant2D((b[0] >> 4) & 0b11)
-	directions[12] = Quadrant2D((b[0] >> 6) & 0b11)
-
-	directions[11] = Quadrant2D(b[1] & 0b11)
-	directions[10] = Quadrant2D((b[1] >> 2) & 0b11)
-	directions[9] = Quadrant2D((b[1] >> 4) & 0b11)
-	directions[8] = Quadrant2D((b[1] >> 6) & 0b11)
-
-	directions[7] = Quadrant2D(b[2] & 0b11)
-	directions[6] = Quadrant2D((b[2] >> 2) & 0b11)
-	directions[5] = Quadrant2D((b[2] >> 4) & 0b11)
-	directions[4] = Quadrant2D((b[2] >> 6) & 0b11)
-
-	directions[3] = Quadrant2D(b[3] & 0b11)
-	directions[2] = Quadrant2D((b[3] >> 2) & 0b11)
-	directions[1] = Quadrant2D((b[3] >> 4) & 0b11)
-	directions[0] = Quadrant2D((b[3] >> 6) & 0b11)
-
-	return recalc(directions)
-}
-
-func recalc(directions []Quadrant2D) vector.Vector2 {
-	multiplyer := 0.5
-	outVec := vector.Vector2Zero()
-	for _, v := range directions {
-		switch v {
-		case TopRight:
-			outVec = outVec.Add(vector.NewVector2(multiplyer, multiplyer))
-			break
-
-		case TopLeft:
-			outVec = outVec.Add(vector.NewVector2(-multiplyer, multiplyer))
-			break
-
-		case BottomLeft:
-			outVec = outVec.Add(vector.NewVector2(-multiplyer, -multiplyer))
-			break
-
-		case BottomRight:
-			outVec = outVec.Add(vector.NewVector2(multiplyer, -multiplyer))
-			break
-		}
-		multiplyer /= 2.0
-	}
-
-	return outVec
+	return quadDecode(uint32(b[0])|(uint32(b[1])<<8)|(uint32(b[2])<<16)|(uint32(b[3])<<24), 16)
 }
@@ -2,6 +2,7 @@ package unitpacking_test
 
 import (
 	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/EliCDavis/vector"
@@ -98,3 +99,27 @@ func TestQuad_2BytesAndBack(t *testing.T) {
 		})
 	}
 }
+
+func quadRecurseBytes(in vector.Vector2, levels int) []byte {
+	results := unitpacking.QuadRecurse(in, vector.NewVector2(-1, -1), vector.NewVector2(1, 1), levels)
+	expected := make([]byte, levels/4)
+	for i, dir := range results {
+		expected[i/4] |= byte(dir) << ((i % 4) * 2)
+	}
+	return expected
+}
+
+func TestQuad_MatchesQuadRecurse(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	inputs := append([]vector.Vector2{}, quadTestVectors...)
+	for i := 0; i < 1000; i++ {
+		inputs = append(inputs, vector.NewVector2((r.Float64()*2)-1, (r.Float64()*2)-1))
+	}
+
+	for _, in := range inputs {
+		assert.Equal(t, quadRecurseBytes(in, 4)[0], unitpacking.Vec2ToByteQuad(in))
+		assert.Equal(t, quadRecurseBytes(in, 8), unitpacking.Vec2ToTwoByteQuad(in))
+		assert.Equal(t, quadRecurseBytes(in, 12), unitpacking.Vec2ToThreeByteQuad(in))
+		assert.Equal(t, quadRecurseBytes(in, 16), unitpacking.Vec2ToFourByteQuad(in))
+	}
+}
@@ -0,0 +1,176 @@
+package unitpacking
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ShaderLanguage is a shading language WriteShaderDecoder can generate code
+// for.
+type ShaderLanguage int
+
+const (
+	// GLSL targets OpenGL 3.0+, OpenGL ES 3.0+ and Vulkan.
+	GLSL ShaderLanguage = iota
+
+	// HLSL targets Direct3D shader model 4.0+.
+	HLSL
+)
+
+func (l ShaderLanguage) String() string {
+	switch l {
+	case GLSL:
+		return "glsl"
+	case HLSL:
+		return "hlsl"
+	}
+	return fmt.Sprintf("ShaderLanguage(%d)", int(l))
+}
+
+// The decoders are written once in GLSL. HLSL only differs by the names of
+// its vector types.
+var hlslTypes = strings.NewReplacer("vec2", "float2", "vec3", "float3")
+
+const shaderHeader = `// Code generated by unitpacking. DO NOT EDIT.
+//
+// Each function takes a code as the unsigned integer produced by the matching
+// Pack*Uint32/Pack*Uint16 function, which is also the packed bytes read as a
+// little endian word.
+`
+
+const shaderFromOctUV = `
+vec3 unitpackingFromOctUV(vec2 e) {
+    vec3 v = vec3(e.x, e.y, 1.0 - abs(e.x) - abs(e.y));
+    if (v.z < 0.0) {
+        vec2 s = vec2(v.x < 0.0 ? -1.0 : 1.0, v.y < 0.0 ? -1.0 : 1.0);
+        v.xy = (1.0 - abs(v.yx)) * s;
+    }
+    return normalize(v);
+}
+`
+
+const shaderQuadDecode = `
+vec2 unitpackingQuadDecode(uint code, int levels) {
+    vec2 p = vec2(0.0, 0.0);
+    float m = 0.5;
+    for (int i = levels - 1; i >= 0; i--) {
+        uint dir = (code >> uint(i * 2)) & 3u;
+        p.x += (dir & 1u) == 1u ? m : -m;
+        p.y += dir < 2u ? m : -m;
+        m *= 0.5;
+    }
+    return p;
+}
+`
+
+type shaderDecoder struct {
+	fromOctUV  bool
+	quadDecode bool
+	body       string
+}
+
+func shaderDecoderFor(spec codegenSpec) shaderDecoder {
+	switch spec.kind {
+	case algKind:
+		return shaderDecoder{body: shaderAlg24}
+
+	case coarseKind:
+		return shaderDecoder{body: shaderCoarse24}
+
+	case octKind:
+		bits := spec.bits()
+		max := 1 << (bits - 1)
+		return shaderDecoder{
+			fromOctUV: true,
+			body:      fmt.Sprintf(shaderOct, spec.name, bits, (1<<bits)-1, max, max-1),
+		}
+	}
+
+	return shaderDecoder{
+		fromOctUV:  true,
+		quadDecode: true,
+		body:       fmt.Sprintf(shaderOctQuad, spec.name, spec.bits()),
+	}
+}
+
+const shaderAlg24 = `
+vec3 unpackAlg24(uint code) {
+    float x = (float((code >> 12u) & 0xFFFu) - 2048.0) / 2047.0;
+    float y = (float((code >> 1u) & 0x7FFu) - 1024.0) / 1023.0;
+    float z = sqrt(max(1.0 - x * x - y * y, 0.0));
+    return vec3(x, y, (code & 1u) == 1u ? z : -z);
+}
+`
+
+const shaderCoarse24 = `
+vec3 unpackCoarse24(uint code) {
+    return vec3(
+        (float(code & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 8u) & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 16u) & 0xFFu) - 128.0) / 127.0
+    );
+}
+`
+
+// Arguments are the name, bits per coordinate, the coordinate mask, the
+// coordinate offset and its max value.
+const shaderOct = `
+vec3 unpack%[1]s(uint code) {
+    float x = clamp((float((code >> %[2]du) & 0x%[3]Xu) - %[4]d.0) / %[5]d.0, -1.0, 1.0);
+    float y = clamp((float(code & 0x%[3]Xu) - %[4]d.0) / %[5]d.0, -1.0, 1.0);
+    return unitpackingFromOctUV(vec2(x, y));
+}
+`
+
+// Arguments are the name and the levels of the quad tree.
+const shaderOctQuad = `
+vec3 unpack%[1]s(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, %[2]d));
+}
+`
+
+// WriteShaderDecoder writes shader functions that decode each of the codecs
+// given, along with any helper functions they share. Decoders are named after
+// the codec, so Oct24 is decoded with unpackOct24. The generated math mirrors
+// the Go Unpack functions, so the GPU decodes the same vectors within float32
+// precision.
+func WriteShaderDecoder(w io.Writer, lang ShaderLanguage, codecs ...Codec) error {
+	if lang != GLSL && lang != HLSL {
+		return fmt.Errorf("unknown shader language: %s", lang)
+	}
+
+	decoders := make([]shaderDecoder, 0, len(codecs))
+	fromOctUV := false
+	quadDecode := false
+	for _, c := range codecs {
+		spec, err := specFor(c)
+		if err != nil {
+			return err
+		}
+		decoder := shaderDecoderFor(spec)
+		decoders = append(decoders, decoder)
+		fromOctUV = fromOctUV || decoder.fromOctUV
+		quadDecode = quadDecode || decoder.quadDecode
+	}
+
+	var sb strings.Builder
+	sb.WriteString(shaderHeader)
+	if fromOctUV {
+		sb.WriteString(shaderFromOctUV)
+	}
+	if quadDecode {
+		sb.WriteString(shaderQuadDecode)
+	}
+	for _, decoder := range decoders {
+		sb.WriteString(decoder.body)
+	}
+
+	out := sb.String()
+	if lang == HLSL {
+		out = hlslTypes.Replace(out)
+	}
+
+	_, err := io.WriteString(w, out)
+	return err
+}
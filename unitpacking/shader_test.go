@@ -0,0 +1,212 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestWriteShaderDecoder_Golden(t *testing.T) {
+	for _, lang := range []unitpacking.ShaderLanguage{unitpacking.GLSL, unitpacking.HLSL} {
+		t.Run(lang.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, unitpacking.WriteShaderDecoder(&buf, lang, unitpacking.Codecs()...))
+
+			golden := filepath.Join("testdata", "shaders", "unitpacking."+lang.String())
+			if *update {
+				assert.NoError(t, os.WriteFile(golden, buf.Bytes(), 0644))
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
+
+func TestWriteShaderDecoder_OnlyNeededHelpers(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, unitpacking.WriteShaderDecoder(&buf, unitpacking.GLSL, unitpacking.Coarse24))
+	assert.NotContains(t, buf.String(), "unitpackingFromOctUV")
+	assert.Contains(t, buf.String(), "vec3 unpackCoarse24(uint code)")
+
+	buf.Reset()
+	assert.NoError(t, unitpacking.WriteShaderDecoder(&buf, unitpacking.HLSL, unitpacking.Oct24))
+	assert.Contains(t, buf.String(), "float3 unitpackingFromOctUV(float2 e)")
+	assert.NotContains(t, buf.String(), "unitpackingQuadDecode")
+	assert.NotContains(t, buf.String(), "vec")
+}
+
+func TestWriteShaderDecoder_Unsupported(t *testing.T) {
+	magnitude, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+
+	err = unitpacking.WriteShaderDecoder(&bytes.Buffer{}, unitpacking.GLSL, magnitude)
+	assert.True(t, errors.Is(err, unitpacking.ErrUnsupportedCodec))
+
+	err = unitpacking.WriteShaderDecoder(&bytes.Buffer{}, unitpacking.ShaderLanguage(7), unitpacking.Oct24)
+	assert.Error(t, err)
+}
+
+// The functions below are a float32 port of the generated shader code, line
+// for line. Every operation is rounded back to float32, the way a GPU
+// evaluates it.
+
+func f32Clamp(v, min, max float32) float32 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func f32Abs(v float32) float32 {
+	return float32(math.Abs(float64(v)))
+}
+
+func f32Sqrt(v float32) float32 {
+	return float32(math.Sqrt(float64(v)))
+}
+
+func f32Sign(v float32) float32 {
+	if v < 0 {
+		return -1
+	}
+	return 1
+}
+
+func shaderFromOctUV(ex, ey float32) [3]float32 {
+	x, y := ex, ey
+	z := float32(float32(1.0-f32Abs(ex)) - f32Abs(ey))
+	if z < 0 {
+		x, y = float32(1.0-f32Abs(ey))*f32Sign(ex), float32(1.0-f32Abs(ex))*f32Sign(ey)
+	}
+
+	dot := float32(float32(float32(x*x)+float32(y*y)) + float32(z*z))
+	inv := float32(1.0 / f32Sqrt(dot))
+	return [3]float32{float32(x * inv), float32(y * inv), float32(z * inv)}
+}
+
+func shaderQuadDecode(code uint32, levels int) (float32, float32) {
+	var x, y float32
+	m := float32(0.5)
+	for i := levels - 1; i >= 0; i-- {
+		dir := (code >> uint(i*2)) & 3
+		if dir&1 == 1 {
+			x += m
+		} else {
+			x -= m
+		}
+		if dir < 2 {
+			y += m
+		} else {
+			y -= m
+		}
+		m *= 0.5
+	}
+	return x, y
+}
+
+func shaderUnpackOct(bits int) func(code uint32) [3]float32 {
+	mask := uint32(1)<<bits - 1
+	half := float32(int(1) << (bits - 1))
+	return func(code uint32) [3]float32 {
+		x := f32Clamp(float32(float32((code>>bits)&mask)-half)/(half-1), -1, 1)
+		y := f32Clamp(float32(float32(code&mask)-half)/(half-1), -1, 1)
+		return shaderFromOctUV(x, y)
+	}
+}
+
+func shaderUnpackOctQuad(levels int) func(code uint32) [3]float32 {
+	return func(code uint32) [3]float32 {
+		return shaderFromOctUV(shaderQuadDecode(code, levels))
+	}
+}
+
+func shaderUnpackAlg24(code uint32) [3]float32 {
+	x := float32(float32((code>>12)&0xFFF)-2048) / 2047
+	y := float32(float32((code>>1)&0x7FF)-1024) / 1023
+	z := f32Sqrt(float32(math.Max(float64(float32(float32(1.0-float32(x*x))-float32(y*y))), 0)))
+	if code&1 != 1 {
+		z = -z
+	}
+	return [3]float32{x, y, z}
+}
+
+func shaderUnpackCoarse24(code uint32) [3]float32 {
+	return [3]float32{
+		float32(float32(code&0xFF)-128) / 127,
+		float32(float32((code>>8)&0xFF)-128) / 127,
+		float32(float32((code>>16)&0xFF)-128) / 127,
+	}
+}
+
+func TestWriteShaderDecoder_Float32Emulation(t *testing.T) {
+	tests := map[string]struct {
+		decode func(code uint32) [3]float32
+
+		// How far the float32 decode may stray from the float64 one. Alg24
+		// recovers Z with a square root, which magnifies the float32 rounding
+		// of 1 - x*x - y*y when Z is close to 0.
+		tolerance float64
+	}{
+		"alg24":     {shaderUnpackAlg24, 1e-3},
+		"coarse24":  {shaderUnpackCoarse24, 1e-6},
+		"oct16":     {shaderUnpackOct(8), 1e-6},
+		"oct24":     {shaderUnpackOct(12), 1e-6},
+		"oct32":     {shaderUnpackOct(16), 1e-6},
+		"octquad16": {shaderUnpackOctQuad(8), 1e-6},
+		"octquad24": {shaderUnpackOctQuad(12), 1e-6},
+		"octquad32": {shaderUnpackOctQuad(16), 1e-6},
+	}
+
+	r := rand.New(rand.NewSource(34))
+	for name, tc := range tests {
+		codec, ok := unitpacking.CodecByName(name)
+		assert.True(t, ok)
+
+		t.Run(name, func(t *testing.T) {
+			codes := make([]uint32, 0)
+			if codec.Size() == 2 {
+				for i := 0; i < 1<<16; i++ {
+					codes = append(codes, uint32(i))
+				}
+			} else {
+				for i := 0; i < 1<<16; i++ {
+					codes = append(codes, r.Uint32()>>(32-codec.Size()*8))
+				}
+				for _, tc := range append(append([]vector.Vector3{}, testVectors...), edgeCaseVectors...) {
+					codes = append(codes, littleEndianWord(codec.Pack(tc.Normalized())))
+				}
+			}
+
+			b := make([]byte, codec.Size())
+			for _, code := range codes {
+				for i := range b {
+					b[i] = byte(code >> (i * 8))
+				}
+				expected := codec.Unpack(b)
+				got := tc.decode(code)
+
+				if math.Abs(expected.X()-float64(got[0])) > tc.tolerance ||
+					math.Abs(expected.Y()-float64(got[1])) > tc.tolerance ||
+					math.Abs(expected.Z()-float64(got[2])) > tc.tolerance {
+					t.Fatalf("code %x decoded to %v in Go but %v in float32", code, expected, got)
+				}
+			}
+		})
+	}
+}
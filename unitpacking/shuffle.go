@@ -0,0 +1,95 @@
+package unitpacking
+
+// ByteShuffle regroups a buffer of packed vectors so that the first byte of
+// every vector comes first, followed by the second byte of every vector, and
+// so on. Bytes belonging to the same position tend to be similar to one
+// another, which makes the shuffled buffer easier for general purpose
+// compressors to shrink. Width is the number of bytes per vector. Any
+// trailing bytes that don't make up a whole vector are copied as is.
+func ByteShuffle(data []byte, width int) []byte {
+	out := make([]byte, len(data))
+	if width <= 1 {
+		copy(out, data)
+		return out
+	}
+
+	count := len(data) / width
+	for i := 0; i < count; i++ {
+		for j := 0; j < width; j++ {
+			out[(j*count)+i] = data[(i*width)+j]
+		}
+	}
+	copy(out[count*width:], data[count*width:])
+	return out
+}
+
+// ByteUnshuffle reverses ByteShuffle, restoring the original order of the
+// packed vectors.
+func ByteUnshuffle(data []byte, width int) []byte {
+	out := make([]byte, len(data))
+	if width <= 1 {
+		copy(out, data)
+		return out
+	}
+
+	count := len(data) / width
+	for i := 0; i < count; i++ {
+		for j := 0; j < width; j++ {
+			out[(i*width)+j] = data[(j*count)+i]
+		}
+	}
+	copy(out[count*width:], data[count*width:])
+	return out
+}
+
+// BitShuffle regroups a buffer of packed vectors into bit planes, where the
+// first plane holds the lowest bit of the first byte of every vector, the
+// second plane the next bit, and so on. Only whole groups of 8 vectors are
+// shuffled so each plane fills whole bytes, anything left over is copied as
+// is. Width is the number of bytes per vector.
+func BitShuffle(data []byte, width int) []byte {
+	out := make([]byte, len(data))
+	if width <= 0 {
+		copy(out, data)
+		return out
+	}
+
+	count := ((len(data) / width) / 8) * 8
+	planeSize := count / 8
+	for i := 0; i < count; i++ {
+		for j := 0; j < width; j++ {
+			b := data[(i*width)+j]
+			for bit := 0; bit < 8; bit++ {
+				plane := (j * 8) + bit
+				out[(plane*planeSize)+(i/8)] |= ((b >> bit) & 1) << (i % 8)
+			}
+		}
+	}
+	copy(out[count*width:], data[count*width:])
+	return out
+}
+
+// BitUnshuffle reverses BitShuffle, restoring the original order of the
+// packed vectors.
+func BitUnshuffle(data []byte, width int) []byte {
+	out := make([]byte, len(data))
+	if width <= 0 {
+		copy(out, data)
+		return out
+	}
+
+	count := ((len(data) / width) / 8) * 8
+	planeSize := count / 8
+	for i := 0; i < count; i++ {
+		for j := 0; j < width; j++ {
+			b := byte(0)
+			for bit := 0; bit < 8; bit++ {
+				plane := (j * 8) + bit
+				b |= ((data[(plane*planeSize)+(i/8)] >> (i % 8)) & 1) << bit
+			}
+			out[(i*width)+j] = b
+		}
+	}
+	copy(out[count*width:], data[count*width:])
+	return out
+}
@@ -0,0 +1,35 @@
+package unitpacking_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteShuffle_GroupsBytePositions(t *testing.T) {
+	shuffled := unitpacking.ByteShuffle([]byte{1, 2, 3, 4, 5, 6, 7}, 3)
+	assert.Equal(t, []byte{1, 4, 2, 5, 3, 6, 7}, shuffled)
+}
+
+func TestBitShuffle_GroupsBitPlanes(t *testing.T) {
+	shuffled := unitpacking.BitShuffle([]byte{1, 1, 1, 1, 1, 1, 1, 0, 2}, 1)
+	assert.Equal(t, []byte{0b01111111, 0, 0, 0, 0, 0, 0, 0, 2}, shuffled)
+}
+
+func TestShuffle_RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for width := 1; width <= 4; width++ {
+		for _, size := range []int{0, 1, 7, 24, 100, 1001} {
+			data := make([]byte, size)
+			r.Read(data)
+
+			t.Run(fmt.Sprintf("width %d size %d", width, size), func(t *testing.T) {
+				assert.Equal(t, data, unitpacking.ByteUnshuffle(unitpacking.ByteShuffle(data, width), width))
+				assert.Equal(t, data, unitpacking.BitUnshuffle(unitpacking.BitShuffle(data, width), width))
+			})
+		}
+	}
+}
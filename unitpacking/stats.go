@@ -0,0 +1,112 @@
+package unitpacking
+
+import (
+	"math"
+	"sort"
+
+	"github.com/EliCDavis/vector"
+)
+
+// ErrorStats collects the angular error, in degrees, between vectors and the
+// vectors they decoded back to, and summarizes them. Every error is kept so
+// percentiles are exact.
+type ErrorStats struct {
+	// Thresholds are the errors, in degrees, to report the fraction of
+	// errors above.
+	Thresholds []float64
+
+	errs       []float64
+	sorted     bool
+	sum        float64
+	sumSquared float64
+}
+
+// ErrorSummary describes a set of angular errors. Everything is in degrees
+// except the fractions.
+type ErrorSummary struct {
+	Count int
+	Mean  float64
+	RMS   float64
+	Max   float64
+	P50   float64
+	P95   float64
+	P99   float64
+	P999  float64
+
+	// OverThreshold holds the fraction of errors greater than each of the
+	// stats' thresholds, in the same order.
+	OverThreshold []float64
+}
+
+// Add records the angle between the original vector and the one it decoded
+// back to. Vectors without a direction, like NaN ones, count as the worst
+// possible error of 180 degrees.
+func (s *ErrorStats) Add(original, decoded vector.Vector3) {
+	s.AddDegrees(AngularError(original, decoded) * 180 / math.Pi)
+}
+
+// AddDegrees records an error that has already been measured.
+func (s *ErrorStats) AddDegrees(degrees float64) {
+	if math.IsNaN(degrees) {
+		degrees = 180
+	}
+	s.errs = append(s.errs, degrees)
+	s.sorted = false
+	s.sum += degrees
+	s.sumSquared += degrees * degrees
+}
+
+// Count is how many errors have been recorded.
+func (s *ErrorStats) Count() int {
+	return len(s.errs)
+}
+
+func (s *ErrorStats) sort() {
+	if !s.sorted {
+		sort.Float64s(s.errs)
+		s.sorted = true
+	}
+}
+
+// Percentile returns the nearest-rank percentile: the smallest recorded error
+// that at least p of the errors are at or below, for p between 0 and 1. It
+// returns 0 when nothing has been recorded.
+func (s *ErrorStats) Percentile(p float64) float64 {
+	if len(s.errs) == 0 {
+		return 0
+	}
+	s.sort()
+	rank := int(math.Ceil(Clamp(p, 0, 1) * float64(len(s.errs))))
+	if rank < 1 {
+		rank = 1
+	}
+	return s.errs[rank-1]
+}
+
+// Summary summarizes every error recorded so far.
+func (s *ErrorStats) Summary() ErrorSummary {
+	summary := ErrorSummary{
+		Count:         len(s.errs),
+		OverThreshold: make([]float64, len(s.Thresholds)),
+	}
+	if len(s.errs) == 0 {
+		return summary
+	}
+
+	count := float64(len(s.errs))
+	summary.Mean = s.sum / count
+	summary.RMS = math.Sqrt(s.sumSquared / count)
+	summary.Max = s.Percentile(1)
+	summary.P50 = s.Percentile(0.5)
+	summary.P95 = s.Percentile(0.95)
+	summary.P99 = s.Percentile(0.99)
+	summary.P999 = s.Percentile(0.999)
+
+	for i, threshold := range s.Thresholds {
+		// Errors are sorted, so everything past the first one above the
+		// threshold is above it too
+		above := len(s.errs) - sort.Search(len(s.errs), func(j int) bool { return s.errs[j] > threshold })
+		summary.OverThreshold[i] = float64(above) / count
+	}
+	return summary
+}
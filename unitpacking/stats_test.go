@@ -0,0 +1,56 @@
+package unitpacking_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStats_Summary(t *testing.T) {
+	stats := unitpacking.ErrorStats{Thresholds: []float64{0.5, 50, 100}}
+	for i := 100; i >= 1; i-- {
+		stats.AddDegrees(float64(i))
+	}
+	assert.Equal(t, 100, stats.Count())
+
+	summary := stats.Summary()
+	assert.Equal(t, 100, summary.Count)
+	assert.InDelta(t, 50.5, summary.Mean, 1e-12)
+	assert.InDelta(t, math.Sqrt(101*201/6.0), summary.RMS, 1e-12)
+	assert.Equal(t, 100.0, summary.Max)
+	assert.Equal(t, 50.0, summary.P50)
+	assert.Equal(t, 95.0, summary.P95)
+	assert.Equal(t, 99.0, summary.P99)
+	assert.Equal(t, 100.0, summary.P999)
+	assert.Equal(t, []float64{1, 0.5, 0}, summary.OverThreshold)
+
+	// Nearest rank, so the 1st percentile of 100 errors is the smallest
+	assert.Equal(t, 1.0, stats.Percentile(0.01))
+	assert.Equal(t, 2.0, stats.Percentile(0.015))
+
+	// Adding more after summarizing is fine
+	stats.AddDegrees(1000)
+	assert.Equal(t, 1000.0, stats.Summary().Max)
+	assert.Equal(t, 1.0, stats.Percentile(0))
+}
+
+func TestErrorStats_Add(t *testing.T) {
+	stats := unitpacking.ErrorStats{}
+	stats.Add(vector.NewVector3(1, 0, 0), vector.NewVector3(0, 1, 0))
+	stats.Add(vector.NewVector3(1, 0, 0), vector.NewVector3(1, 0, 0))
+	stats.Add(vector.NewVector3(1, 0, 0), vector.NewVector3(math.NaN(), 0, 0))
+
+	summary := stats.Summary()
+	assert.InDelta(t, 90, summary.P50, 1e-12)
+	assert.Equal(t, 180.0, summary.Max)
+	assert.InDelta(t, 90, summary.Mean, 1e-12)
+}
+
+func TestErrorStats_Empty(t *testing.T) {
+	stats := unitpacking.ErrorStats{Thresholds: []float64{1}}
+	assert.Equal(t, unitpacking.ErrorSummary{OverThreshold: []float64{0}}, stats.Summary())
+	assert.Equal(t, 0.0, stats.Percentile(0.5))
+}
@@ -0,0 +1,82 @@
+package unitpacking
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/EliCDavis/vector"
+)
+
+// Encoder writes packed vectors to an output stream. Writes are buffered, so
+// Flush must be called once all vectors have been encoded.
+type Encoder struct {
+	codec Codec
+	out   *bufio.Writer
+	err   error
+}
+
+// NewEncoder returns a new encoder that packs vectors with the given codec
+// and writes them to w.
+func NewEncoder(w io.Writer, codec Codec) *Encoder {
+	return &Encoder{
+		codec: codec,
+		out:   bufio.NewWriter(w),
+	}
+}
+
+// Encode packs a single vector and writes it to the stream. Once a write has
+// failed, every following call returns that same error.
+func (e *Encoder) Encode(v vector.Vector3) error {
+	if e.err != nil {
+		return e.err
+	}
+	_, e.err = e.out.Write(e.codec.Pack(v))
+	return e.err
+}
+
+// EncodeSlice packs and writes every vector in the slice, stopping at the
+// first error encountered.
+func (e *Encoder) EncodeSlice(vs []vector.Vector3) error {
+	for _, v := range vs {
+		if err := e.Encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.out.Flush()
+	return e.err
+}
+
+// Decoder reads packed vectors from an input stream.
+type Decoder struct {
+	codec Codec
+	in    *bufio.Reader
+	buf   []byte
+}
+
+// NewDecoder returns a new decoder that reads from r and unpacks vectors with
+// the given codec.
+func NewDecoder(r io.Reader, codec Codec) *Decoder {
+	return &Decoder{
+		codec: codec,
+		in:    bufio.NewReader(r),
+		buf:   make([]byte, codec.Size()),
+	}
+}
+
+// Decode reads and unpacks the next vector from the stream. Returns io.EOF
+// when the stream ends cleanly between vectors, and io.ErrUnexpectedEOF when
+// it ends partway through one.
+func (d *Decoder) Decode() (vector.Vector3, error) {
+	if _, err := io.ReadFull(d.in, d.buf); err != nil {
+		return vector.Vector3Zero(), err
+	}
+	return d.codec.Unpack(d.buf), nil
+}
@@ -0,0 +1,72 @@
+package unitpacking_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestEncoderDecoder_RoundTrip(t *testing.T) {
+	for _, codec := range unitpacking.Codecs() {
+		t.Run(codec.Name(), func(t *testing.T) {
+			out := bytes.Buffer{}
+			encoder := unitpacking.NewEncoder(&out, codec)
+			for _, tc := range testVectors {
+				assert.NoError(t, encoder.Encode(tc.Normalized()))
+			}
+			assert.NoError(t, encoder.Flush())
+			assert.Equal(t, len(testVectors)*codec.Size(), out.Len())
+
+			decoder := unitpacking.NewDecoder(&out, codec)
+			for _, tc := range testVectors {
+				unpacked, err := decoder.Decode()
+				assert.NoError(t, err)
+				assert.Equal(t, codec.Unpack(codec.Pack(tc.Normalized())), unpacked)
+			}
+
+			_, err := decoder.Decode()
+			assert.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestEncoder_EncodeSlice(t *testing.T) {
+	out := bytes.Buffer{}
+	encoder := unitpacking.NewEncoder(&out, unitpacking.Oct24)
+	assert.NoError(t, encoder.EncodeSlice(testVectors))
+	assert.NoError(t, encoder.Flush())
+
+	expected := bytes.Buffer{}
+	for _, tc := range testVectors {
+		expected.Write(unitpacking.PackOct24(tc))
+	}
+	assert.Equal(t, expected.Bytes(), out.Bytes())
+}
+
+func TestEncoder_ReportsWriteErrors(t *testing.T) {
+	encoder := unitpacking.NewEncoder(failingWriter{}, unitpacking.Oct32)
+	assert.NoError(t, encoder.Encode(testVectors[0].Normalized()))
+	assert.Error(t, encoder.Flush())
+	assert.Error(t, encoder.Encode(testVectors[1].Normalized()))
+}
+
+func TestDecoder_UnexpectedEOF(t *testing.T) {
+	packed := append(unitpacking.PackOct24(testVectors[1]), 0x01)
+
+	decoder := unitpacking.NewDecoder(bytes.NewReader(packed), unitpacking.Oct24)
+	_, err := decoder.Decode()
+	assert.NoError(t, err)
+
+	_, err = decoder.Decode()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
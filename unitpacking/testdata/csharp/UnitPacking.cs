@@ -0,0 +1,519 @@
+// Code generated by unitpacking. DO NOT EDIT.
+
+using System;
+
+namespace Recolude
+{
+    /// <summary>
+    /// A vector of doubles, the precision every codec packs from and unpacks
+    /// to.
+    /// </summary>
+    public struct Vector3d
+    {
+        public double X;
+        public double Y;
+        public double Z;
+
+        public Vector3d(double x, double y, double z)
+        {
+            X = x;
+            Y = y;
+            Z = z;
+        }
+
+#if UNITY_5_3_OR_NEWER
+        public static implicit operator Vector3d(UnityEngine.Vector3 v)
+        {
+            return new Vector3d(v.x, v.y, v.z);
+        }
+
+        public static explicit operator UnityEngine.Vector3(Vector3d v)
+        {
+            return new UnityEngine.Vector3((float)v.X, (float)v.Y, (float)v.Z);
+        }
+#endif
+
+        public override string ToString()
+        {
+            return "(" + X.ToString("R") + ", " + Y.ToString("R") + ", " + Z.ToString("R") + ")";
+        }
+    }
+
+    /// <summary>
+    /// Packs and unpacks unit vectors exactly like the Go unitpacking library.
+    /// Codes are written little endian, and the word forms match the Go
+    /// Pack*Uint32/Pack*Uint16 functions.
+    /// </summary>
+    public static class UnitPacking
+    {
+        // Rounds half away from zero like Go's math.Round. Math.Round defaults
+        // to rounding half to even, and MidpointRounding.AwayFromZero isn't
+        // exact on every runtime.
+        private static double Round(double x)
+        {
+            double t = Math.Truncate(x);
+            if (Math.Abs(x - t) >= 0.5)
+            {
+                t += Math.Sign(x);
+            }
+            return t;
+        }
+
+        private static double Clamp(double num, double min, double max)
+        {
+            if (num < min)
+            {
+                return min;
+            }
+            if (num > max)
+            {
+                return max;
+            }
+            return num;
+        }
+
+        private static double ClampUnit(double num)
+        {
+            return double.IsNaN(num) ? 0 : Clamp(num, -1.0, 1.0);
+        }
+
+        private static uint ReadWord(byte[] b, int offset, int size)
+        {
+            uint word = 0;
+            for (int i = 0; i < size; i++)
+            {
+                word |= (uint)b[offset + i] << (i * 8);
+            }
+            return word;
+        }
+
+        private static byte[] WriteWord(uint word, int size)
+        {
+            byte[] b = new byte[size];
+            for (int i = 0; i < size; i++)
+            {
+                b[i] = (byte)(word >> (i * 8));
+            }
+            return b;
+        }
+
+        private static double SignNotZero(double v)
+        {
+            return v < 0.0 ? -1.0 : 1.0;
+        }
+
+        private static void MapToOctUV(Vector3d v, out double u, out double w)
+        {
+            double inv = 1.0 / (Math.Abs(v.X) + Math.Abs(v.Y) + Math.Abs(v.Z));
+            double px = v.X * inv;
+            double py = v.Y * inv;
+            if (v.Z > 0)
+            {
+                u = px;
+                w = py;
+                return;
+            }
+
+            u = SignNotZero(px) * (1.0 - Math.Abs(py));
+            w = SignNotZero(py) * (1.0 - Math.Abs(px));
+        }
+
+        private static Vector3d FromOctUV(double u, double w)
+        {
+            double x = u;
+            double y = w;
+            double z = 1.0 - Math.Abs(u) - Math.Abs(w);
+            if (z < 0)
+            {
+                x = (1.0 - Math.Abs(w)) * SignNotZero(u);
+                y = (1.0 - Math.Abs(u)) * SignNotZero(w);
+            }
+
+            double inv = 1.0 / Math.Sqrt((x * x) + (y * y) + (z * z));
+            return new Vector3d(x * inv, y * inv, z * inv);
+        }
+
+        private static double Dot(Vector3d a, Vector3d b)
+        {
+            return (a.X * b.X) + (a.Y * b.Y) + (a.Z * b.Z);
+        }
+
+        private static void MapToOctUVPrecise(Vector3d v, int n, out double u, out double w)
+        {
+            double s;
+            double t;
+            MapToOctUV(v, out s, out t);
+            if (double.IsNaN(s) || double.IsNaN(t))
+            {
+                u = 0;
+                w = 0;
+                return;
+            }
+
+            double m = (double)(1 << ((n / 2) - 1)) - 1.0;
+            double baseX = Math.Floor(Clamp(s, -1.0, 1.0) * m);
+            double baseY = Math.Floor(Clamp(t, -1.0, 1.0) * m);
+
+            u = baseX / m;
+            w = baseY / m;
+            double highestCosine = Dot(FromOctUV(u, w), v);
+
+            for (double i = 0.0; i <= 1; i++)
+            {
+                for (double j = 0.0; j <= 1; j++)
+                {
+                    if (i == 0 && j == 0)
+                    {
+                        continue;
+                    }
+                    if (baseX + i > m || baseY + j > m)
+                    {
+                        continue;
+                    }
+
+                    double candidateU = (baseX + i) / m;
+                    double candidateW = (baseY + j) / m;
+                    double cosine = Dot(FromOctUV(candidateU, candidateW), v);
+                    if (cosine > highestCosine)
+                    {
+                        u = candidateU;
+                        w = candidateW;
+                        highestCosine = cosine;
+                    }
+                }
+            }
+
+            // Directions on the seams of the lower hemisphere always get the
+            // same code.
+            if (Math.Abs(u) == 1)
+            {
+                w = Math.Abs(w);
+            }
+            if (Math.Abs(w) == 1)
+            {
+                u = Math.Abs(u);
+            }
+        }
+
+        private static uint QuadCode(double u, double w, int levels)
+        {
+            double minX = -1.0;
+            double minY = -1.0;
+            double maxX = 1.0;
+            double maxY = 1.0;
+
+            uint code = 0;
+            for (int i = 0; i < levels; i++)
+            {
+                double midX = (maxX + minX) / 2;
+                double midY = (maxY + minY) / 2;
+
+                uint dir;
+                if (u < midX)
+                {
+                    maxX = midX;
+                    if (w < midY)
+                    {
+                        maxY = midY;
+                        dir = 2;
+                    }
+                    else
+                    {
+                        minY = midY;
+                        dir = 0;
+                    }
+                }
+                else
+                {
+                    minX = midX;
+                    if (w < midY)
+                    {
+                        maxY = midY;
+                        dir = 3;
+                    }
+                    else
+                    {
+                        minY = midY;
+                        dir = 1;
+                    }
+                }
+
+                code = (code << 2) | dir;
+            }
+            return code;
+        }
+
+        private static void QuadDecode(uint code, int levels, out double u, out double w)
+        {
+            double multiplyer = 0.5;
+            u = 0.0;
+            w = 0.0;
+            for (int i = levels - 1; i >= 0; i--)
+            {
+                uint dir = (code >> (i * 2)) & 3;
+                u += (dir & 1) == 1 ? multiplyer : -multiplyer;
+                w += dir < 2 ? multiplyer : -multiplyer;
+                multiplyer /= 2.0;
+            }
+        }
+
+        // Every level of the quad tree going to the bottom left is the null
+        // code, and is moved one cell to the right.
+        private static uint AvoidOctQuadNull(uint code, int levels)
+        {
+            uint nullCode = 0;
+            for (int i = 0; i < levels; i++)
+            {
+                nullCode = (nullCode << 2) | 2;
+            }
+            return code == nullCode ? code | 3 : code;
+        }
+
+        public static byte[] PackAlg24(Vector3d v)
+        {
+            return WriteWord(PackAlg24Uint32(v), 3);
+        }
+
+        public static uint PackAlg24Uint32(Vector3d v)
+        {
+            uint x = (uint)(Round(ClampUnit(v.X) * 2047) + 2048);
+            uint y = (uint)(Round(ClampUnit(v.Y) * 1023) + 1024);
+
+            uint zPositive = 0;
+            if (v.Z >= 0.0 || AlgZ((x - 2048.0) / 2047.0, (y - 1024.0) / 1023.0) == 0)
+            {
+                zPositive = 1;
+            }
+
+            return (x << 12) | (y << 1) | zPositive;
+        }
+
+        public static Vector3d UnpackAlg24(byte[] b, int offset = 0)
+        {
+            return UnpackAlg24Uint32(ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackAlg24Uint32(uint code)
+        {
+            double x = (((code >> 12) & 0xFFF) - 2048.0) / 2047.0;
+            double y = (((code >> 1) & 0x7FF) - 1024.0) / 1023.0;
+            double z = AlgZ(x, y);
+            if ((code & 1) != 1)
+            {
+                z *= -1;
+            }
+            return new Vector3d(x, y, z);
+        }
+
+        private static double AlgZ(double x, double y)
+        {
+            double z = Math.Sqrt(1.0 - (x * x) - (y * y));
+            return double.IsNaN(z) ? 0 : z;
+        }
+
+        public static byte[] PackCoarse24(Vector3d v)
+        {
+            return WriteWord(PackCoarse24Uint32(v), 3);
+        }
+
+        public static uint PackCoarse24Uint32(Vector3d v)
+        {
+            return (uint)NormalToByte(v.X) | ((uint)NormalToByte(v.Y) << 8) | ((uint)NormalToByte(v.Z) << 16);
+        }
+
+        public static Vector3d UnpackCoarse24(byte[] b, int offset = 0)
+        {
+            return UnpackCoarse24Uint32(ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackCoarse24Uint32(uint code)
+        {
+            return new Vector3d(
+                ByteToNormal((byte)code),
+                ByteToNormal((byte)(code >> 8)),
+                ByteToNormal((byte)(code >> 16))
+            );
+        }
+
+        private static byte NormalToByte(double n)
+        {
+            return (byte)(Round(ClampUnit(n) * 127.0) + 128);
+        }
+
+        private static double ByteToNormal(byte b)
+        {
+            return (b - 128.0) / 127.0;
+        }
+
+        public static byte[] PackOct16(Vector3d v)
+        {
+            return WriteWord(PackOct16Uint16(v), 2);
+        }
+
+        public static ushort PackOct16Uint16(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUVPrecise(v, 16, out u, out w);
+
+            uint x = (uint)(Round(u * 127) + 128);
+            uint y = (uint)(Round(w * 127) + 128);
+            return (ushort)((x << 8) | y);
+        }
+
+        public static Vector3d UnpackOct16(byte[] b, int offset = 0)
+        {
+            return UnpackOct16Uint16((ushort)ReadWord(b, offset, 2));
+        }
+
+        public static Vector3d UnpackOct16Uint16(ushort code)
+        {
+            uint rawX = ((uint)code >> 8) & 0xFF;
+            uint rawY = (uint)code & 0xFF;
+
+            double x = Clamp((rawX - 128.0) / 127.0, -1.0, 1.0);
+            double y = Clamp((rawY - 128.0) / 127.0, -1.0, 1.0);
+            return FromOctUV(x, y);
+        }
+
+        public static byte[] PackOct24(Vector3d v)
+        {
+            return WriteWord(PackOct24Uint32(v), 3);
+        }
+
+        public static uint PackOct24Uint32(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUVPrecise(v, 24, out u, out w);
+
+            uint x = (uint)(Round(u * 2047) + 2048);
+            uint y = (uint)(Round(w * 2047) + 2048);
+            return (uint)((x << 12) | y);
+        }
+
+        public static Vector3d UnpackOct24(byte[] b, int offset = 0)
+        {
+            return UnpackOct24Uint32((uint)ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackOct24Uint32(uint code)
+        {
+            uint rawX = ((uint)code >> 12) & 0xFFF;
+            uint rawY = (uint)code & 0xFFF;
+
+            double x = Clamp((rawX - 2048.0) / 2047.0, -1.0, 1.0);
+            double y = Clamp((rawY - 2048.0) / 2047.0, -1.0, 1.0);
+            return FromOctUV(x, y);
+        }
+
+        public static byte[] PackOct32(Vector3d v)
+        {
+            return WriteWord(PackOct32Uint32(v), 4);
+        }
+
+        public static uint PackOct32Uint32(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUVPrecise(v, 32, out u, out w);
+
+            uint x = (uint)(Round(u * 32767) + 32768);
+            uint y = (uint)(Round(w * 32767) + 32768);
+            return (uint)((x << 16) | y);
+        }
+
+        public static Vector3d UnpackOct32(byte[] b, int offset = 0)
+        {
+            return UnpackOct32Uint32((uint)ReadWord(b, offset, 4));
+        }
+
+        public static Vector3d UnpackOct32Uint32(uint code)
+        {
+            uint rawX = ((uint)code >> 16) & 0xFFFF;
+            uint rawY = (uint)code & 0xFFFF;
+
+            double x = Clamp((rawX - 32768.0) / 32767.0, -1.0, 1.0);
+            double y = Clamp((rawY - 32768.0) / 32767.0, -1.0, 1.0);
+            return FromOctUV(x, y);
+        }
+
+        public static byte[] PackOctQuad16(Vector3d v)
+        {
+            return WriteWord(PackOctQuad16Uint16(v), 2);
+        }
+
+        public static ushort PackOctQuad16Uint16(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUV(v, out u, out w);
+            return (ushort)AvoidOctQuadNull(QuadCode(u, w, 8), 8);
+        }
+
+        public static Vector3d UnpackOctQuad16(byte[] b, int offset = 0)
+        {
+            return UnpackOctQuad16Uint16((ushort)ReadWord(b, offset, 2));
+        }
+
+        public static Vector3d UnpackOctQuad16Uint16(ushort code)
+        {
+            double u;
+            double w;
+            QuadDecode((uint)code & 0xFFFF, 8, out u, out w);
+            return FromOctUV(u, w);
+        }
+
+        public static byte[] PackOctQuad24(Vector3d v)
+        {
+            return WriteWord(PackOctQuad24Uint32(v), 3);
+        }
+
+        public static uint PackOctQuad24Uint32(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUV(v, out u, out w);
+            return (uint)AvoidOctQuadNull(QuadCode(u, w, 12), 12);
+        }
+
+        public static Vector3d UnpackOctQuad24(byte[] b, int offset = 0)
+        {
+            return UnpackOctQuad24Uint32((uint)ReadWord(b, offset, 3));
+        }
+
+        public static Vector3d UnpackOctQuad24Uint32(uint code)
+        {
+            double u;
+            double w;
+            QuadDecode((uint)code & 0xFFFFFF, 12, out u, out w);
+            return FromOctUV(u, w);
+        }
+
+        public static byte[] PackOctQuad32(Vector3d v)
+        {
+            return WriteWord(PackOctQuad32Uint32(v), 4);
+        }
+
+        public static uint PackOctQuad32Uint32(Vector3d v)
+        {
+            double u;
+            double w;
+            MapToOctUV(v, out u, out w);
+            return (uint)AvoidOctQuadNull(QuadCode(u, w, 16), 16);
+        }
+
+        public static Vector3d UnpackOctQuad32(byte[] b, int offset = 0)
+        {
+            return UnpackOctQuad32Uint32((uint)ReadWord(b, offset, 4));
+        }
+
+        public static Vector3d UnpackOctQuad32Uint32(uint code)
+        {
+            double u;
+            double w;
+            QuadDecode((uint)code & 0xFFFFFFFF, 16, out u, out w);
+            return FromOctUV(u, w);
+        }
+    }
+}
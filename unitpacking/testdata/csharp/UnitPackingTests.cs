@@ -0,0 +1,141 @@
+// Code generated by unitpacking. DO NOT EDIT.
+
+using System;
+using System.Collections.Generic;
+using System.Globalization;
+using System.IO;
+
+namespace Recolude
+{
+    /// <summary>
+    /// Checks the generated UnitPacking class against the test vectors shared
+    /// with the Go library. Each line of the file holds a codec, the input
+    /// vector, the packed bytes, and the vector they unpack to. Vectors are
+    /// written as the hex bits of each double, and must match exactly.
+    /// </summary>
+    public static class UnitPackingTestVectors
+    {
+        public const string DefaultPath = "vectors.txt";
+
+        private static double Bits(string hex)
+        {
+            return BitConverter.Int64BitsToDouble(unchecked((long)ulong.Parse(hex, NumberStyles.HexNumber, CultureInfo.InvariantCulture)));
+        }
+
+        private static string Hex(byte[] b)
+        {
+            return BitConverter.ToString(b).Replace("-", "").ToLowerInvariant();
+        }
+
+        private static byte[] FromHex(string hex)
+        {
+            byte[] b = new byte[hex.Length / 2];
+            for (int i = 0; i < b.Length; i++)
+            {
+                b[i] = byte.Parse(hex.Substring(i * 2, 2), NumberStyles.HexNumber, CultureInfo.InvariantCulture);
+            }
+            return b;
+        }
+
+        private static bool SameBits(Vector3d a, Vector3d b)
+        {
+            return BitConverter.DoubleToInt64Bits(a.X) == BitConverter.DoubleToInt64Bits(b.X) &&
+                BitConverter.DoubleToInt64Bits(a.Y) == BitConverter.DoubleToInt64Bits(b.Y) &&
+                BitConverter.DoubleToInt64Bits(a.Z) == BitConverter.DoubleToInt64Bits(b.Z);
+        }
+
+        /// <summary>
+        /// Returns a description of every test vector the generated codecs
+        /// don't reproduce exactly. Lines for codecs that weren't generated
+        /// are skipped.
+        /// </summary>
+        public static List<string> Verify(string path)
+        {
+            List<string> failures = new List<string>();
+            int verified = 0;
+
+            foreach (string raw in File.ReadAllLines(path))
+            {
+                string line = raw.Trim();
+                if (line.Length == 0 || line[0] == '#')
+                {
+                    continue;
+                }
+
+                string[] fields = line.Split(' ');
+                Vector3d input = new Vector3d(Bits(fields[1]), Bits(fields[2]), Bits(fields[3]));
+                byte[] expectedPacked = FromHex(fields[4]);
+                Vector3d expectedUnpacked = new Vector3d(Bits(fields[5]), Bits(fields[6]), Bits(fields[7]));
+
+                byte[] packed;
+                Vector3d unpacked;
+                switch (fields[0])
+                {
+                    case "alg24":
+                        packed = UnitPacking.PackAlg24(input);
+                        unpacked = UnitPacking.UnpackAlg24(expectedPacked);
+                        break;
+                    case "coarse24":
+                        packed = UnitPacking.PackCoarse24(input);
+                        unpacked = UnitPacking.UnpackCoarse24(expectedPacked);
+                        break;
+                    case "oct16":
+                        packed = UnitPacking.PackOct16(input);
+                        unpacked = UnitPacking.UnpackOct16(expectedPacked);
+                        break;
+                    case "oct24":
+                        packed = UnitPacking.PackOct24(input);
+                        unpacked = UnitPacking.UnpackOct24(expectedPacked);
+                        break;
+                    case "oct32":
+                        packed = UnitPacking.PackOct32(input);
+                        unpacked = UnitPacking.UnpackOct32(expectedPacked);
+                        break;
+                    case "octquad16":
+                        packed = UnitPacking.PackOctQuad16(input);
+                        unpacked = UnitPacking.UnpackOctQuad16(expectedPacked);
+                        break;
+                    case "octquad24":
+                        packed = UnitPacking.PackOctQuad24(input);
+                        unpacked = UnitPacking.UnpackOctQuad24(expectedPacked);
+                        break;
+                    case "octquad32":
+                        packed = UnitPacking.PackOctQuad32(input);
+                        unpacked = UnitPacking.UnpackOctQuad32(expectedPacked);
+                        break;
+                    default:
+                        continue;
+                }
+
+                verified++;
+                if (Hex(packed) != fields[4])
+                {
+                    failures.Add(fields[0] + " packed " + input + " to " + Hex(packed) + ", expected " + fields[4]);
+                }
+                if (!SameBits(unpacked, expectedUnpacked))
+                {
+                    failures.Add(fields[0] + " unpacked " + fields[4] + " to " + unpacked + ", expected " + expectedUnpacked);
+                }
+            }
+
+            if (verified == 0)
+            {
+                failures.Add("no test vectors found for the generated codecs in " + path);
+            }
+            return failures;
+        }
+    }
+
+#if !UNITPACKING_NO_NUNIT
+    [NUnit.Framework.TestFixture]
+    public class UnitPackingTests
+    {
+        [NUnit.Framework.Test]
+        public void MatchesTestVectors()
+        {
+            List<string> failures = UnitPackingTestVectors.Verify(UnitPackingTestVectors.DefaultPath);
+            NUnit.Framework.Assert.IsEmpty(failures, string.Join("\n", failures.ToArray()));
+        }
+    }
+#endif
+}
@@ -0,0 +1,71 @@
+// Code generated by unitpacking. DO NOT EDIT.
+//
+// Each function takes a code as the unsigned integer produced by the matching
+// Pack*Uint32/Pack*Uint16 function, which is also the packed bytes read as a
+// little endian word.
+
+vec3 unitpackingFromOctUV(vec2 e) {
+    vec3 v = vec3(e.x, e.y, 1.0 - abs(e.x) - abs(e.y));
+    if (v.z < 0.0) {
+        vec2 s = vec2(v.x < 0.0 ? -1.0 : 1.0, v.y < 0.0 ? -1.0 : 1.0);
+        v.xy = (1.0 - abs(v.yx)) * s;
+    }
+    return normalize(v);
+}
+
+vec2 unitpackingQuadDecode(uint code, int levels) {
+    vec2 p = vec2(0.0, 0.0);
+    float m = 0.5;
+    for (int i = levels - 1; i >= 0; i--) {
+        uint dir = (code >> uint(i * 2)) & 3u;
+        p.x += (dir & 1u) == 1u ? m : -m;
+        p.y += dir < 2u ? m : -m;
+        m *= 0.5;
+    }
+    return p;
+}
+
+vec3 unpackAlg24(uint code) {
+    float x = (float((code >> 12u) & 0xFFFu) - 2048.0) / 2047.0;
+    float y = (float((code >> 1u) & 0x7FFu) - 1024.0) / 1023.0;
+    float z = sqrt(max(1.0 - x * x - y * y, 0.0));
+    return vec3(x, y, (code & 1u) == 1u ? z : -z);
+}
+
+vec3 unpackCoarse24(uint code) {
+    return vec3(
+        (float(code & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 8u) & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 16u) & 0xFFu) - 128.0) / 127.0
+    );
+}
+
+vec3 unpackOct16(uint code) {
+    float x = clamp((float((code >> 8u) & 0xFFu) - 128.0) / 127.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFu) - 128.0) / 127.0, -1.0, 1.0);
+    return unitpackingFromOctUV(vec2(x, y));
+}
+
+vec3 unpackOct24(uint code) {
+    float x = clamp((float((code >> 12u) & 0xFFFu) - 2048.0) / 2047.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFFu) - 2048.0) / 2047.0, -1.0, 1.0);
+    return unitpackingFromOctUV(vec2(x, y));
+}
+
+vec3 unpackOct32(uint code) {
+    float x = clamp((float((code >> 16u) & 0xFFFFu) - 32768.0) / 32767.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFFFu) - 32768.0) / 32767.0, -1.0, 1.0);
+    return unitpackingFromOctUV(vec2(x, y));
+}
+
+vec3 unpackOctQuad16(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 8));
+}
+
+vec3 unpackOctQuad24(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 12));
+}
+
+vec3 unpackOctQuad32(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 16));
+}
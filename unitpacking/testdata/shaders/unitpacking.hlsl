@@ -0,0 +1,71 @@
+// Code generated by unitpacking. DO NOT EDIT.
+//
+// Each function takes a code as the unsigned integer produced by the matching
+// Pack*Uint32/Pack*Uint16 function, which is also the packed bytes read as a
+// little endian word.
+
+float3 unitpackingFromOctUV(float2 e) {
+    float3 v = float3(e.x, e.y, 1.0 - abs(e.x) - abs(e.y));
+    if (v.z < 0.0) {
+        float2 s = float2(v.x < 0.0 ? -1.0 : 1.0, v.y < 0.0 ? -1.0 : 1.0);
+        v.xy = (1.0 - abs(v.yx)) * s;
+    }
+    return normalize(v);
+}
+
+float2 unitpackingQuadDecode(uint code, int levels) {
+    float2 p = float2(0.0, 0.0);
+    float m = 0.5;
+    for (int i = levels - 1; i >= 0; i--) {
+        uint dir = (code >> uint(i * 2)) & 3u;
+        p.x += (dir & 1u) == 1u ? m : -m;
+        p.y += dir < 2u ? m : -m;
+        m *= 0.5;
+    }
+    return p;
+}
+
+float3 unpackAlg24(uint code) {
+    float x = (float((code >> 12u) & 0xFFFu) - 2048.0) / 2047.0;
+    float y = (float((code >> 1u) & 0x7FFu) - 1024.0) / 1023.0;
+    float z = sqrt(max(1.0 - x * x - y * y, 0.0));
+    return float3(x, y, (code & 1u) == 1u ? z : -z);
+}
+
+float3 unpackCoarse24(uint code) {
+    return float3(
+        (float(code & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 8u) & 0xFFu) - 128.0) / 127.0,
+        (float((code >> 16u) & 0xFFu) - 128.0) / 127.0
+    );
+}
+
+float3 unpackOct16(uint code) {
+    float x = clamp((float((code >> 8u) & 0xFFu) - 128.0) / 127.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFu) - 128.0) / 127.0, -1.0, 1.0);
+    return unitpackingFromOctUV(float2(x, y));
+}
+
+float3 unpackOct24(uint code) {
+    float x = clamp((float((code >> 12u) & 0xFFFu) - 2048.0) / 2047.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFFu) - 2048.0) / 2047.0, -1.0, 1.0);
+    return unitpackingFromOctUV(float2(x, y));
+}
+
+float3 unpackOct32(uint code) {
+    float x = clamp((float((code >> 16u) & 0xFFFFu) - 32768.0) / 32767.0, -1.0, 1.0);
+    float y = clamp((float(code & 0xFFFFu) - 32768.0) / 32767.0, -1.0, 1.0);
+    return unitpackingFromOctUV(float2(x, y));
+}
+
+float3 unpackOctQuad16(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 8));
+}
+
+float3 unpackOctQuad24(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 12));
+}
+
+float3 unpackOctQuad32(uint code) {
+    return unitpackingFromOctUV(unitpackingQuadDecode(code, 16));
+}
@@ -0,0 +1,139 @@
+package unitpacking_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+// testVectorsPath is shared with the tests generated for other languages, so
+// every implementation is held to the exact same bytes and vectors.
+var testVectorsPath = filepath.Join("testdata", "vectors.txt")
+
+func testVectorInputs() []vector.Vector3 {
+	normalizer := unitpacking.Validator{Policy: unitpacking.PolicyNormalize, Tolerance: 1e-15}
+
+	inputs := make([]vector.Vector3, 0)
+	for _, v := range append(append([]vector.Vector3{}, testVectors...), edgeCaseVectors...) {
+		normalized, err := normalizer.Validate(v)
+		if err == nil {
+			inputs = append(inputs, normalized)
+		}
+	}
+
+	// Vectors that aren't unit length are packed differently by each method
+	inputs = append(inputs,
+		vector.NewVector3(0, 3, 4),
+		vector.NewVector3(0.2, -0.1, 0.05),
+		vector.NewVector3(-2, 0.5, -1),
+
+		// Vectors with no direction still pack to a code other than the null
+		// code
+		vector.Vector3Zero(),
+		vector.NewVector3(math.NaN(), 0.5, -0.5),
+		vector.NewVector3(math.NaN(), math.NaN(), math.NaN()),
+
+		// Components that scale to exactly x.5, where rounding half away from
+		// zero and rounding half to even disagree
+		vector.NewVector3(0.01968503937007874, -0.01968503937007874, 0.9996124625),
+		vector.NewVector3(0.0012212994626282364, -0.0024437927663734115, -1),
+	)
+
+	r := rand.New(rand.NewSource(35))
+	for i := 0; i < 200; i++ {
+		inputs = append(inputs, vector.NewVector3(r.NormFloat64(), r.NormFloat64(), r.NormFloat64()).Normalized())
+	}
+	return inputs
+}
+
+func formatBits(f float64) string {
+	return fmt.Sprintf("%016x", math.Float64bits(f))
+}
+
+func parseBits(t *testing.T, s string) float64 {
+	bits, err := strconv.ParseUint(s, 16, 64)
+	assert.NoError(t, err)
+	return math.Float64frombits(bits)
+}
+
+func writeTestVectors() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Golden test vectors, regenerate with: go test ./unitpacking -update\n")
+	buf.WriteString("# codec input.x input.y input.z packed unpacked.x unpacked.y unpacked.z\n")
+	buf.WriteString("# Vectors are the hex bits of float64s, packed is the hex of the packed bytes.\n")
+
+	for _, codec := range unitpacking.Codecs() {
+		for _, in := range testVectorInputs() {
+			packed := codec.Pack(in)
+			out := codec.Unpack(packed)
+			fmt.Fprintf(
+				&buf,
+				"%s %s %s %s %x %s %s %s\n",
+				codec.Name(),
+				formatBits(in.X()), formatBits(in.Y()), formatBits(in.Z()),
+				packed,
+				formatBits(out.X()), formatBits(out.Y()), formatBits(out.Z()),
+			)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestVectors_Golden(t *testing.T) {
+	if *update {
+		assert.NoError(t, os.WriteFile(testVectorsPath, writeTestVectors(), 0644))
+	}
+
+	f, err := os.Open(testVectorsPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	verified := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if !assert.Len(t, fields, 8, line) {
+			continue
+		}
+
+		codec, ok := unitpacking.CodecByName(fields[0])
+		if !assert.True(t, ok, line) {
+			continue
+		}
+
+		in := vector.NewVector3(parseBits(t, fields[1]), parseBits(t, fields[2]), parseBits(t, fields[3]))
+		packed, err := hex.DecodeString(fields[4])
+		assert.NoError(t, err)
+		assert.Equal(t, packed, codec.Pack(in), line)
+
+		out := codec.Unpack(packed)
+		assert.Equal(t, fields[5], formatBits(out.X()), line)
+		assert.Equal(t, fields[6], formatBits(out.Y()), line)
+		assert.Equal(t, fields[7], formatBits(out.Z()), line)
+		verified[codec.Name()]++
+	}
+	assert.NoError(t, scanner.Err())
+
+	for _, codec := range unitpacking.Codecs() {
+		assert.NotZero(t, verified[codec.Name()], codec.Name())
+	}
+}
@@ -20,6 +20,15 @@ func clampVec2(v vector.Vector2, min float64, max float64) vector.Vector2 {
 	)
 }
 
+// clampUnit clamps a component between -1 and 1, treating NaN as 0 so that
+// it still packs to a valid code.
+func clampUnit(num float64) float64 {
+	if math.IsNaN(num) {
+		return 0
+	}
+	return Clamp(num, -1, 1)
+}
+
 // Clamp clamps the given value between the given minimum float and maximum
 // float values. Returns the given value if it is within the min and max range.
 func Clamp(num float64, min float64, max float64) float64 {
@@ -31,3 +40,10 @@ func Clamp(num float64, min float64, max float64) float64 {
 	}
 	return num
 }
+
+// AngularError is the angle in radians between two vectors.
+func AngularError(a, b vector.Vector3) float64 {
+	// atan2 stays accurate for nearly parallel vectors where acos of the dot
+	// product would lose all precision.
+	return math.Atan2(a.Cross(b).Length(), a.Dot(b))
+}
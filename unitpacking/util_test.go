@@ -1,8 +1,10 @@
 package unitpacking_test
 
 import (
+	"math"
 	"testing"
 
+	"github.com/EliCDavis/vector"
 	"github.com/recolude/unitpacking/unitpacking"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,3 +29,22 @@ func TestClamp(t *testing.T) {
 		})
 	}
 }
+
+func TestAngularError(t *testing.T) {
+	tests := map[string]struct {
+		a    vector.Vector3
+		b    vector.Vector3
+		want float64
+	}{
+		"same":           {a: vector.NewVector3(1, 0, 0), b: vector.NewVector3(1, 0, 0), want: 0},
+		"perpendicular":  {a: vector.NewVector3(1, 0, 0), b: vector.NewVector3(0, 0, 1), want: math.Pi / 2},
+		"opposite":       {a: vector.NewVector3(0, 1, 0), b: vector.NewVector3(0, -1, 0), want: math.Pi},
+		"ignores length": {a: vector.NewVector3(3, 0, 0), b: vector.NewVector3(1, 1, 0), want: math.Pi / 4},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.InDelta(t, tc.want, unitpacking.AngularError(tc.a, tc.b), 0.0000001)
+		})
+	}
+}
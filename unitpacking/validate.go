@@ -0,0 +1,152 @@
+package unitpacking
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/EliCDavis/vector"
+)
+
+var (
+	// ErrZeroLength is returned when a vector has no direction to pack.
+	ErrZeroLength = errors.New("vector is zero length")
+
+	// ErrNonFinite is returned when a vector has a NaN or infinite component.
+	ErrNonFinite = errors.New("vector has a NaN or infinite component")
+
+	// ErrNotNormalized is returned when a vector's length is further from 1
+	// than the validator allows.
+	ErrNotNormalized = errors.New("vector is not unit length")
+
+	// ErrNoNullCode is returned when an invalid vector should be written as
+	// the null code, but the codec doesn't reserve one.
+	ErrNoNullCode = errors.New("codec has no null code")
+)
+
+// DefaultTolerance is how far from 1 a vector's length can be while still
+// being considered unit length, if a validator doesn't specify otherwise.
+// Loose enough to accept normals read back from 32bit floats or text.
+const DefaultTolerance = 1e-4
+
+// Policy decides what a Validator does with vectors that aren't unit length.
+//
+// Every policy passes unit vectors through untouched, and every policy
+// treats zero length vectors and vectors with a NaN or infinite component as
+// having no direction.
+type Policy int
+
+const (
+	// PolicyNormalize rescales vectors to unit length. Vectors with no
+	// direction result in an error.
+	PolicyNormalize Policy = iota
+
+	// PolicyClamp clamps each component between -1 and 1 and packs the
+	// result as is, which is what PackCoarse24 and PackAlg24 do on their
+	// own. The Oct and OctQuad methods only store the direction of the
+	// clamped vector, Alg24 keeps the clamped X and Y and recalculates Z from
+	// them, and Coarse24 keeps all three clamped components. Vectors with no
+	// direction result in an error.
+	PolicyClamp
+
+	// PolicyError rejects every vector that isn't unit length within the
+	// validator's tolerance.
+	PolicyError
+
+	// PolicySentinel rescales vectors to unit length like PolicyNormalize,
+	// but packs vectors with no direction as the codec's null code instead
+	// of returning an error. Requires a NullCodec.
+	PolicySentinel
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyNormalize:
+		return "normalize"
+	case PolicyClamp:
+		return "clamp"
+	case PolicyError:
+		return "error"
+	case PolicySentinel:
+		return "sentinel"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Validator checks vectors before they are packed, fixing or rejecting them
+// according to its policy.
+type Validator struct {
+	Policy Policy
+
+	// Tolerance is how far from 1 a vector's length can be while still
+	// being considered unit length. DefaultTolerance is used when 0.
+	Tolerance float64
+}
+
+func (val Validator) tolerance() float64 {
+	if val.Tolerance <= 0 {
+		return DefaultTolerance
+	}
+	return val.Tolerance
+}
+
+// Validate returns the vector that should be packed in place of the one
+// given, or an error explaining why it can't be packed. Under
+// PolicySentinel, ErrZeroLength and ErrNonFinite signal that the null code
+// should be written instead.
+func (val Validator) Validate(v vector.Vector3) (vector.Vector3, error) {
+	if isNonFinite(v.X()) || isNonFinite(v.Y()) || isNonFinite(v.Z()) {
+		return v, ErrNonFinite
+	}
+
+	largest := math.Max(math.Abs(v.X()), math.Max(math.Abs(v.Y()), math.Abs(v.Z())))
+	if largest == 0 {
+		return v, ErrZeroLength
+	}
+
+	if math.Abs(v.Length()-1) <= val.tolerance() {
+		return v, nil
+	}
+
+	switch val.Policy {
+	case PolicyNormalize, PolicySentinel:
+		// Scale by the largest component first, so the length of tiny or
+		// huge vectors doesn't underflow to 0 or overflow to infinity.
+		scaled := vector.NewVector3(v.X()/largest, v.Y()/largest, v.Z()/largest)
+		return scaled.DivByConstant(scaled.Length()), nil
+
+	case PolicyClamp:
+		return vector.NewVector3(
+			Clamp(v.X(), -1, 1),
+			Clamp(v.Y(), -1, 1),
+			Clamp(v.Z(), -1, 1),
+		), nil
+
+	case PolicyError:
+		return v, ErrNotNormalized
+	}
+
+	return v, fmt.Errorf("unknown policy: %s", val.Policy)
+}
+
+// Pack validates the vector and packs it with the given codec.
+func (val Validator) Pack(codec Codec, v vector.Vector3) ([]byte, error) {
+	cleaned, err := val.Validate(v)
+	if err == nil {
+		return codec.Pack(cleaned), nil
+	}
+
+	if val.Policy == PolicySentinel && (err == ErrZeroLength || err == ErrNonFinite) {
+		nullCodec, ok := codec.(NullCodec)
+		if !ok {
+			return nil, ErrNoNullCode
+		}
+		return nullCodec.Null(), nil
+	}
+
+	return nil, err
+}
+
+func isNonFinite(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
@@ -0,0 +1,202 @@
+package unitpacking_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/vector"
+	"github.com/recolude/unitpacking/unitpacking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	nan := math.NaN()
+	inf := math.Inf(1)
+
+	tests := map[string]struct {
+		policy unitpacking.Policy
+		input  vector.Vector3
+		want   vector.Vector3
+		err    error
+	}{
+		"normalize unit":   {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(0, 1, 0), want: vector.NewVector3(0, 1, 0)},
+		"normalize long":   {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(0, 3, 4), want: vector.NewVector3(0, 0.6, 0.8)},
+		"normalize short":  {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(0, 0.3, 0.4), want: vector.NewVector3(0, 0.6, 0.8)},
+		"normalize tiny":   {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(0, 3e-200, 4e-200), want: vector.NewVector3(0, 0.6, 0.8)},
+		"normalize huge":   {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(0, -3e200, 4e200), want: vector.NewVector3(0, -0.6, 0.8)},
+		"normalize zero":   {policy: unitpacking.PolicyNormalize, input: vector.Vector3Zero(), err: unitpacking.ErrZeroLength},
+		"normalize nan":    {policy: unitpacking.PolicyNormalize, input: vector.NewVector3(nan, 0, 1), err: unitpacking.ErrNonFinite},
+		"clamp unit":       {policy: unitpacking.PolicyClamp, input: vector.NewVector3(0, 1, 0), want: vector.NewVector3(0, 1, 0)},
+		"clamp long":       {policy: unitpacking.PolicyClamp, input: vector.NewVector3(0, 3, -4), want: vector.NewVector3(0, 1, -1)},
+		"clamp short":      {policy: unitpacking.PolicyClamp, input: vector.NewVector3(0, 0.3, 0.4), want: vector.NewVector3(0, 0.3, 0.4)},
+		"clamp zero":       {policy: unitpacking.PolicyClamp, input: vector.Vector3Zero(), err: unitpacking.ErrZeroLength},
+		"clamp inf":        {policy: unitpacking.PolicyClamp, input: vector.NewVector3(inf, 0, 0), err: unitpacking.ErrNonFinite},
+		"error unit":       {policy: unitpacking.PolicyError, input: vector.NewVector3(0, 1, 0), want: vector.NewVector3(0, 1, 0)},
+		"error within tol": {policy: unitpacking.PolicyError, input: vector.NewVector3(0, 1.00001, 0), want: vector.NewVector3(0, 1.00001, 0)},
+		"error long":       {policy: unitpacking.PolicyError, input: vector.NewVector3(0, 3, 4), err: unitpacking.ErrNotNormalized},
+		"error zero":       {policy: unitpacking.PolicyError, input: vector.Vector3Zero(), err: unitpacking.ErrZeroLength},
+		"error nan":        {policy: unitpacking.PolicyError, input: vector.NewVector3(0, nan, 0), err: unitpacking.ErrNonFinite},
+		"sentinel unit":    {policy: unitpacking.PolicySentinel, input: vector.NewVector3(0, 1, 0), want: vector.NewVector3(0, 1, 0)},
+		"sentinel long":    {policy: unitpacking.PolicySentinel, input: vector.NewVector3(0, 3, 4), want: vector.NewVector3(0, 0.6, 0.8)},
+		"sentinel zero":    {policy: unitpacking.PolicySentinel, input: vector.Vector3Zero(), err: unitpacking.ErrZeroLength},
+		"sentinel inf":     {policy: unitpacking.PolicySentinel, input: vector.NewVector3(0, 0, -inf), err: unitpacking.ErrNonFinite},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := unitpacking.Validator{Policy: tc.policy}.Validate(tc.input)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.InDelta(t, tc.want.X(), got.X(), 0.0000001)
+			assert.InDelta(t, tc.want.Y(), got.Y(), 0.0000001)
+			assert.InDelta(t, tc.want.Z(), got.Z(), 0.0000001)
+		})
+	}
+}
+
+func TestValidator_UnknownPolicy(t *testing.T) {
+	_, err := unitpacking.Validator{Policy: unitpacking.Policy(9)}.Validate(vector.NewVector3(0, 3, 4))
+	assert.Error(t, err)
+}
+
+func TestValidator_PackEveryCodec(t *testing.T) {
+	long := vector.NewVector3(0.3, -6, 8)
+	normalized := long.Normalized()
+	clamped := vector.NewVector3(0.3, -1, 1)
+
+	for _, codec := range unitpacking.Codecs() {
+		nullCodec := codec.(unitpacking.NullCodec)
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, policy := range []unitpacking.Policy{unitpacking.PolicyNormalize, unitpacking.PolicyClamp, unitpacking.PolicyError, unitpacking.PolicySentinel} {
+				val := unitpacking.Validator{Policy: policy}
+
+				// Unit vectors are always packed as is
+				for _, tc := range testVectors {
+					packed, err := val.Pack(codec, tc.Normalized())
+					assert.NoError(t, err)
+					assert.Equal(t, codec.Pack(tc.Normalized()), packed)
+				}
+
+				packed, err := val.Pack(codec, long)
+				switch policy {
+				case unitpacking.PolicyNormalize, unitpacking.PolicySentinel:
+					assert.NoError(t, err)
+					assert.Equal(t, codec.Pack(normalized), packed)
+				case unitpacking.PolicyClamp:
+					assert.NoError(t, err)
+					assert.Equal(t, codec.Pack(clamped), packed)
+				case unitpacking.PolicyError:
+					assert.Equal(t, unitpacking.ErrNotNormalized, err)
+				}
+
+				for _, invalid := range []vector.Vector3{vector.Vector3Zero(), vector.NewVector3(math.NaN(), 0, 0)} {
+					packed, err := val.Pack(codec, invalid)
+					if policy == unitpacking.PolicySentinel {
+						assert.NoError(t, err)
+						assert.Equal(t, nullCodec.Null(), packed)
+					} else {
+						assert.Error(t, err)
+						assert.Nil(t, packed)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestValidator_ClampBehaviorPerCodec(t *testing.T) {
+	val := unitpacking.Validator{Policy: unitpacking.PolicyClamp}
+	short := vector.NewVector3(0.5, 0.25, 0)
+
+	// Oct methods only keep the direction
+	for _, codec := range []unitpacking.Codec{unitpacking.Oct32, unitpacking.OctQuad32} {
+		packed, err := val.Pack(codec, short)
+		assert.NoError(t, err)
+		assert.InDelta(t, 0, unitpacking.AngularError(short, codec.Unpack(packed)), 0.0001)
+		assert.InDelta(t, 1, codec.Unpack(packed).Length(), 0.0001)
+	}
+
+	// Alg keeps X and Y, recalculating Z
+	packed, err := val.Pack(unitpacking.Alg24, short)
+	assert.NoError(t, err)
+	unpacked := unitpacking.Alg24.Unpack(packed)
+	assert.InDelta(t, 0.5, unpacked.X(), 0.001)
+	assert.InDelta(t, 0.25, unpacked.Y(), 0.001)
+	assert.InDelta(t, math.Sqrt(1-0.25-0.0625), unpacked.Z(), 0.01)
+
+	// Coarse keeps everything
+	packed, err = val.Pack(unitpacking.Coarse24, short)
+	assert.NoError(t, err)
+	unpacked = unitpacking.Coarse24.Unpack(packed)
+	assert.InDelta(t, 0.5, unpacked.X(), 0.01)
+	assert.InDelta(t, 0.25, unpacked.Y(), 0.01)
+	assert.InDelta(t, 0, unpacked.Z(), 0.01)
+}
+
+func TestValidator_SentinelRequiresNullCodec(t *testing.T) {
+	codec, err := unitpacking.NewDirectionMagnitude(unitpacking.Oct24, unitpacking.LinearMagnitude, 0, 1)
+	assert.NoError(t, err)
+
+	_, err = unitpacking.Validator{Policy: unitpacking.PolicySentinel}.Pack(codec, vector.Vector3Zero())
+	assert.Equal(t, unitpacking.ErrNoNullCode, err)
+}
+
+// reservedCodec reserves a code the wrapped codec never packs a unit vector
+// to.
+type reservedCodec struct {
+	unitpacking.Codec
+	null []byte
+}
+
+func (c reservedCodec) Null() []byte { return c.null }
+
+func TestValidator_SentinelPacksNullCode(t *testing.T) {
+	codec := reservedCodec{unitpacking.Oct16, []byte{0xFF, 0xFF}}
+	val := unitpacking.Validator{Policy: unitpacking.PolicySentinel}
+
+	for _, invalid := range []vector.Vector3{vector.Vector3Zero(), vector.NewVector3(0, math.Inf(-1), 0)} {
+		packed, err := val.Pack(codec, invalid)
+		assert.NoError(t, err)
+		assert.Equal(t, codec.null, packed)
+	}
+
+	packed, err := val.Pack(codec, vector.NewVector3(0, 0, 2))
+	assert.NoError(t, err)
+	assert.Equal(t, unitpacking.Oct16.Pack(vector.NewVector3(0, 0, 1)), packed)
+}
+
+func TestPack_NeverProducesNullCode(t *testing.T) {
+	inputs := append([]vector.Vector3{
+		vector.NewVector3(-1, -1, -1),
+		vector.NewVector3(-5, 0, 0),
+		vector.NewVector3(-0.0001, -0.0001, -1),
+		vector.NewVector3(-0.001, -0.001, -1),
+		vector.NewVector3(-1, -1, -1000),
+		vector.NewVector3(-1, 0, 0),
+		vector.NewVector3(0, -1, 0),
+
+		// Vectors with no direction still pack to a code
+		vector.Vector3Zero(),
+		vector.NewVector3(math.NaN(), 0, 0),
+		vector.NewVector3(math.NaN(), math.NaN(), math.NaN()),
+		vector.NewVector3(0, math.Inf(-1), 0),
+		vector.NewVector3(math.Inf(1), math.Inf(-1), math.Inf(1)),
+	}, testVectors...)
+
+	for _, codec := range unitpacking.AllCodecs() {
+		null := codec.(unitpacking.NullCodec).Null()
+		assert.Len(t, null, codec.Size())
+
+		t.Run(codec.Name(), func(t *testing.T) {
+			for _, in := range inputs {
+				assert.NotEqual(t, null, codec.Pack(in))
+				assert.NotEqual(t, null, codec.Pack(in.Normalized()))
+			}
+		})
+	}
+}